@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubernetes/pkg/kubemark/manifests"
+)
+
+type credentialsConfig struct {
+	manifests.CredentialsConfig
+	KubeconfigPath      string
+	AdminKubeconfigPath string
+	CAPath              string
+}
+
+// newCredentialsCommand creates the `kubemark credentials` subcommand, which
+// pre-creates per-node ServiceAccounts and kubeconfig Secrets in batch for
+// external-cluster fleets.
+func newCredentialsCommand() *cobra.Command {
+	c := &credentialsConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "credentials",
+		Short: "Batch-create per-node ServiceAccounts and kubeconfig Secrets for a hollow-node fleet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCredentials(c)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig for the cluster under test, where ServiceAccounts are created and tokens requested.")
+	fs.StringVar(&c.AdminKubeconfigPath, "admin-kubeconfig", "", "Path to the kubeconfig for the admin cluster running the fleet, where the per-node kubeconfig Secrets are stored. Defaults to --kubeconfig.")
+	fs.StringVar(&c.NamePrefix, "name-prefix", "hollow-node", "Per-node name stem; node i uses <prefix>-<i> for its ServiceAccount and Secret.")
+	fs.IntVar(&c.Count, "num-nodes", 1, "Number of nodes to create credentials for.")
+	fs.StringVar(&c.Namespace, "namespace", "kubemark", "Namespace for the ServiceAccounts on the cluster under test.")
+	fs.StringVar(&c.SecretNamespace, "secret-namespace", "kubemark", "Namespace for the kubeconfig Secrets on the admin cluster.")
+	fs.StringVar(&c.Server, "server", "", "Apiserver URL of the cluster under test embedded into the generated kubeconfigs. Defaults to the server of --kubeconfig.")
+	fs.StringVar(&c.CAPath, "certificate-authority", "", "Path to the cluster CA bundle embedded into the kubeconfigs. Defaults to the CA of --kubeconfig; if neither is available the kubeconfigs skip TLS verification.")
+	fs.DurationVar(&c.TokenExpiration, "token-expiration", 0, "Requested ServiceAccount token lifetime. 0 uses the apiserver default. Re-running the command refreshes the Secrets with new tokens.")
+	fs.IntVar(&c.Concurrency, "concurrency", 20, "How many nodes to process in parallel.")
+
+	return cmd
+}
+
+func runCredentials(c *credentialsConfig) error {
+	targetConfig, err := clientcmd.BuildConfigFromFlags("", c.KubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("error while building kubeconfig from %v: %w", c.KubeconfigPath, err)
+	}
+	target, err := clientset.NewForConfig(targetConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to create a ClientSet, error: %w", err)
+	}
+
+	admin := target
+	if c.AdminKubeconfigPath != "" {
+		adminConfig, err := clientcmd.BuildConfigFromFlags("", c.AdminKubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error while building kubeconfig from %v: %w", c.AdminKubeconfigPath, err)
+		}
+		if admin, err = clientset.NewForConfig(adminConfig); err != nil {
+			return fmt.Errorf("Failed to create a ClientSet, error: %w", err)
+		}
+	}
+
+	if c.Server == "" {
+		c.Server = targetConfig.Host
+	}
+	if c.CAPath != "" {
+		data, err := os.ReadFile(c.CAPath)
+		if err != nil {
+			return fmt.Errorf("error while reading CA bundle from %v: %w", c.CAPath, err)
+		}
+		c.CAData = data
+	} else {
+		c.CAData = targetConfig.CAData
+		if len(c.CAData) == 0 && targetConfig.CAFile != "" {
+			data, err := os.ReadFile(targetConfig.CAFile)
+			if err != nil {
+				return fmt.Errorf("error while reading CA bundle from %v: %w", targetConfig.CAFile, err)
+			}
+			c.CAData = data
+		}
+	}
+
+	start := time.Now()
+	if err := manifests.GenerateCredentials(context.Background(), target, admin, c.CredentialsConfig); err != nil {
+		return fmt.Errorf("Failed to generate credentials, error: %w", err)
+	}
+	fmt.Printf("Created credentials for %d nodes in %v.\n", c.Count, time.Since(start).Round(time.Second))
+	return nil
+}
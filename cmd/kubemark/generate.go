@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/kubernetes/pkg/kubemark/manifests"
+)
+
+type generateConfig struct {
+	manifests.KubemarkConfiguration
+	NodeLabels     map[string]string
+	KubeconfigPath string
+	OutputPath     string
+}
+
+// newGenerateCommand creates the `kubemark generate` subcommand, which
+// produces the manifests needed to run a hollow-node fleet on an admin
+// cluster.
+func newGenerateCommand() *cobra.Command {
+	c := &generateConfig{
+		NodeLabels: make(map[string]string),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate manifests for deploying a hollow-node fleet on an admin cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerate(c)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.Name, "fleet-name", "hollow-node", "Base name for the generated objects.")
+	fs.StringVar(&c.Namespace, "namespace", "kubemark", "Namespace the hollow-node fleet runs in.")
+	fs.Int32Var(&c.NumNodes, "num-nodes", 1, "Number of hollow nodes to deploy.")
+	fs.StringVar(&c.Image, "kubemark-image", "staging-k8s.gcr.io/kubemark:latest", "Kubemark image for the hollow-node containers.")
+	fs.StringVar(&c.KubeconfigSecret, "kubeconfig-secret", "kubeconfig", "Name of the Secret holding the hollow components' kubeconfigs.")
+	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to a kubeconfig to embed into the generated Secret. If empty, the Secret manifest is not generated and --kubeconfig-secret must exist already.")
+	bindableNodeLabels := cliflag.ConfigurationMap(c.NodeLabels)
+	fs.Var(&bindableNodeLabels, "node-labels", "Additional node labels for the hollow kubelets")
+	fs.StringSliceVar(&c.KubeletParams, "kubelet-params", nil, "Extra arguments for the hollow kubelet containers.")
+	fs.StringSliceVar(&c.ProxyParams, "proxy-params", nil, "Extra arguments for the hollow proxy containers.")
+	fs.StringVarP(&c.OutputPath, "output", "o", "-", "File to write the manifests to, or '-' for stdout.")
+
+	return cmd
+}
+
+func runGenerate(c *generateConfig) error {
+	c.KubemarkConfiguration.NodeLabels = c.NodeLabels
+	if c.KubeconfigPath != "" {
+		data, err := os.ReadFile(c.KubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("error while reading kubeconfig from %v: %w", c.KubeconfigPath, err)
+		}
+		c.Kubeconfig = data
+	}
+
+	out := os.Stdout
+	if c.OutputPath != "" && c.OutputPath != "-" {
+		f, err := os.Create(c.OutputPath)
+		if err != nil {
+			return fmt.Errorf("error while creating output file %v: %w", c.OutputPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return manifests.WriteManifests(out, c.GenerateAll())
+}
@@ -21,9 +21,13 @@ import (
 	"errors"
 	goflag "flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
+	cadvisorapi "github.com/google/cadvisor/info/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	internalapi "k8s.io/cri-api/pkg/apis"
@@ -33,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -50,6 +55,10 @@ import (
 	"k8s.io/kubernetes/pkg/kubelet/cm"
 	"k8s.io/kubernetes/pkg/kubelet/cri/remote"
 	fakeremote "k8s.io/kubernetes/pkg/kubelet/cri/remote/fake"
+	evictionapi "k8s.io/kubernetes/pkg/kubelet/eviction/api"
+	"k8s.io/kubernetes/pkg/kubelet/nodestatus"
+	serverstats "k8s.io/kubernetes/pkg/kubelet/server/stats"
+	"k8s.io/kubernetes/pkg/kubelet/stats"
 	"k8s.io/kubernetes/pkg/kubemark"
 	utilflag "k8s.io/kubernetes/pkg/util/flag"
 	fakeiptables "k8s.io/kubernetes/pkg/util/iptables/testing"
@@ -58,6 +67,10 @@ import (
 
 type hollowNodeConfig struct {
 	KubeconfigPath            string
+	KubeconfigContext         string
+	APIServerURL              string
+	TLSServerName             string
+	ProxyURL                  string
 	BootstrapKubeconfigPath   string
 	CertDirectory             string
 	KubeletPort               int
@@ -66,6 +79,8 @@ type hollowNodeConfig struct {
 	NodeName                  string
 	ServerPort                int
 	ContentType               string
+	DisableCompression        bool
+	UserAgent                 string
 	UseRealProxier            bool
 	ProxierSyncPeriod         time.Duration
 	ProxierMinSyncPeriod      time.Duration
@@ -76,10 +91,111 @@ type hollowNodeConfig struct {
 	UseHostImageService       bool
 	NodeCpu                   int
 	NodeMemory                int
+	NodeEphemeralStorage      int
+	PodStorageGrowthMBPerHour int
 	NodeLeaseDurationSeconds  int
 	NodeStatusUpdateFrequency int
 	NodeStatusReportFrequency int
 	PodCIDR                   string
+	StandaloneMode            bool
+	SimulatedPodsDir          string
+	NodeRootDirBase           string
+	EnableAPFAttribution      bool
+	ClientRequestMetrics      bool
+	APIAuditLogPath           string
+	StatusBackoffBase         int
+	StatusBackoffDuration     int
+	DNSConfigMapNamespace     string
+	DNSConfigMapName          string
+	DNSMetricsPort            int
+	ControllerResyncPeriod    time.Duration
+	ControllerWorkers         int
+	LogLinesPerSecond         float64
+	ContainerLogDir           string
+	LifecycleWebhookURL       string
+	LifecycleWebhookTimeout   time.Duration
+	APIRequestBudget          map[string]string
+	APIRequestBudgetEnforce   bool
+	RuntimeOpLatency          time.Duration
+	SandboxRestartRate        float64
+	NetworkReadyDelay         time.Duration
+	AdminPort                 int
+	PaddingImages             int
+	PaddingVolumes            int
+	PaddingConditions         int
+	CPUManagerPolicy          string
+	CPUSockets                int
+	CPUCoresPerSocket         int
+	CPUThreadsPerCore         int
+	SimulatedImages           int
+	SimulatedImageMeanMB      int
+	SimulatedImageSpreadMB    int
+	MemoryManagerPolicy       string
+	TopologyManagerPolicy     string
+	Hugepages2Mi              int
+	Hugepages1Gi              int
+	ReportVolumeStatus        bool
+	SimulatedCertLifetime     time.Duration
+	DeviceChurnFraction       float64
+	DeviceChurnPeriod         time.Duration
+	NetworkRxBytesPerSecond   int
+	NetworkTxBytesPerSecond   int
+	NetworkErrorsPerHour      int
+	PIDCapacity               int
+	SystemPIDs                int
+	PIDsPerPod                int
+	NodeProfilePath           string
+	CapacityDriftProfilePath  string
+	SystemReserved            map[string]string
+	KubeReserved              map[string]string
+	PodTerminationLatency     time.Duration
+	PressureBase              float64
+	PressureAmplitude         float64
+	PressurePeriod            time.Duration
+	PressureGrowthPerHour     float64
+	EnablePriorityAdmission   bool
+	EnforceMaxPods            bool
+	MeasureBindingLatency     bool
+	ConfigMapSecretStrategy   string
+	CgroupFailureRate         float64
+	CgroupDriver              string
+	CgroupVersion             int
+	PodStatusQPS              float64
+	PodStatusBurst            int
+	IPConflictFraction        float64
+	IPDelayFraction           float64
+	IPAssignmentDelay         time.Duration
+	StrictAffinityValidation  bool
+	ValidateDownwardAPI       bool
+	EnforceHostPorts          bool
+	FileSDDir                 string
+	CrashPolicyPath           string
+	ContainerStartupDuration  time.Duration
+	InitContainerDuration     time.Duration
+	ShutdownGracePeriods      string
+	TerminationGraceCap       time.Duration
+	PressureScenarioPath      string
+	RuntimeName               string
+	RuntimeVersion            string
+	ManagedFieldsManagers     int
+	ManagedFieldsInterval     time.Duration
+	TokenRotationExpiration   time.Duration
+	ConditionSchedulePath     string
+	BootTimeOffset            time.Duration
+	OOMKillsPerHour           float64
+	ImagePullLatency          time.Duration
+	ImagePullCachedLatency    time.Duration
+	ImagePullFailureRates     map[string]string
+	NodeIP                    string
+	ProbeReadyDelay           time.Duration
+	ProbeFlakeFraction        float64
+	ProbeFlakePeriod          time.Duration
+	LivenessFailuresPerHour   float64
+	LogGrowthMBPerHour        int
+	ContainerLogMaxSize       string
+	ContainerLogMaxFiles      int
+	DryRun                    bool
+	DryRunNodes               int
 }
 
 const (
@@ -89,10 +205,14 @@ const (
 
 // TODO(#45650): Refactor hollow-node into hollow-kubelet and hollow-proxy
 // and make the config driven.
-var knownMorphs = sets.NewString("kubelet", "proxy")
+var knownMorphs = sets.NewString("kubelet", "proxy", "node-dns", "controller-manager")
 
 func (c *hollowNodeConfig) addFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "/kubeconfig/kubeconfig", "Path to kubeconfig file.")
+	fs.StringVar(&c.KubeconfigContext, "kubeconfig-context", "", "Context within the kubeconfig to use. If empty, the current context is used.")
+	fs.StringVar(&c.APIServerURL, "apiserver-url", "", "If set, overrides the apiserver address from the kubeconfig, e.g. to point kubelet and proxy at different endpoints of an external control plane.")
+	fs.StringVar(&c.TLSServerName, "tls-server-name", "", "Server name to use for apiserver certificate validation. If it is not provided, the hostname used to contact the server is used, which breaks behind SNI-routing load balancers.")
+	fs.StringVar(&c.ProxyURL, "proxy-url", "", "HTTP(S) proxy to use for apiserver traffic. If empty, proxy settings are taken from the environment.")
 	fs.StringVar(&c.BootstrapKubeconfigPath, "bootstrap-kubeconfig", "", "Path to bootstrap kubeconfig file.")
 	fs.StringVar(&c.CertDirectory, "cert-dir", "/etc/srv/", "Path to cert directory for bootstraping.")
 	fs.IntVar(&c.KubeletPort, "kubelet-port", ports.KubeletPort, "Port on which HollowKubelet should be listening.")
@@ -101,6 +221,8 @@ func (c *hollowNodeConfig) addFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&c.ServerPort, "api-server-port", 443, "Port on which API server is listening.")
 	fs.StringVar(&c.Morph, "morph", "", fmt.Sprintf("Specifies into which Hollow component this binary should morph. Allowed values: %v", knownMorphs.List()))
 	fs.StringVar(&c.ContentType, "kube-api-content-type", "application/vnd.kubernetes.protobuf", "ContentType of requests sent to apiserver.")
+	fs.StringVar(&c.UserAgent, "user-agent", "", "User-Agent to use on apiserver requests, so audit logs and metrics can attribute simulated traffic precisely. If empty, '<component>/<node name>' is used, e.g. 'hollow-kubelet/fake-node-001'.")
+	fs.BoolVar(&c.DisableCompression, "disable-api-compression", false, "If true, opt out of apiserver response compression (Accept-Encoding: gzip) for this component's requests, so the CPU/bandwidth tradeoff of compression can be measured under kubemark load.")
 	fs.BoolVar(&c.UseRealProxier, "use-real-proxier", true, "Set to true if you want to use real proxier inside hollow-proxy.")
 	fs.DurationVar(&c.ProxierSyncPeriod, "proxier-sync-period", 30*time.Second, "Period that proxy rules are refreshed in hollow-proxy.")
 	fs.DurationVar(&c.ProxierMinSyncPeriod, "proxier-min-sync-period", 0, "Minimum period that proxy rules are refreshed in hollow-proxy.")
@@ -110,13 +232,117 @@ func (c *hollowNodeConfig) addFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&c.MaxPods, "max-pods", maxPods, "Number of pods that can run on this Kubelet.")
 	bindableExtendedResources := cliflag.ConfigurationMap(c.ExtendedResources)
 	fs.Var(&bindableExtendedResources, "extended-resources", "Register the node with extended resources (comma separated \"<name>=<quantity>\")")
+	bindableSystemReserved := cliflag.ConfigurationMap(c.SystemReserved)
+	fs.Var(&bindableSystemReserved, "system-reserved", "Resources reserved for system daemons (comma separated \"<name>=<quantity>\"), subtracted from capacity to compute allocatable.")
+	bindableKubeReserved := cliflag.ConfigurationMap(c.KubeReserved)
+	fs.Var(&bindableKubeReserved, "kube-reserved", "Resources reserved for kubernetes components (comma separated \"<name>=<quantity>\"), subtracted from capacity to compute allocatable.")
 	fs.BoolVar(&c.UseHostImageService, "use-host-image-service", true, "Set to true if the hollow-kubelet should use the host image service. If set to false the fake image service will be used")
 	fs.IntVar(&c.NodeCpu, "node-cpu", 72, "Node cpu num")
 	fs.IntVar(&c.NodeMemory, "node-memory", 288, "Node cpu memory(Gi) num")
+	fs.IntVar(&c.NodeEphemeralStorage, "node-ephemeral-storage", 0, "Node ephemeral-storage capacity (Gi) reported in node status and rootfs stats, instead of the hardcoded 0. 0 keeps the legacy behavior.")
+	fs.IntVar(&c.LogGrowthMBPerHour, "container-log-growth-mb-per-hour", 0, "Rate (MB/hour) at which each running container's simulated log files grow. Retained log bytes are capped by the rotation policy and count toward node rootfs usage, so disk-usage modeling includes container logs. 0 disables log simulation.")
+	fs.StringVar(&c.ContainerLogMaxSize, "container-log-max-size", "10Mi", "Maximum size a container log file may reach before rotation, reported in /configz and bounding simulated log usage.")
+	fs.IntVar(&c.ContainerLogMaxFiles, "container-log-max-files", 5, "Maximum number of rotated log files retained per container.")
+	fs.IntVar(&c.PodStorageGrowthMBPerHour, "pod-storage-growth-mb-per-hour", 0, "Rate (MB/hour) at which each running container's simulated writable layer grows, driving per-pod ephemeral-storage usage and, past the nodefs eviction threshold, storage eviction. 0 disables storage growth.")
 	fs.IntVar(&c.NodeStatusUpdateFrequency, "node-status-update-frequency", 10, "Specifies how often kubelet posts node status to master. Note: be cautious when changing the constant, it must work with nodeMonitorGracePeriod in nodecontroller.")
 	fs.IntVar(&c.NodeStatusReportFrequency, "node-status-report-frequency", 300, "report status frequency")
 	fs.IntVar(&c.NodeLeaseDurationSeconds, "node-lease-duration-seconds", 40, "report node lease frequency")
 	fs.StringVar(&c.PodCIDR, "pod-cidr", "10.244.3.0/24", "report node lease frequency")
+	fs.BoolVar(&c.StandaloneMode, "standalone", false, "Run the hollow kubelet without an apiserver, driving the pod lifecycle pipeline from locally injected pod specs. Only valid with --morph=kubelet.")
+	fs.StringVar(&c.SimulatedPodsDir, "simulated-pods-dir", "", "Directory with pod manifests to inject as static pods. With an apiserver, the kubelet manages the corresponding mirror pods (status sync, recreation on delete, replacement on spec edits) exactly as on a real node.")
+	fs.IntVar(&c.ManagedFieldsManagers, "managed-fields-managers", 0, "Number of distinct field manager identities that periodically server-side-apply annotations to this hollow node's Node object, accumulating realistic managedFields history so its apiserver/etcd cost on long-lived Node objects can be quantified. 0 disables the simulation.")
+	fs.DurationVar(&c.ManagedFieldsInterval, "managed-fields-interval", time.Minute, "Interval between managed-fields apply operations when --managed-fields-managers is set.")
+	fs.DurationVar(&c.TokenRotationExpiration, "token-rotation-expiration", 0, "If non-zero, request a pod-bound service account token of this TTL for every pod on the node and refresh it at 80% of the TTL, reproducing projected token volume rotation traffic so the apiserver cost of BoundServiceAccountTokenVolume can be benchmarked. 0 disables the simulation.")
+	fs.StringVar(&c.RuntimeName, "runtime-name", "", "Container runtime name reported in nodeInfo and runtime status (e.g. 'containerd', 'cri-o'). Empty keeps the fake runtime's own name.")
+	fs.StringVar(&c.RuntimeVersion, "runtime-version", "", "Container runtime version reported in nodeInfo and runtime status (e.g. '1.6.8'), so version-skew tooling can be validated against mixed simulated runtimes. Empty keeps the fake runtime's own version.")
+	fs.DurationVar(&c.RuntimeOpLatency, "runtime-op-latency", 0, "Simulated latency added to each fake container runtime operation (sandbox/container create, start, stop, remove).")
+	fs.StringVar(&c.NodeProfilePath, "node-profile", "", "Path to a YAML node profile (capacity, reserved resources, NUMA layout) making this hollow node look like a specific real instance type.")
+	fs.StringVar(&c.CapacityDriftProfilePath, "capacity-drift-profile", "", "Path to a YAML capacity drift profile making this node's reported capacity vary over time (e.g. ephemeral-storage slowly shrinking, GPUs intermittently disappearing), for testing controllers that react to capacity changes.")
+	fs.BoolVar(&c.ReportVolumeStatus, "report-volume-status", false, "If true, report volumesInUse and volumesAttached in node status for the CSI volumes referenced by pods on this node, so the attach-detach controller reconciles against realistic status.")
+	fs.DurationVar(&c.SimulatedCertLifetime, "simulated-cert-lifetime", 0, "If non-zero, expose kubelet_certificate_manager_* TTL and rotation metrics for synthetic client/serving certificates with this lifetime, so certificate-expiry alerting can be validated at scale. 0 disables the metrics.")
+	fs.Float64Var(&c.DeviceChurnFraction, "device-churn-fraction", 0, "Fraction of simulated device plugin devices that periodically flips to Unhealthy, shrinking allocatable. 0 disables device health churn.")
+	fs.DurationVar(&c.DeviceChurnPeriod, "device-churn-period", 5*time.Minute, "Period between device health flips when --device-churn-fraction is set.")
+	fs.IntVar(&c.NetworkRxBytesPerSecond, "network-rx-bytes-per-second", 0, "Node-level simulated network receive rate reported in /stats/summary; pods carry stable per-pod shares of it. 0 disables network stats simulation.")
+	fs.IntVar(&c.NetworkTxBytesPerSecond, "network-tx-bytes-per-second", 0, "Node-level simulated network transmit rate reported in /stats/summary.")
+	fs.IntVar(&c.NetworkErrorsPerHour, "network-errors-per-hour", 0, "Rate of simulated rx/tx errors on the node's network interface.")
+	fs.IntVar(&c.PIDCapacity, "pid-capacity", 0, "Simulated kernel task limit reported as the node's PID capacity in /stats/summary, with a pid.available<10% hard eviction threshold so PIDPressure triggers when pods consume the simulated PIDs. 0 disables PID simulation.")
+	fs.IntVar(&c.SystemPIDs, "system-pids", 100, "Baseline number of simulated PIDs consumed by system daemons when --pid-capacity is set.")
+	fs.IntVar(&c.PIDsPerPod, "pids-per-pod", 50, "Number of simulated PIDs each ready pod sandbox consumes, unless the pod overrides it with the kubemark.io/simulated-pids annotation.")
+	fs.StringVar(&c.MemoryManagerPolicy, "memory-manager-policy", "None", "Memory manager policy for the hollow kubelet: 'None' uses the fake memory manager, 'Static' runs a real static memory manager over the synthetic NUMA topology.")
+	fs.IntVar(&c.Hugepages2Mi, "simulated-hugepages-2mi", 0, "Number of pre-allocated 2Mi huge pages to report as node capacity.")
+	fs.IntVar(&c.Hugepages1Gi, "simulated-hugepages-1gi", 0, "Number of pre-allocated 1Gi huge pages to report as node capacity.")
+	fs.IntVar(&c.SimulatedImages, "simulated-images", 0, "Number of images in the synthetic image catalog reported by the fake image service and in node.status.images. 0 leaves the catalog empty. Takes precedence over --padding-node-images.")
+	fs.IntVar(&c.SimulatedImageMeanMB, "simulated-image-mean-mb", 300, "Mean size in MB of images in the synthetic catalog.")
+	fs.IntVar(&c.SimulatedImageSpreadMB, "simulated-image-spread-mb", 200, "Half-width in MB of the uniform size spread around the mean for the synthetic catalog.")
+	fs.StringVar(&c.CPUManagerPolicy, "cpu-manager-policy", "none", "CPU manager policy for the hollow kubelet: 'none' uses the fake CPU manager, 'static' runs a real static CPU manager over a synthetic topology.")
+	fs.StringVar(&c.TopologyManagerPolicy, "topology-manager-policy", "none", "Topology manager policy for the hollow kubelet: 'none' uses the fake topology manager, 'best-effort', 'restricted' and 'single-numa-node' run a real topology manager over the synthetic NUMA topology so TopologyAffinityError admission rejections can be reproduced.")
+	fs.IntVar(&c.CPUSockets, "simulated-cpu-sockets", 2, "Number of CPU sockets in the synthetic topology used with --cpu-manager-policy=static.")
+	fs.IntVar(&c.CPUCoresPerSocket, "simulated-cpu-cores-per-socket", 4, "Number of physical cores per socket in the synthetic CPU topology.")
+	fs.IntVar(&c.CPUThreadsPerCore, "simulated-cpu-threads-per-core", 2, "Number of hardware threads per core in the synthetic CPU topology.")
+	fs.IntVar(&c.PaddingImages, "padding-node-images", 0, "Number of synthetic images to report in node status, for stressing apiserver/etcd with large Node objects.")
+	fs.IntVar(&c.PaddingVolumes, "padding-node-volumes", 0, "Number of synthetic volumesInUse entries to pad node status with.")
+	fs.IntVar(&c.PaddingConditions, "padding-node-conditions", 0, "Number of synthetic conditions to pad node status with.")
+	fs.IntVar(&c.AdminPort, "admin-port", 0, "Port to serve the hollow node's admin API on, with in-process percentile readouts of key simulated latencies. 0 disables the admin API.")
+	fs.DurationVar(&c.NetworkReadyDelay, "network-ready-delay", 0, "If non-zero, register the node with NetworkUnavailable=true and clear the condition after this delay, simulating cloud route creation. 0 disables the simulation.")
+	fs.Float64Var(&c.SandboxRestartRate, "sandbox-restart-rate", 0, "Average number of simulated pod sandbox restarts per hour on this node, each emitting a SandboxChanged event. Accounted separately from container restarts. 0 disables sandbox churn.")
+	fs.BoolVar(&c.StrictAffinityValidation, "strict-affinity-validation", false, "If true, re-validate affinity/anti-affinity at admission and report mismatches as pod events, to catch scheduler regressions during kubemark runs.")
+	fs.StringVar(&c.FileSDDir, "file-sd-dir", "", "Directory to write this process's metrics endpoints into as a Prometheus file_sd JSON file (<node-name>.json). Processes sharing the directory assemble the fleet's scrape configuration automatically. Empty disables the file.")
+	fs.BoolVar(&c.EnforceHostPorts, "enforce-host-ports", false, "If true, reject pods at admission whose hostPorts collide with a pod already on the node, with the scheduler's NodePorts failure reason, so scheduler hostPort predicate drift is caught in kubemark runs.")
+	fs.BoolVar(&c.ValidateDownwardAPI, "validate-downward-api", false, "If true, resolve every downward API env var and volume item at admission (resourceFieldRefs against the node's simulated allocatable) and report failures as pod events, so downward API workloads can be validated at scale.")
+	fs.BoolVar(&c.EnablePriorityAdmission, "enable-priority-admission", false, "If true, apply priority-aware admission when the node is at max-pods capacity: higher-priority pods evict the lowest-priority pod, others are rejected with OutOfpods.")
+	fs.StringVar(&c.ConfigMapSecretStrategy, "configmap-secret-strategy", "Watch", "How the hollow kubelet's secret and configmap managers talk to the apiserver: 'Watch' (per-object watches, the real kubelet default), 'Cache' (TTL cache over GETs) or 'Get' (GET on every sync). Pods mounting ConfigMaps/Secrets generate the corresponding apiserver traffic, so propagation load can be measured in scale tests.")
+	fs.BoolVar(&c.MeasureBindingLatency, "measure-binding-latency", false, "If true, record kubemark_binding_observation_latency_seconds, the time from a pod's Binding creation to this kubelet first seeing it on the watch. The measurement excludes the simulated startup latency, so it isolates the watch-propagation component of scheduling latency. For sub-second resolution the benchmark driver must stamp the kubemark.io/binding-time annotation (RFC3339Nano) on the pod; otherwise the PodScheduled condition is used and values are accurate only to whole seconds.")
+	fs.BoolVar(&c.EnforceMaxPods, "enforce-max-pods", false, "If true, reject pods with OutOfpods once the node holds --max-pods active pods, as on a real kubelet, so scheduler/node-capacity races are reproducible. Ignored when --enable-priority-admission is set.")
+	fs.Float64Var(&c.PodStatusQPS, "pod-status-qps", 0, "Cap on the sustained rate of pod status updates this node sends, in updates per second. Over-cap updates are answered with a synthetic 429 and coalesced into the next retry with the latest status. 0 means unlimited.")
+	fs.IntVar(&c.PodStatusBurst, "pod-status-burst", 10, "How many pod status updates may go out back-to-back under --pod-status-qps.")
+	fs.Float64Var(&c.IPConflictFraction, "pod-ip-conflict-fraction", 0, "Fraction of pods reported with a duplicate pod IP (the pod CIDR's first host address), so IPAM validation and EndpointSlice correctness checks catch conflicts during simulation runs. 0 disables conflict injection.")
+	fs.Float64Var(&c.IPDelayFraction, "pod-ip-delay-fraction", 0, "Fraction of pods whose IP assignment is delayed by --pod-ip-assignment-delay after sandbox creation, reporting no pod IP until then.")
+	fs.DurationVar(&c.IPAssignmentDelay, "pod-ip-assignment-delay", 30*time.Second, "How long delayed pods stay without an IP when --pod-ip-delay-fraction is set.")
+	fs.StringVar(&c.CgroupDriver, "cgroup-driver", "cgroupfs", "Cgroup driver this hollow node reports ('cgroupfs' or 'systemd'), so controllers and validation webhooks that branch on the driver can be exercised.")
+	fs.IntVar(&c.CgroupVersion, "cgroup-version", 0, "Cgroup hierarchy version this hollow node reports in its mounted subsystems: 1 (per-controller mounts), 2 (unified hierarchy) or 0 (none, the legacy behavior).")
+	fs.Float64Var(&c.CgroupFailureRate, "cgroup-failure-rate", 0, "Fraction of simulated cgroup operations (QoS cgroup updates, pod cgroup creation/destruction) that fail with realistic errors, surfaced as node events and metrics. 0 disables failure injection.")
+	fs.DurationVar(&c.ProbeReadyDelay, "probe-ready-delay", 0, "How long after starting containers stay unready, modeling readiness probe initial delays and latency. 0 reports readiness immediately.")
+	fs.Float64Var(&c.ProbeFlakeFraction, "probe-flake-fraction", 0, "Fraction of per-container probe windows reporting not-ready, generating readiness flapping and endpoint churn for endpoint controller and proxy scale tests. 0 disables flakiness.")
+	fs.DurationVar(&c.ProbeFlakePeriod, "probe-flake-period", time.Minute, "Length of one readiness probe window; readiness can change at window boundaries.")
+	fs.Float64Var(&c.LivenessFailuresPerHour, "liveness-failures-per-hour", 0, "Average number of simulated liveness probe failures per running container per hour; each kills the container so the kubelet restarts it per restartPolicy. 0 disables liveness failures.")
+	fs.DurationVar(&c.ContainerStartupDuration, "container-startup-duration", 0, "How long each simulated container start takes, keeping pods in Waiting (ContainerCreating) for that long before Running instead of jumping straight there. Pods can override it with the kubemark.io/container-startup-seconds annotation. 0 starts containers immediately.")
+	fs.StringVar(&c.ShutdownGracePeriods, "shutdown-grace-periods", "", "Comma-separated priority:duration pairs (e.g. '0:30s,2000000000:10s') mirroring the kubelet's shutdownGracePeriodByPodPriority. On SIGTERM the hollow node fails its pods tier by tier with these grace periods, lowest priorities first, before exiting, so priority-tiered shutdown policies can be validated. Empty exits without a tiered teardown.")
+	fs.DurationVar(&c.InitContainerDuration, "init-container-duration", 5*time.Second, "How long each simulated init container runs before exiting successfully. Init containers are declared with the kubemark.io/init-containers annotation (the CRI carries no container-type marker); pods can override the duration with kubemark.io/init-container-seconds and fail one container with kubemark.io/failing-init-container.")
+	fs.StringVar(&c.CrashPolicyPath, "crash-policy", "", "Path to a YAML crash policy making containers of matched pods exit after a configured time with a configured exit code. Pods can also opt in via the kubemark.io/crash-after-seconds annotation. The kubelet then honors restartPolicy, increments restartCount and transitions through CrashLoopBackOff as on a real node.")
+	fs.Float64Var(&c.PressureBase, "system-pressure-base", 0, "Base fraction of node memory consumed by simulated background system usage, reflected in node stats. 0 disables pressure simulation.")
+	fs.Float64Var(&c.PressureAmplitude, "system-pressure-amplitude", 0, "Amplitude (fraction of node memory) of periodic oscillation on top of the base system usage.")
+	fs.DurationVar(&c.PressurePeriod, "system-pressure-period", 10*time.Minute, "Period of the system usage oscillation.")
+	fs.Float64Var(&c.PressureGrowthPerHour, "system-pressure-growth-per-hour", 0, "Fraction of node memory the simulated system usage grows per hour, modeling a gradual leak.")
+	fs.StringVar(&c.PressureScenarioPath, "pressure-scenario", "", "Path to a YAML schedule of memory/disk pressure episodes. While an episode is active, node usage is floored at the configured fractions, so the real eviction manager sets pressure conditions and evicts pods in priority/QoS order with Evicted statuses and events. Disk episodes need --node-ephemeral-storage.")
+	fs.StringVar(&c.NodeIP, "node-ip", "", "IP address this node registers in its status. Defaults to the POD_IP environment variable, so a hollow node running as a pod is reachable through the apiserver's egress/konnectivity path at its own pod IP for exec/logs routing. Empty with no POD_IP falls back to the host's default address.")
+	fs.DurationVar(&c.ImagePullLatency, "image-pull-latency", 0, "How long the first pull of an image takes on this node. Subsequent pulls of a cached image take --image-pull-cached-latency. 0 with no failure rates disables the pull simulation.")
+	fs.DurationVar(&c.ImagePullCachedLatency, "image-pull-cached-latency", 500*time.Millisecond, "How long pulls of an already-cached image take under --image-pull-latency.")
+	fs.StringToStringVar(&c.ImagePullFailureRates, "image-pull-failure-rates", nil, "Per-registry pull failure probabilities in [0,1] (e.g. registry.k8s.io=0.05,docker.io=0.2). Failed pulls surface as ErrImagePull and ImagePullBackOff through the real kubelet.")
+	fs.Float64Var(&c.OOMKillsPerHour, "oom-kills-per-hour", 0, "Average number of simulated container OOM kills per hour on this node. Victims are random running containers with a memory limit; they terminate with reason OOMKilled and exit code 137, restartCount bumps per restartPolicy, and the node records the kubelet's OOMKilling event. 0 disables OOM injection.")
+	fs.DurationVar(&c.BootTimeOffset, "boot-time-offset", 0, "How long before process start this node reports having booted. The node's stats startTime is derived from process start rather than the shared host's boot time, so uptime-based tooling and node-age policies see plausible per-node uptimes; the offset backdates it further.")
+	fs.StringVar(&c.ConditionSchedulePath, "condition-schedule", "", "Path to a YAML timeline of node condition and taint injections (e.g. DiskPressure, PIDPressure, Ready, arbitrary taints), for exercising the node-lifecycle controller, taint-based eviction and descheduler policies against this node.")
+	fs.DurationVar(&c.PodTerminationLatency, "pod-termination-latency", 0, "Simulated latency for stop/remove runtime operations, delaying how quickly this hollow node acknowledges pod termination. Overrides --runtime-op-latency for those operations.")
+	fs.DurationVar(&c.TerminationGraceCap, "termination-grace-cap", 0, "If non-zero, deleted pods take min(terminationGracePeriodSeconds, this cap) to stop — the behavior of containers that ignore SIGTERM — staying Terminating for that long so node drain and rolling-update timing is realistic. Pods can add a simulated preStop delay with the kubemark.io/prestop-seconds annotation. 0 stops containers immediately.")
+	bindableRequestBudget := cliflag.ConfigurationMap(c.APIRequestBudget)
+	fs.Var(&bindableRequestBudget, "api-request-budget", "Per-verb-group API request budget in requests per minute (comma separated \"<group>=<limit>\", groups: read, write, watch). Empty means unlimited.")
+	fs.BoolVar(&c.APIRequestBudgetEnforce, "api-request-budget-enforce", false, "If true, reject over-budget requests with a synthetic 429; otherwise only count and log violations.")
+	fs.StringVar(&c.LifecycleWebhookURL, "lifecycle-webhook-url", "", "URL of a webhook consulted at simulated pod transition points. The webhook response can delay, fail, or redirect the transition. If empty, no webhook is called.")
+	fs.DurationVar(&c.LifecycleWebhookTimeout, "lifecycle-webhook-timeout", 5*time.Second, "Timeout for lifecycle webhook calls. Webhook failures fail open.")
+	fs.StringVar(&c.DNSConfigMapNamespace, "dns-configmap-namespace", "kube-system", "Namespace of the ConfigMap consumed by the node-dns morph.")
+	fs.StringVar(&c.DNSConfigMapName, "dns-configmap-name", "node-local-dns", "Name of the ConfigMap consumed by the node-dns morph.")
+	fs.IntVar(&c.DNSMetricsPort, "dns-metrics-port", 9253, "Port on which the node-dns morph exposes its metrics.")
+	fs.Float64Var(&c.LogLinesPerSecond, "log-lines-per-second", 0, "Synthetic log lines written per second for each running simulated container, in CRI log format at the path the container's status reports, so kubectl logs and log collectors work against hollow nodes. 0 disables generation.")
+	fs.StringVar(&c.ContainerLogDir, "container-log-dir", "/var/log/kubemark-containers", "Directory the synthetic container log files are written under.")
+	fs.DurationVar(&c.ControllerResyncPeriod, "controller-resync-period", 12*time.Hour, "Informer resync period of the controller-manager morph, matching kube-controller-manager's min-resync-period.")
+	fs.IntVar(&c.ControllerWorkers, "controller-workers", 5, "Reconcile concurrency per controller of the controller-manager morph.")
+	fs.IntVar(&c.StatusBackoffBase, "status-write-backoff-base", 1, "Base (in seconds) of the client backoff applied after failed status/lease writes.")
+	fs.IntVar(&c.StatusBackoffDuration, "status-write-backoff-duration", 10, "Cap (in seconds) of the client backoff applied after failed status/lease writes.")
+	fs.StringVar(&c.APIAuditLogPath, "api-audit-log", "", "Path of a local file to log every API request this hollow node makes (verb, path, latency, sizes) as JSON lines, so traffic models for capacity planning can be derived from actual simulated traffic. Empty disables the audit log.")
+	fs.BoolVar(&c.EnableAPFAttribution, "enable-apf-attribution", false, "Record the apiserver priority-and-fairness FlowSchema attribution of this component's requests as metrics and logs.")
+	fs.BoolVar(&c.ClientRequestMetrics, "client-request-metrics", false, "Export per-verb, per-resource request latency and error-count metrics for this component's apiserver requests, as a client-side apiserver health signal across the fleet.")
+	fs.BoolVar(&c.DryRun, "dry-run", false, "Evaluate the configuration and node profile, print the planned node inventory, total simulated capacity and expected steady-state API request rate, and exit without connecting to a cluster. Helps size control planes before a run.")
+	fs.IntVar(&c.DryRunNodes, "dry-run-nodes", 1, "Number of nodes with this configuration the planned fleet consists of, for --dry-run totals.")
+	fs.StringVar(&c.NodeRootDirBase, "node-root-dir-base", "", "Base directory under which each hollow node gets an isolated root dir (pods dir, plugin sockets, checkpoints) named after the node. If empty, a unique temporary directory is used.")
 }
 
 func (c *hollowNodeConfig) createClientConfigFromFile() (*restclient.Config, error) {
@@ -124,16 +350,67 @@ func (c *hollowNodeConfig) createClientConfigFromFile() (*restclient.Config, err
 	if err != nil {
 		return nil, fmt.Errorf("error while loading kubeconfig from file %v: %v", c.KubeconfigPath, err)
 	}
-	config, err := clientcmd.NewDefaultClientConfig(*clientConfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	overrides := &clientcmd.ConfigOverrides{}
+	if c.KubeconfigContext != "" {
+		overrides.CurrentContext = c.KubeconfigContext
+	}
+	if c.APIServerURL != "" {
+		overrides.ClusterInfo.Server = c.APIServerURL
+	}
+	config, err := clientcmd.NewDefaultClientConfig(*clientConfig, overrides).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("error while creating kubeconfig: %v", err)
 	}
+	if c.TLSServerName != "" {
+		config.TLSClientConfig.ServerName = c.TLSServerName
+	}
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing proxy url %v: %v", c.ProxyURL, err)
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
+	} else {
+		// Honor HTTP(S)_PROXY/NO_PROXY from the environment, as real node
+		// components do in corporate environments.
+		config.Proxy = http.ProxyFromEnvironment
+	}
 	config.ContentType = c.ContentType
+	config.DisableCompression = c.DisableCompression
 	config.QPS = 10
 	config.Burst = 20
+	kubemark.ApplyTransportWrappers(config)
 	return config, nil
 }
 
+// userAgent returns the User-Agent the given hollow component should put on
+// its apiserver requests. The default includes the node name so that traffic
+// from individual hollow nodes can be told apart in audit logs and metrics.
+func (c *hollowNodeConfig) userAgent(component string) string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+	return fmt.Sprintf("%s/%s", component, c.NodeName)
+}
+
+// nodeIP returns the address this node should register: the explicit flag
+// if set, else the POD_IP environment variable. An empty result leaves the
+// kubelet to its own default interface detection.
+func (c *hollowNodeConfig) nodeIP() string {
+	if c.NodeIP != "" {
+		return c.NodeIP
+	}
+	return os.Getenv("POD_IP")
+}
+
+// zone returns the zone this hollow node is labelled with, or "".
+func (c *hollowNodeConfig) zone() string {
+	if zone := c.NodeLabels[v1.LabelTopologyZone]; zone != "" {
+		return zone
+	}
+	return c.NodeLabels[v1.LabelFailureDomainBetaZone]
+}
+
 func (c *hollowNodeConfig) bootstrapClientConfig() error {
 	if c.BootstrapKubeconfigPath != "" {
 		return bootstrap.LoadClientCert(context.TODO(), c.KubeconfigPath, c.BootstrapKubeconfigPath, c.CertDirectory, types.NodeName(c.NodeName))
@@ -154,7 +431,91 @@ func (c *hollowNodeConfig) createHollowKubeletOptions() *kubemark.HollowKubletOp
 		NodeStatusReportFrequency: c.NodeStatusReportFrequency,
 		NodeLeaseDurationSeconds:  c.NodeLeaseDurationSeconds,
 		PodCIDR:                   c.PodCIDR,
+		RootDirBase:               c.NodeRootDirBase,
+		ConfigMapSecretStrategy:   c.ConfigMapSecretStrategy,
+		NodeIP:                    c.nodeIP(),
+	}
+}
+
+// buildFleetPlan evaluates this configuration into the fleet plan that
+// --dry-run prints, without connecting to a cluster.
+func (c *hollowNodeConfig) buildFleetPlan() (*kubemark.FleetPlan, error) {
+	capacity := v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewQuantity(int64(c.NodeCpu), resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(int64(c.NodeMemory)*1024*1024*1024, resource.BinarySI),
+		v1.ResourcePods:   *resource.NewQuantity(int64(c.MaxPods), resource.DecimalSI),
+	}
+	if c.NodeEphemeralStorage > 0 {
+		capacity[v1.ResourceEphemeralStorage] = *resource.NewQuantity(int64(c.NodeEphemeralStorage)*1024*1024*1024, resource.BinarySI)
+	}
+	profileName := ""
+	if c.NodeProfilePath != "" {
+		profile, err := kubemark.LoadNodeProfile(c.NodeProfilePath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load node profile, error: %w", err)
+		}
+		profileName = profile.Name
+		for name, quantity := range profile.CapacityList() {
+			capacity[name] = quantity
+		}
+	}
+	for name, value := range c.ExtendedResources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --extended-resources quantity %q for %q: %w", value, name, err)
+		}
+		capacity[v1.ResourceName(name)] = quantity
+	}
+	if c.Hugepages2Mi > 0 {
+		capacity[v1.ResourceHugePagesPrefix+"2Mi"] = *resource.NewQuantity(int64(c.Hugepages2Mi)*2*1024*1024, resource.BinarySI)
 	}
+	if c.Hugepages1Gi > 0 {
+		capacity[v1.ResourceHugePagesPrefix+"1Gi"] = *resource.NewQuantity(int64(c.Hugepages1Gi)*1024*1024*1024, resource.BinarySI)
+	}
+
+	// Steady-state writes: node status updates, lease renewals (the kubelet
+	// renews every 10 seconds) and simulated sandbox churn events. Watches
+	// count the long-lived streams each component holds open.
+	writes := 0.0
+	watches := 0
+	switch c.Morph {
+	case "kubelet":
+		if c.NodeStatusUpdateFrequency > 0 {
+			writes += 1 / float64(c.NodeStatusUpdateFrequency)
+		}
+		writes += 0.1
+		writes += c.SandboxRestartRate / 3600
+		watches = 5
+	case "proxy":
+		watches = 3
+	case "node-dns":
+		watches = 1
+	case "controller-manager":
+		// One stream per informer; writes all carry dryRun=All and are not
+		// persisted.
+		watches = 9
+	}
+
+	nodes := c.DryRunNodes
+	if nodes < 1 {
+		nodes = 1
+	}
+	plan := &kubemark.FleetPlan{}
+	for i := 0; i < nodes; i++ {
+		name := c.NodeName
+		if nodes > 1 {
+			name = fmt.Sprintf("%s-%d", c.NodeName, i)
+		}
+		plan.Nodes = append(plan.Nodes, kubemark.PlannedNode{
+			Name:            name,
+			Morph:           c.Morph,
+			Profile:         profileName,
+			Capacity:        capacity,
+			WritesPerSecond: writes,
+			WatchStreams:    watches,
+		})
+	}
+	return plan, nil
 }
 
 func main() {
@@ -168,6 +529,9 @@ func newHollowNodeCommand() *cobra.Command {
 	s := &hollowNodeConfig{
 		NodeLabels:        make(map[string]string),
 		ExtendedResources: make(map[string]string),
+		APIRequestBudget:  make(map[string]string),
+		SystemReserved:    make(map[string]string),
+		KubeReserved:      make(map[string]string),
 	}
 
 	cmd := &cobra.Command{
@@ -191,6 +555,13 @@ func newHollowNodeCommand() *cobra.Command {
 	fs.AddGoFlagSet(goflag.CommandLine) // for flags like --docker-only
 	s.addFlags(fs)
 
+	cmd.AddCommand(newGenerateCommand())
+	cmd.AddCommand(newOutageCommand())
+	cmd.AddCommand(newRampCommand())
+	cmd.AddCommand(newCredentialsCommand())
+	cmd.AddCommand(newSkewCommand())
+	cmd.AddCommand(newTeardownCommand())
+
 	return cmd
 }
 
@@ -203,37 +574,175 @@ func run(cmd *cobra.Command, config *hollowNodeConfig) error {
 		return fmt.Errorf("Unknown morph: %v. allowed values: %v", config.Morph, knownMorphs.List())
 	}
 
-	// create a client to communicate with API server.
-	err := config.bootstrapClientConfig()
-	if err != nil {
-		return fmt.Errorf("Failed to bootstrap, error: %w. Exiting", err)
+	if config.StandaloneMode && config.Morph != "kubelet" {
+		return fmt.Errorf("--standalone is only supported with --morph=kubelet")
 	}
-	clientConfig, err := config.createClientConfigFromFile()
-	if err != nil {
-		return fmt.Errorf("Failed to create a ClientConfig, error: %w. Exiting", err)
+
+	if config.DryRun {
+		plan, err := config.buildFleetPlan()
+		if err != nil {
+			return err
+		}
+		plan.Print(os.Stdout)
+		return nil
 	}
 
-	if config.Morph == "kubelet" {
-		clientConfig.UserAgent = "hollow-kubelet"
-		client, err := clientset.NewForConfig(clientConfig)
+	// create a client to communicate with API server, unless running
+	// standalone without one.
+	if config.EnableAPFAttribution {
+		kubemark.RegisterTransportWrapper(kubemark.NewAPFAttributionWrapper())
+	}
+	if config.ClientRequestMetrics {
+		kubemark.RegisterTransportWrapper(kubemark.NewClientMetricsWrapper())
+	}
+	if config.PodStatusQPS > 0 {
+		kubemark.RegisterTransportWrapper(kubemark.NewPodStatusThrottleWrapper(kubemark.NewPodStatusThrottle(config.PodStatusQPS, config.PodStatusBurst)))
+	}
+	if config.APIAuditLogPath != "" {
+		auditLogger, err := kubemark.NewAPIAuditLogger(config.APIAuditLogPath)
 		if err != nil {
-			return fmt.Errorf("Failed to create a ClientSet, error: %w. Exiting", err)
+			return fmt.Errorf("Failed to open API audit log, error: %w. Exiting", err)
+		}
+		kubemark.RegisterTransportWrapper(kubemark.NewAPIAuditWrapper(auditLogger))
+	}
+	if config.LifecycleWebhookURL != "" {
+		webhook := kubemark.NewWebhookHook(config.LifecycleWebhookURL, config.NodeName, config.LifecycleWebhookTimeout)
+		kubemark.RegisterLifecycleHook(webhook)
+		// The webhook gates transitions as a decider; the hook registration
+		// above only keeps it informed of node start/shutdown.
+		kubemark.PodLifecycle().AddDecider(webhook)
+	}
+	if len(config.APIRequestBudget) > 0 {
+		limits := make(map[string]int, len(config.APIRequestBudget))
+		for group, limit := range config.APIRequestBudget {
+			parsed, err := strconv.Atoi(limit)
+			if err != nil {
+				return fmt.Errorf("invalid request budget %q for verb group %q: %w", limit, group, err)
+			}
+			limits[group] = parsed
 		}
+		kubemark.RegisterTransportWrapper(kubemark.NewRequestBudgetWrapper(kubemark.NewRequestBudget(limits, config.APIRequestBudgetEnforce)))
+	}
+	if zone := config.zone(); zone != "" {
+		kubemark.RegisterTransportWrapper(kubemark.NewOutageWrapper(config.NodeName, zone))
+	}
+	if err := kubemark.SetHeartbeatClientBackoff(config.StatusBackoffBase, config.StatusBackoffDuration); err != nil {
+		return fmt.Errorf("Failed to configure client backoff, error: %w. Exiting", err)
+	}
 
-		f, c := kubemark.GetHollowKubeletConfig(config.createHollowKubeletOptions())
+	if config.AdminPort > 0 {
+		kubemark.StartAdminServer(config.AdminPort)
+	}
+	if config.FileSDDir != "" {
+		host := os.Getenv("POD_IP")
+		if host == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("Failed to determine scrape target host, error: %w", err)
+			}
+			host = hostname
+		}
+		ports := map[string]int{"admin": config.AdminPort}
+		if config.Morph == "kubelet" {
+			ports["kubelet"] = config.KubeletPort
+			ports["kubelet-readonly"] = config.KubeletReadOnlyPort
+		}
+		extraLabels := map[string]string{}
+		if zone := config.zone(); zone != "" {
+			extraLabels["zone"] = zone
+		}
+		groups := kubemark.ScrapeTargetGroups(host, config.NodeName, config.Morph, ports, extraLabels)
+		if err := kubemark.WriteScrapeTargets(config.FileSDDir, config.NodeName, groups); err != nil {
+			return fmt.Errorf("Failed to write scrape targets, error: %w", err)
+		}
+	}
 
-		heartbeatClientConfig := *clientConfig
-		heartbeatClientConfig.Timeout = c.NodeStatusUpdateFrequency.Duration
-		// The timeout is the minimum of the lease duration and status update frequency
-		leaseTimeout := time.Duration(c.NodeLeaseDurationSeconds) * time.Second
-		if heartbeatClientConfig.Timeout > leaseTimeout {
-			heartbeatClientConfig.Timeout = leaseTimeout
+	var clientConfig *restclient.Config
+	if !config.StandaloneMode {
+		err := config.bootstrapClientConfig()
+		if err != nil {
+			return fmt.Errorf("Failed to bootstrap, error: %w. Exiting", err)
+		}
+		clientConfig, err = config.createClientConfigFromFile()
+		if err != nil {
+			return fmt.Errorf("Failed to create a ClientConfig, error: %w. Exiting", err)
+		}
+		if config.NodeProfilePath != "" {
+			// Profiles may pin their own client encoding, so serialization
+			// cost can be A/B measured across profiles in one cluster.
+			profile, err := kubemark.LoadNodeProfile(config.NodeProfilePath)
+			if err != nil {
+				return fmt.Errorf("Failed to load node profile, error: %w", err)
+			}
+			profile.ApplyClientConfig(clientConfig)
 		}
+	}
+
+	if config.CgroupDriver != "cgroupfs" && config.CgroupDriver != "systemd" {
+		return fmt.Errorf("invalid --cgroup-driver %q, must be 'cgroupfs' or 'systemd'", config.CgroupDriver)
+	}
+	if config.CgroupVersion < 0 || config.CgroupVersion > 2 {
+		return fmt.Errorf("invalid --cgroup-version %d, must be 0, 1 or 2", config.CgroupVersion)
+	}
+	switch config.ConfigMapSecretStrategy {
+	case "Watch", "Cache", "Get":
+	default:
+		return fmt.Errorf("invalid --configmap-secret-strategy %q, must be 'Watch', 'Cache' or 'Get'", config.ConfigMapSecretStrategy)
+	}
+
+	if config.Morph == "kubelet" {
+		// Each hollow node "booted" when its process started (optionally
+		// backdated), not when the shared host did.
+		serverstats.BootTimeOverride = time.Now().Add(-config.BootTimeOffset)
 
-		heartbeatClientConfig.QPS = float32(-1)
-		heartbeatClient, err := clientset.NewForConfig(&heartbeatClientConfig)
+		f, c := kubemark.GetHollowKubeletConfig(config.createHollowKubeletOptions())
+		// Report the configured cgroup driver through /configz as well, so
+		// validation that cross-checks it against GetNodeConfig holds up.
+		c.CgroupDriver = config.CgroupDriver
+		// Reflect the simulated log rotation policy in /configz, and bound
+		// simulated log usage by the same policy.
+		logMaxSize, err := resource.ParseQuantity(config.ContainerLogMaxSize)
 		if err != nil {
-			return fmt.Errorf("Failed to create a ClientSet, error: %w. Exiting", err)
+			return fmt.Errorf("invalid --container-log-max-size: %w", err)
+		}
+		if config.ContainerLogMaxFiles < 2 {
+			return fmt.Errorf("invalid --container-log-max-files %d, must be at least 2", config.ContainerLogMaxFiles)
+		}
+		c.ContainerLogMaxSize = config.ContainerLogMaxSize
+		c.ContainerLogMaxFiles = int32(config.ContainerLogMaxFiles)
+		if config.PIDCapacity > 0 {
+			// Let the eviction manager react to the simulated PID usage, so
+			// PIDPressure is raised once pid.available drops below 10% of
+			// --pid-capacity.
+			if c.EvictionHard == nil {
+				c.EvictionHard = map[string]string{}
+			}
+			c.EvictionHard[string(evictionapi.SignalPIDAvailable)] = "10%"
+		}
+
+		var client, heartbeatClient *clientset.Clientset
+		if !config.StandaloneMode {
+			clientConfig.UserAgent = config.userAgent("hollow-kubelet")
+			var err error
+			client, err = clientset.NewForConfig(clientConfig)
+			if err != nil {
+				return fmt.Errorf("Failed to create a ClientSet, error: %w. Exiting", err)
+			}
+
+			heartbeatClientConfig := *clientConfig
+			heartbeatClientConfig.Timeout = c.NodeStatusUpdateFrequency.Duration
+			// The timeout is the minimum of the lease duration and status update frequency
+			leaseTimeout := time.Duration(c.NodeLeaseDurationSeconds) * time.Second
+			if heartbeatClientConfig.Timeout > leaseTimeout {
+				heartbeatClientConfig.Timeout = leaseTimeout
+			}
+
+			heartbeatClientConfig.QPS = float32(-1)
+			heartbeatClientConfig.Wrap(kubemark.NewHeartbeatMetricsWrapper())
+			heartbeatClient, err = clientset.NewForConfig(&heartbeatClientConfig)
+			if err != nil {
+				return fmt.Errorf("Failed to create a ClientSet, error: %w. Exiting", err)
+			}
 		}
 
 		cadvisorInterface := &kubemark.Fake{
@@ -241,17 +750,173 @@ func run(cmd *cobra.Command, config *hollowNodeConfig) error {
 			NodeCpu:    config.NodeCpu,
 			NodeMemory: config.NodeMemory,
 		}
+		if config.PressureBase > 0 || config.PressureScenarioPath != "" {
+			cadvisorInterface.Pressure = kubemark.NewSystemPressure(kubemark.PressureProfile{
+				BaseFraction:          config.PressureBase,
+				AmplitudeFraction:     config.PressureAmplitude,
+				Period:                config.PressurePeriod,
+				GrowthFractionPerHour: config.PressureGrowthPerHour,
+			}, uint64(config.NodeMemory)*1024*1024*1024)
+		}
+		var ephemeralStorage *kubemark.EphemeralStorageSimulator
+		if config.NodeEphemeralStorage > 0 {
+			ephemeralStorage = kubemark.NewEphemeralStorageSimulator(int64(config.NodeEphemeralStorage) * 1024 * 1024 * 1024)
+			cadvisorInterface.EphemeralStorage = ephemeralStorage
+		}
+		if config.PressureScenarioPath != "" {
+			scenario, err := kubemark.LoadPressureScenario(config.PressureScenarioPath)
+			if err != nil {
+				return fmt.Errorf("Failed to load pressure scenario, error: %w", err)
+			}
+			runner := &kubemark.PressureScenarioRunner{
+				Scenario: scenario,
+				Memory:   cadvisorInterface.Pressure,
+				Disk:     ephemeralStorage,
+			}
+			runner.Run(wait.NeverStop)
+		}
+		var networkTraffic *kubemark.NetworkTraffic
+		if config.NetworkRxBytesPerSecond > 0 || config.NetworkTxBytesPerSecond > 0 {
+			networkTraffic = kubemark.NewNetworkTraffic(uint64(config.NetworkRxBytesPerSecond), uint64(config.NetworkTxBytesPerSecond), uint64(config.NetworkErrorsPerHour))
+			cadvisorInterface.Network = networkTraffic
+		}
+
+		extendedResources := v1.ResourceList{}
+		for k, v := range config.ExtendedResources {
+			extendedResources[v1.ResourceName(k)] = resource.MustParse(v)
+		}
+
+		hugePagesCapacity := v1.ResourceList{}
+		if config.Hugepages2Mi > 0 {
+			cadvisorInterface.HugePages = append(cadvisorInterface.HugePages, cadvisorapi.HugePagesInfo{PageSize: 2 * 1024, NumPages: uint64(config.Hugepages2Mi)})
+			hugePagesCapacity[v1.ResourceHugePagesPrefix+"2Mi"] = *resource.NewQuantity(int64(config.Hugepages2Mi)*2*1024*1024, resource.BinarySI)
+		}
+		if config.Hugepages1Gi > 0 {
+			cadvisorInterface.HugePages = append(cadvisorInterface.HugePages, cadvisorapi.HugePagesInfo{PageSize: 1024 * 1024, NumPages: uint64(config.Hugepages1Gi)})
+			hugePagesCapacity[v1.ResourceHugePagesPrefix+"1Gi"] = *resource.NewQuantity(int64(config.Hugepages1Gi)*1024*1024*1024, resource.BinarySI)
+		}
+
+		stubOptions := cm.StubManagerOptions{
+			ExtendedPluginResources: extendedResources,
+			HugePagesCapacity:       hugePagesCapacity,
+		}
+		if config.CgroupFailureRate > 0 {
+			var eventClient clientset.Interface
+			if client != nil {
+				eventClient = client
+			}
+			stubOptions.CgroupOpError = kubemark.NewCgroupFailureInjector(eventClient, config.NodeName, config.CgroupFailureRate).MaybeFail
+		}
+
+		var profile *kubemark.NodeProfile
+		if config.NodeProfilePath != "" {
+			loaded, err := kubemark.LoadNodeProfile(config.NodeProfilePath)
+			if err != nil {
+				return fmt.Errorf("Failed to load node profile, error: %w", err)
+			}
+			profile = loaded
+			stubOptions.Capacity = profile.CapacityList()
+			stubOptions.NodeAllocatableReservation = profile.AllocatableReservation()
+			stubOptions.NodeConfig = cm.NodeConfig{
+				NodeAllocatableConfig: cm.NodeAllocatableConfig{
+					SystemReserved: profile.SystemReservedList(),
+					KubeReserved:   profile.KubeReservedList(),
+				},
+			}
+			if profile.NUMA != nil {
+				cadvisorInterface.Topology = kubemark.SyntheticCPUTopology(profile.NUMA.Sockets, profile.NUMA.CoresPerSocket, profile.NUMA.ThreadsPerCore)
+			}
+			if profile.Architecture != "" {
+				nodestatus.NodeArchitecture = profile.Architecture
+			}
+			// The kubelet's own sysctl allowlist admission enforces these,
+			// rejecting pods that request anything else with SysctlForbidden.
+			c.AllowedUnsafeSysctls = profile.AllowedUnsafeSysctls
+		}
+
+		if len(config.SystemReserved) > 0 || len(config.KubeReserved) > 0 {
+			systemReserved, err := kubemark.ParseResourceList(config.SystemReserved)
+			if err != nil {
+				return fmt.Errorf("invalid --system-reserved: %w", err)
+			}
+			kubeReserved, err := kubemark.ParseResourceList(config.KubeReserved)
+			if err != nil {
+				return fmt.Errorf("invalid --kube-reserved: %w", err)
+			}
+			reservation := v1.ResourceList{}
+			for _, reserved := range []v1.ResourceList{systemReserved, kubeReserved} {
+				for name, quantity := range reserved {
+					total := reservation[name]
+					total.Add(quantity)
+					reservation[name] = total
+				}
+			}
+			stubOptions.NodeAllocatableReservation = reservation
+			stubOptions.NodeConfig.SystemReserved = systemReserved
+			stubOptions.NodeConfig.KubeReserved = kubeReserved
+		}
+
+		stubOptions.NodeConfig.CgroupDriver = config.CgroupDriver
+		stubOptions.CgroupVersion = config.CgroupVersion
+
+		if ephemeralStorage != nil {
+			// The profile's ephemeral-storage wins when both are configured.
+			if stubOptions.Capacity == nil {
+				stubOptions.Capacity = v1.ResourceList{}
+			}
+			if _, ok := stubOptions.Capacity[v1.ResourceEphemeralStorage]; !ok {
+				stubOptions.Capacity[v1.ResourceEphemeralStorage] = *resource.NewQuantity(ephemeralStorage.CapacityBytes, resource.BinarySI)
+			}
+		}
 
-		var containerManager cm.ContainerManager
-		if config.ExtendedResources != nil {
-			extendedResources := v1.ResourceList{}
-			for k, v := range config.ExtendedResources {
-				extendedResources[v1.ResourceName(k)] = resource.MustParse(v)
+		staticCPUManager := config.CPUManagerPolicy == "static"
+		staticMemoryManager := config.MemoryManagerPolicy == "Static"
+		realTopologyManager := config.TopologyManagerPolicy != "" && config.TopologyManagerPolicy != "none"
+		if (staticCPUManager || staticMemoryManager || realTopologyManager) && cadvisorInterface.Topology == nil {
+			cadvisorInterface.Topology = kubemark.SyntheticCPUTopology(config.CPUSockets, config.CPUCoresPerSocket, config.CPUThreadsPerCore)
+		}
+		if staticCPUManager || staticMemoryManager || realTopologyManager {
+			machineInfo, err := cadvisorInterface.MachineInfo()
+			if err != nil {
+				return fmt.Errorf("Failed to get machine info, error: %w", err)
+			}
+			if realTopologyManager {
+				if stubOptions.TopologyManager, err = kubemark.NewTopologyManager(machineInfo, config.TopologyManagerPolicy); err != nil {
+					return fmt.Errorf("Failed to create a topology manager, error: %w", err)
+				}
 			}
+			if staticCPUManager {
+				if stubOptions.CPUManager, err = kubemark.NewStaticCPUManager(machineInfo, f.RootDirectory, stubOptions.TopologyManager); err != nil {
+					return fmt.Errorf("Failed to create a static CPU manager, error: %w", err)
+				}
+			}
+			if staticMemoryManager {
+				if stubOptions.MemoryManager, err = kubemark.NewStaticMemoryManager(machineInfo, f.RootDirectory, stubOptions.TopologyManager); err != nil {
+					return fmt.Errorf("Failed to create a static memory manager, error: %w", err)
+				}
+			}
+		}
 
-			containerManager = cm.NewStubContainerManagerWithDevicePluginResource(extendedResources)
-		} else {
-			containerManager = cm.NewStubContainerManager()
+		containerManager := cm.NewStubContainerManagerWithOptions(stubOptions)
+		if setter, ok := containerManager.(kubemark.DevicePluginCapacitySetter); ok && config.AdminPort > 0 {
+			kubemark.RegisterCapacityMutationHandler(setter)
+		}
+		if introspector, ok := containerManager.(kubemark.ResourceIntrospector); ok && config.AdminPort > 0 {
+			kubemark.RegisterResourceIntrospectionHandler(config.NodeName, introspector)
+		}
+		if setter, ok := containerManager.(kubemark.DeviceHealthSetter); ok && config.DeviceChurnFraction > 0 {
+			kubemark.NewDeviceHealthChurner(setter, config.DeviceChurnFraction, config.DeviceChurnPeriod).Run(wait.NeverStop)
+		}
+		if config.CapacityDriftProfilePath != "" {
+			driftProfile, err := kubemark.LoadCapacityDriftProfile(config.CapacityDriftProfilePath)
+			if err != nil {
+				return fmt.Errorf("Failed to load capacity drift profile, error: %w", err)
+			}
+			target, ok := containerManager.(kubemark.CapacityDriftTarget)
+			if !ok {
+				return fmt.Errorf("Failed to apply capacity drift profile, error: %w", errors.New("container manager does not support capacity mutation"))
+			}
+			kubemark.NewCapacityDriftSimulator(driftProfile, target).Run(wait.NeverStop)
 		}
 
 		endpoint, err := fakeremote.GenerateEndpoint()
@@ -259,14 +924,65 @@ func run(cmd *cobra.Command, config *hollowNodeConfig) error {
 			return fmt.Errorf("Failed to generate fake endpoint, error: %w", err)
 		}
 		fakeRemoteRuntime := fakeremote.NewFakeRemoteRuntime()
+		switch {
+		case config.SimulatedImages > 0:
+			kubemark.PopulateImageCatalog(fakeRemoteRuntime.ImageService, config.SimulatedImages, config.SimulatedImageMeanMB, config.SimulatedImageSpreadMB)
+		case config.PaddingImages > 0:
+			fakeRemoteRuntime.ImageService.SetFakeImages(kubemark.PaddingImageNames(config.PaddingImages))
+		}
 		if err = fakeRemoteRuntime.Start(endpoint); err != nil {
 			return fmt.Errorf("Failed to start fake runtime, error: %w", err)
 		}
 		defer fakeRemoteRuntime.Stop()
+		if machineInfo, err := cadvisorInterface.MachineInfo(); err == nil {
+			statsSimulator := kubemark.NewContainerStatsSimulator(fakeRemoteRuntime.RuntimeService, int64(machineInfo.MemoryCapacity))
+			statsSimulator.Network = networkTraffic
+			statsSimulator.StorageGrowthBytesPerHour = int64(config.PodStorageGrowthMBPerHour) * 1024 * 1024
+			statsSimulator.EphemeralStorage = ephemeralStorage
+			statsSimulator.LogGrowthBytesPerHour = int64(config.LogGrowthMBPerHour) * 1024 * 1024
+			statsSimulator.LogCapBytes = logMaxSize.Value() * int64(config.ContainerLogMaxFiles)
+			statsSimulator.Run(wait.NeverStop)
+		}
+		if config.PIDCapacity > 0 {
+			pidSimulator := kubemark.NewPIDUsageSimulator(fakeRemoteRuntime.RuntimeService, int64(config.PIDCapacity), int64(config.SystemPIDs), int64(config.PIDsPerPod))
+			stats.RlimitStatsFn = pidSimulator.Stats
+		}
+		var crashPolicy *kubemark.CrashPolicy
+		if config.CrashPolicyPath != "" {
+			if crashPolicy, err = kubemark.LoadCrashPolicy(config.CrashPolicyPath); err != nil {
+				return fmt.Errorf("Failed to load crash policy, error: %w", err)
+			}
+		}
+		// Run even without a policy file so annotated pods can crash.
+		kubemark.NewCrashSimulator(fakeRemoteRuntime.RuntimeService, crashPolicy).Run(wait.NeverStop)
+		if config.OOMKillsPerHour > 0 {
+			kubemark.NewOOMSimulator(client, fakeRemoteRuntime.RuntimeService, config.NodeName, config.OOMKillsPerHour).Run(wait.NeverStop)
+		}
+		kubemark.NewInitContainerSimulator(fakeRemoteRuntime.RuntimeService, config.InitContainerDuration).Run(wait.NeverStop)
+		if config.LogLinesPerSecond > 0 {
+			kubemark.NewLogGenerator(fakeRemoteRuntime.RuntimeService, config.LogLinesPerSecond, config.ContainerLogDir).Run(wait.NeverStop)
+		}
 		runtimeService, err := remote.NewRemoteRuntimeService(endpoint, 15*time.Second)
 		if err != nil {
 			return fmt.Errorf("Failed to init runtime service, error: %w", err)
 		}
+		runtimeService = kubemark.NewSimulatedRuntimeService(runtimeService, config.RuntimeOpLatency, config.PodTerminationLatency, config.TerminationGraceCap)
+		if config.RuntimeName != "" || config.RuntimeVersion != "" {
+			runtimeService = kubemark.NewRuntimeVersionService(runtimeService, config.RuntimeName, config.RuntimeVersion)
+		}
+		runtimeService = kubemark.NewContainerProgressionService(runtimeService, config.ContainerStartupDuration)
+		// Serve real exec/attach streams so `kubectl exec` and interactive
+		// `kubectl debug` work against hollow pods.
+		runtimeService, err = kubemark.NewDebugStreamingService(runtimeService)
+		if err != nil {
+			return fmt.Errorf("Failed to init debug streaming, error: %w", err)
+		}
+		if config.IPConflictFraction > 0 || config.IPDelayFraction > 0 {
+			runtimeService, err = kubemark.NewPodIPSimulatorService(runtimeService, config.PodCIDR, config.IPConflictFraction, config.IPDelayFraction, config.IPAssignmentDelay)
+			if err != nil {
+				return fmt.Errorf("Failed to init pod IP simulation, error: %w", err)
+			}
+		}
 
 		var imageService internalapi.ImageManagerService = fakeRemoteRuntime.ImageService
 		if config.UseHostImageService {
@@ -275,6 +991,17 @@ func run(cmd *cobra.Command, config *hollowNodeConfig) error {
 				return fmt.Errorf("Failed to init image service, error: %w", err)
 			}
 		}
+		if config.ImagePullLatency > 0 || len(config.ImagePullFailureRates) > 0 {
+			failureRates := make(map[string]float64, len(config.ImagePullFailureRates))
+			for registry, value := range config.ImagePullFailureRates {
+				rate, err := strconv.ParseFloat(value, 64)
+				if err != nil || rate < 0 || rate > 1 {
+					return fmt.Errorf("invalid pull failure rate %q for registry %q, must be in [0,1]", value, registry)
+				}
+				failureRates[registry] = rate
+			}
+			imageService = kubemark.NewSimulatedImageService(imageService, config.ImagePullLatency, config.ImagePullCachedLatency, failureRates)
+		}
 
 		hollowKubelet := kubemark.NewHollowKubelet(
 			f, c,
@@ -285,11 +1012,105 @@ func run(cmd *cobra.Command, config *hollowNodeConfig) error {
 			runtimeService,
 			containerManager,
 		)
+		if profile != nil && profile.Events != nil && client != nil {
+			// Reflect the profile's event throttle in /configz as well.
+			if profile.Events.RecordQPS > 0 {
+				c.EventRecordQPS = int32(profile.Events.RecordQPS)
+			}
+			if profile.Events.RecordBurst > 0 {
+				c.EventBurst = int32(profile.Events.RecordBurst)
+			}
+			if err := hollowKubelet.ConfigureEventBroadcaster(clientConfig, config.NodeName, profile.Events); err != nil {
+				return fmt.Errorf("Failed to configure event broadcaster, error: %w", err)
+			}
+		}
+		if client != nil {
+			if config.AdminPort > 0 {
+				kubemark.RegisterPodsHandler(client, config.NodeName)
+			}
+			kubemark.NewPodCountTracker(client, config.NodeName, config.MaxPods).Run(wait.NeverStop)
+			if config.ManagedFieldsManagers > 0 {
+				kubemark.NewManagedFieldsGrower(client, config.NodeName, config.ManagedFieldsInterval, config.ManagedFieldsManagers).Run(wait.NeverStop)
+			}
+			if config.TokenRotationExpiration > 0 {
+				kubemark.NewTokenRotationSimulator(client, config.NodeName, config.TokenRotationExpiration).Run(wait.NeverStop)
+			}
+			if config.ConditionSchedulePath != "" {
+				schedule, err := kubemark.LoadConditionSchedule(config.ConditionSchedulePath)
+				if err != nil {
+					return fmt.Errorf("Failed to load condition schedule, error: %w", err)
+				}
+				kubemark.NewConditionScheduleRunner(client, config.NodeName, schedule).Run(wait.NeverStop)
+			}
+			kubemark.NewTaintEvictionObserver(client, config.NodeName).Run(wait.NeverStop)
+			if config.SandboxRestartRate > 0 {
+				kubemark.NewSandboxChurner(client, config.NodeName, config.SandboxRestartRate).Run(wait.NeverStop)
+			}
+			if config.NetworkReadyDelay > 0 {
+				kubemark.NewNetworkReadinessSimulator(client, config.NodeName, config.NetworkReadyDelay).Run(wait.NeverStop)
+			}
+			if config.zone() != "" {
+				kubemark.NewOutageWatcher(client).Run(wait.NeverStop)
+			}
+			if config.PaddingVolumes > 0 || config.PaddingConditions > 0 {
+				kubemark.NewNodeStatusPadder(client, config.NodeName, config.PaddingVolumes, config.PaddingConditions).Run(wait.NeverStop)
+			}
+			if config.ReportVolumeStatus {
+				kubemark.NewVolumeStatusReporter(client, config.NodeName).Run(wait.NeverStop)
+			}
+			orphanCleaner := kubemark.NewOrphanedPodCleaner(client, config.NodeName, f.RootDirectory)
+			orphanCleaner.CleanupOnStart()
+			orphanCleaner.Run(wait.NeverStop)
+		}
+		if config.ProbeReadyDelay > 0 || config.ProbeFlakeFraction > 0 || config.LivenessFailuresPerHour > 0 {
+			probeSimulator := kubemark.NewProbeSimulator(config.ProbeReadyDelay, config.ProbeFlakeFraction, config.ProbeFlakePeriod)
+			probeSimulator.LivenessFailuresPerHour = config.LivenessFailuresPerHour
+			probeSimulator.Runtime = fakeRemoteRuntime.RuntimeService
+			probeSimulator.Run(wait.NeverStop)
+			hollowKubelet.KubeletDeps.ProbeManager = probeSimulator
+		}
+		if config.ShutdownGracePeriods != "" && client != nil {
+			tiers, err := kubemark.ParseShutdownGracePeriods(config.ShutdownGracePeriods)
+			if err != nil {
+				return fmt.Errorf("invalid --shutdown-grace-periods: %w", err)
+			}
+			kubemark.NewGracefulShutdownSimulator(client, config.NodeName, tiers).HandleSignals()
+		}
+		if config.EnablePriorityAdmission {
+			hollowKubelet.EnablePriorityAdmission(client, config.NodeName, config.MaxPods)
+		} else if config.EnforceMaxPods {
+			hollowKubelet.EnforceMaxPods(config.MaxPods)
+		}
+		if config.StrictAffinityValidation {
+			hollowKubelet.EnableAffinityValidation(client, config.NodeName)
+		}
+		if config.EnforceHostPorts {
+			hollowKubelet.EnableHostPortAdmission(config.NodeName)
+		}
+		if config.ValidateDownwardAPI {
+			hollowKubelet.EnableDownwardAPIValidation(client, config.NodeName)
+		}
+		if config.MeasureBindingLatency {
+			hollowKubelet.EnableBindingLatencyObservation()
+		}
+		if config.SimulatedCertLifetime > 0 {
+			kubemark.NewCertificateExpirySimulator(config.SimulatedCertLifetime).Run(wait.NeverStop)
+		}
+		// With an apiserver present the kubelet's own mirror pod machinery
+		// takes over for the injected static pods: it creates the mirror
+		// pods, keeps their status in sync, recreates them if deleted and
+		// replaces them on API-side spec edits.
+		if config.SimulatedPodsDir != "" {
+			if err := hollowKubelet.InjectStaticPods(config.SimulatedPodsDir); err != nil {
+				return fmt.Errorf("Failed to inject simulated pods, error: %w", err)
+			}
+		}
+		kubemark.NotifySystemdReady()
 		hollowKubelet.Run()
 	}
 
 	if config.Morph == "proxy" {
-		clientConfig.UserAgent = "hollow-proxy"
+		clientConfig.UserAgent = config.userAgent("hollow-proxy")
 
 		client, err := clientset.NewForConfig(clientConfig)
 		if err != nil {
@@ -319,8 +1140,39 @@ func run(cmd *cobra.Command, config *hollowNodeConfig) error {
 		if err != nil {
 			return fmt.Errorf("Failed to create hollowProxy instance, error: %w", err)
 		}
+		kubemark.NotifySystemdReady()
 		return hollowProxy.Run()
 	}
 
+	if config.Morph == "node-dns" {
+		clientConfig.UserAgent = config.userAgent("hollow-node-dns")
+
+		client, err := clientset.NewForConfig(clientConfig)
+		if err != nil {
+			return fmt.Errorf("Failed to create API Server client, error: %w", err)
+		}
+		hollowDNS := kubemark.NewHollowNodeDNS(
+			client,
+			config.NodeName,
+			config.DNSConfigMapNamespace,
+			config.DNSConfigMapName,
+			config.DNSMetricsPort,
+		)
+		kubemark.NotifySystemdReady()
+		return hollowDNS.Run()
+	}
+
+	if config.Morph == "controller-manager" {
+		clientConfig.UserAgent = config.userAgent("hollow-controller-manager")
+
+		client, err := clientset.NewForConfig(clientConfig)
+		if err != nil {
+			return fmt.Errorf("Failed to create API Server client, error: %w", err)
+		}
+		hollowCM := kubemark.NewHollowControllerManager(client, config.ControllerResyncPeriod, config.ControllerWorkers)
+		kubemark.NotifySystemdReady()
+		return hollowCM.Run(wait.NeverStop)
+	}
+
 	return nil
 }
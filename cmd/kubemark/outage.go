@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubernetes/pkg/kubemark"
+)
+
+type outageConfig struct {
+	KubeconfigPath string
+	Zone           string
+	Mode           string
+	Duration       time.Duration
+	Clear          bool
+}
+
+// newOutageCommand creates the `kubemark outage` subcommand, the admin
+// action that applies a coordinated failure to every hollow node in a zone.
+func newOutageCommand() *cobra.Command {
+	c := &outageConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "outage",
+		Short: "Trigger or clear a simulated zone outage across the hollow-node fleet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runOutage(c)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig for the cluster under test.")
+	fs.StringVar(&c.Zone, "zone", "", "Zone label value of the hollow nodes to fail.")
+	fs.StringVar(&c.Mode, "mode", kubemark.OutageModeNotReady, fmt.Sprintf("Failure mode: %q suppresses heartbeats so nodes turn NotReady, %q fails all apiserver traffic from the zone.", kubemark.OutageModeNotReady, kubemark.OutageModePartition))
+	fs.DurationVar(&c.Duration, "duration", 5*time.Minute, "How long the outage lasts. Nodes recover on their own once it elapses.")
+	fs.BoolVar(&c.Clear, "clear", false, "Clear any active outage instead of starting one.")
+
+	return cmd
+}
+
+func runOutage(c *outageConfig) error {
+	clientConfig, err := clientcmd.BuildConfigFromFlags("", c.KubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("error while building kubeconfig from %v: %w", c.KubeconfigPath, err)
+	}
+	client, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to create a ClientSet, error: %w", err)
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(kubemark.OutageConfigMapNamespace)
+	if c.Clear {
+		err := configMaps.Delete(context.TODO(), kubemark.OutageConfigMapName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("Failed to clear outage, error: %w", err)
+		}
+		fmt.Println("Outage cleared.")
+		return nil
+	}
+
+	if c.Zone == "" {
+		return fmt.Errorf("--zone is required")
+	}
+	if c.Mode != kubemark.OutageModeNotReady && c.Mode != kubemark.OutageModePartition {
+		return fmt.Errorf("unknown outage mode %q", c.Mode)
+	}
+
+	until := time.Now().Add(c.Duration)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubemark.OutageConfigMapName,
+			Namespace: kubemark.OutageConfigMapNamespace,
+		},
+		Data: map[string]string{
+			"zone":  c.Zone,
+			"mode":  c.Mode,
+			"until": until.Format(time.RFC3339),
+		},
+	}
+	_, err = configMaps.Create(context.TODO(), cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to publish outage, error: %w", err)
+	}
+	fmt.Printf("Zone %s set to %s until %v.\n", c.Zone, c.Mode, until.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubernetes/pkg/kubemark/ramp"
+)
+
+type rampConfig struct {
+	ramp.Config
+	KubeconfigPath string
+}
+
+// newRampCommand creates the `kubemark ramp` subcommand, which grows the
+// hollow-node fleet through configured plateaus while recording
+// control-plane SLIs at each one.
+func newRampCommand() *cobra.Command {
+	c := &rampConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "ramp",
+		Short: "Run a scripted multi-phase ramp test against a hollow-node fleet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRamp(c)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig for the cluster under test.")
+	fs.StringVar(&c.FleetNamespace, "namespace", "kubemark", "Namespace the hollow-node fleet runs in.")
+	fs.StringVar(&c.FleetName, "fleet-name", "hollow-node", "Name of the hollow-node Deployment to scale.")
+	fs.IntSliceVar(&c.Phases, "phases", []int{500, 2000, 5000}, "Fleet sizes to plateau at, in order.")
+	fs.DurationVar(&c.Hold, "hold", 10*time.Minute, "How long each plateau is held while SLIs are sampled.")
+	fs.DurationVar(&c.ReadyTimeout, "ready-timeout", 20*time.Minute, "How long to wait for a phase's nodes to turn Ready before moving on.")
+	fs.DurationVar(&c.SampleInterval, "sample-interval", 5*time.Second, "Spacing of API latency samples during a plateau.")
+	fs.IntVar(&c.PodsPerNode, "pods-per-node", 0, "Pause pods to run per node at each plateau; 0 ramps nodes only.")
+	fs.StringVar(&c.WorkloadNamespace, "workload-namespace", "default", "Namespace the pause-pod workload runs in.")
+
+	return cmd
+}
+
+func runRamp(c *rampConfig) error {
+	if len(c.Phases) == 0 {
+		return fmt.Errorf("--phases must name at least one fleet size")
+	}
+	for i, nodes := range c.Phases {
+		if nodes <= 0 {
+			return fmt.Errorf("--phases entry %d must be positive, got %d", i, nodes)
+		}
+	}
+
+	clientConfig, err := clientcmd.BuildConfigFromFlags("", c.KubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("error while building kubeconfig from %v: %w", c.KubeconfigPath, err)
+	}
+	client, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to create a ClientSet, error: %w", err)
+	}
+
+	runner := ramp.NewRunner(client, c.Config)
+	reports, err := runner.Run(context.Background())
+	if len(reports) > 0 {
+		ramp.WriteReport(os.Stdout, reports)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to complete ramp, error: %w", err)
+	}
+	return nil
+}
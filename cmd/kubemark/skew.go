@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubernetes/pkg/kubemark"
+)
+
+type skewConfig struct {
+	KubeconfigPath string
+	GroupBy        string
+	Weights        map[string]string
+	Namespace      string
+	Selector       string
+}
+
+// newSkewCommand creates the `kubemark skew` subcommand, the post-run
+// analysis that compares actual pod distribution across node groups against
+// expected weights.
+func newSkewCommand() *cobra.Command {
+	c := &skewConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "skew",
+		Short: "Report pod placement skew across node profiles/zones against expected weights",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSkew(c)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig for the cluster under test.")
+	fs.StringVar(&c.GroupBy, "group-by", v1.LabelTopologyZone, "Node label key that partitions the fleet into groups.")
+	fs.StringToStringVar(&c.Weights, "weights", nil, "Expected relative pod share per group value (e.g. zone-a=2,zone-b=1). Unset means each node carries equal weight.")
+	fs.StringVar(&c.Namespace, "namespace", "", "Namespace of the pods to analyze. Empty analyzes all namespaces.")
+	fs.StringVar(&c.Selector, "selector", "", "Label selector restricting the analyzed pods.")
+
+	return cmd
+}
+
+func runSkew(c *skewConfig) error {
+	clientConfig, err := clientcmd.BuildConfigFromFlags("", c.KubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("error while building kubeconfig from %v: %w", c.KubeconfigPath, err)
+	}
+	client, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to create a ClientSet, error: %w", err)
+	}
+
+	weights := make(map[string]float64, len(c.Weights))
+	for group, value := range c.Weights {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 {
+			return fmt.Errorf("invalid weight %q for group %q, must be a non-negative number", value, group)
+		}
+		weights[group] = weight
+	}
+
+	report, err := kubemark.AnalyzePlacementSkew(context.TODO(), client, kubemark.PlacementSkewConfig{
+		GroupBy:   c.GroupBy,
+		Weights:   weights,
+		Namespace: c.Namespace,
+		Selector:  c.Selector,
+	})
+	if err != nil {
+		return err
+	}
+	report.WriteReport(os.Stdout)
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubernetes/pkg/kubemark"
+)
+
+type teardownConfig struct {
+	kubemark.TeardownConfig
+	KubeconfigPath string
+}
+
+// newTeardownCommand creates the `kubemark teardown` subcommand, which
+// removes a hollow-node fleet at a bounded API rate.
+func newTeardownCommand() *cobra.Command {
+	c := &teardownConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "teardown",
+		Short: "Delete a hollow-node fleet and its pods at a bounded API rate",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTeardown(c)
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig for the cluster under test.")
+	fs.StringVar(&c.FleetNamespace, "namespace", "kubemark", "Namespace the hollow-node fleet runs in.")
+	fs.StringVar(&c.FleetName, "fleet-name", "hollow-node", "Name of the hollow-node Deployment to tear down.")
+	fs.Float64Var(&c.QPS, "qps", 50, "Aggregate cap on delete calls per second during teardown.")
+	fs.IntVar(&c.Burst, "burst", 10, "Token bucket depth behind --qps.")
+	fs.IntVar(&c.Workers, "workers", 10, "How many nodes are torn down concurrently.")
+
+	return cmd
+}
+
+func runTeardown(c *teardownConfig) error {
+	if c.QPS <= 0 {
+		return fmt.Errorf("--qps must be positive")
+	}
+	clientConfig, err := clientcmd.BuildConfigFromFlags("", c.KubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("error while building kubeconfig from %v: %w", c.KubeconfigPath, err)
+	}
+	client, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to create a ClientSet, error: %w", err)
+	}
+
+	start := time.Now()
+	summary, err := kubemark.Teardown(context.TODO(), client, c.TeardownConfig)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d nodes and %d pods in %v.\n", summary.Nodes, summary.Pods, time.Since(start).Round(time.Second))
+	return nil
+}
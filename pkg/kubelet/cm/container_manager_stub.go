@@ -17,14 +17,26 @@ limitations under the License.
 package cm
 
 import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 	internalapi "k8s.io/cri-api/pkg/apis"
 	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+	v1resource "k8s.io/kubernetes/pkg/api/v1/resource"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/kubelet/cm/containermap"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
 	"k8s.io/kubernetes/pkg/kubelet/cm/memorymanager"
+	memorymanagerstate "k8s.io/kubernetes/pkg/kubelet/cm/memorymanager/state"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
 	"k8s.io/kubernetes/pkg/kubelet/config"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
@@ -36,13 +48,93 @@ import (
 
 type containerManagerStub struct {
 	shouldResetExtendedResourceCapacity bool
-	extendedPluginResources             v1.ResourceList
+	// extendedResourcesLock guards the plugin resource fields, which can be
+	// mutated at runtime via SetDevicePluginResourceCapacity.
+	extendedResourcesLock   sync.RWMutex
+	extendedPluginResources v1.ResourceList
+	// removedPluginResources are resource names that were reported earlier
+	// but have since been removed, so the kubelet zeroes them out.
+	removedPluginResources []string
+	// unhealthyPluginResources holds, per resource, how many simulated
+	// devices are currently unhealthy; they are subtracted from allocatable.
+	unhealthyPluginResources v1.ResourceList
+	// cpuManager and memoryManager, when set, are real managers run over a
+	// synthetic topology so exclusive CPU assignment and NUMA-pinned
+	// memory/hugepages can be exercised without real hardware.
+	cpuManager    cpumanager.Manager
+	memoryManager memorymanager.Manager
+	// topologyManager, when set, is a real topology manager with the CPU and
+	// memory managers registered as hint providers, so TopologyAffinityError
+	// admission rejections occur as they would on real NUMA hardware.
+	topologyManager topologymanager.Manager
+	// hugePagesCapacity is extra hugepages-* capacity the stub reports.
+	hugePagesCapacity v1.ResourceList
+	// capacity, nodeAllocatableReservation and nodeConfig, when set from a
+	// node profile, replace the empty defaults so the hollow node looks
+	// like a specific real instance type. capacity may be mutated at
+	// runtime through SetCapacity, guarded by capacityLock.
+	capacityLock               sync.RWMutex
+	capacity                   v1.ResourceList
+	nodeAllocatableReservation v1.ResourceList
+	nodeConfig                 NodeConfig
+	// activePods, captured at Start, lets UpdateQOSCgroups aggregate the
+	// requests of the pods on the node per QoS class.
+	activePods ActivePodsFunc
+	// podContainerManager is the shared stateful PodContainerManager, created
+	// on first use so all NewPodContainerManager callers see the same
+	// simulated pod cgroups.
+	podContainerManagerOnce sync.Once
+	podContainerManager     *podContainerManagerStub
+	// cgroupOpError, when set, is consulted before each simulated cgroup
+	// operation and may return an injected failure.
+	cgroupOpError func(operation string) error
+	// cgroupVersion selects the cgroup hierarchy GetMountedSubsystems
+	// reports: 1 (per-controller mounts), 2 (unified) or 0 (none).
+	cgroupVersion int
+}
+
+var (
+	qosClassRequests = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "kubemark",
+			Name:           "qos_class_requests",
+			Help:           "Aggregate resource requests of active pods on this hollow node, partitioned by QoS class and resource, in milli-CPU and bytes.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"qos", "resource"},
+	)
+	qosCgroupUpdatesTotal = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      "kubemark",
+			Name:           "qos_cgroup_updates_total",
+			Help:           "Number of UpdateQOSCgroups calls handled by the stub container manager.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(qosClassRequests)
+	legacyregistry.MustRegister(qosCgroupUpdatesTotal)
 }
 
 var _ ContainerManager = &containerManagerStub{}
 
-func (cm *containerManagerStub) Start(_ *v1.Node, _ ActivePodsFunc, _ config.SourcesReady, _ status.PodStatusProvider, _ internalapi.RuntimeService) error {
+func (cm *containerManagerStub) Start(_ *v1.Node, activePods ActivePodsFunc, sourcesReady config.SourcesReady, podStatusProvider status.PodStatusProvider, runtimeService internalapi.RuntimeService) error {
 	klog.V(2).InfoS("Starting stub container manager")
+	cm.activePods = activePods
+	// The stub starts with no running containers, so the initial container
+	// maps are empty.
+	if cm.cpuManager != nil {
+		if err := cm.cpuManager.Start(cpumanager.ActivePodsFunc(activePods), sourcesReady, podStatusProvider, runtimeService, containermap.NewContainerMap()); err != nil {
+			return err
+		}
+	}
+	if cm.memoryManager != nil {
+		if err := cm.memoryManager.Start(memorymanager.ActivePodsFunc(activePods), sourcesReady, podStatusProvider, runtimeService, containermap.NewContainerMap()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -51,18 +143,63 @@ func (cm *containerManagerStub) SystemCgroupsLimit() v1.ResourceList {
 }
 
 func (cm *containerManagerStub) GetNodeConfig() NodeConfig {
-	return NodeConfig{}
+	return cm.nodeConfig
 }
 
 func (cm *containerManagerStub) GetMountedSubsystems() *CgroupSubsystems {
-	return &CgroupSubsystems{}
+	subsystems := &CgroupSubsystems{MountPoints: map[string]string{}}
+	switch cm.cgroupVersion {
+	case 1:
+		// The per-controller hierarchy of a cgroup v1 host.
+		for _, subsystem := range []string{"blkio", "cpu", "cpuacct", "cpuset", "devices", "freezer", "hugetlb", "memory", "net_cls", "net_prio", "perf_event", "pids", "systemd"} {
+			subsystems.MountPoints[subsystem] = "/sys/fs/cgroup/" + subsystem
+		}
+	case 2:
+		// The unified hierarchy: every controller at the cgroup root.
+		for _, controller := range []string{"cpu", "cpuset", "memory", "io", "pids", "hugetlb"} {
+			subsystems.MountPoints[controller] = "/sys/fs/cgroup"
+		}
+	}
+	return subsystems
 }
 
 func (cm *containerManagerStub) GetQOSContainersInfo() QOSContainersInfo {
-	return QOSContainersInfo{}
+	return QOSContainersInfo{
+		Guaranteed: RootCgroupName,
+		Burstable:  NewCgroupName(RootCgroupName, "kubepods", strings.ToLower(string(v1.PodQOSBurstable))),
+		BestEffort: NewCgroupName(RootCgroupName, "kubepods", strings.ToLower(string(v1.PodQOSBestEffort))),
+	}
 }
 
 func (cm *containerManagerStub) UpdateQOSCgroups() error {
+	qosCgroupUpdatesTotal.Inc()
+	if cm.cgroupOpError != nil {
+		if err := cm.cgroupOpError("update QOS cgroups"); err != nil {
+			return err
+		}
+	}
+	if cm.activePods == nil {
+		return nil
+	}
+	// Aggregate the requests of active pods per QoS class, standing in for
+	// the Burstable/BestEffort cgroup limits a real cgroup manager would
+	// apply.
+	type aggregate struct{ milliCPU, memory int64 }
+	perClass := map[v1.PodQOSClass]*aggregate{
+		v1.PodQOSGuaranteed: {},
+		v1.PodQOSBurstable:  {},
+		v1.PodQOSBestEffort: {},
+	}
+	for _, pod := range cm.activePods() {
+		requests, _ := v1resource.PodRequestsAndLimits(pod)
+		class := perClass[v1qos.GetPodQOS(pod)]
+		class.milliCPU += requests.Cpu().MilliValue()
+		class.memory += requests.Memory().Value()
+	}
+	for qosClass, totals := range perClass {
+		qosClassRequests.WithLabelValues(string(qosClass), "cpu").Set(float64(totals.milliCPU))
+		qosClassRequests.WithLabelValues(string(qosClass), "memory").Set(float64(totals.memory))
+	}
 	return nil
 }
 
@@ -71,52 +208,245 @@ func (cm *containerManagerStub) Status() Status {
 }
 
 func (cm *containerManagerStub) GetNodeAllocatableReservation() v1.ResourceList {
-	return nil
+	return cm.nodeAllocatableReservation
 }
 
 func (cm *containerManagerStub) GetCapacity() v1.ResourceList {
+	cm.capacityLock.RLock()
+	defer cm.capacityLock.RUnlock()
 	c := v1.ResourceList{
 		v1.ResourceEphemeralStorage: *resource.NewQuantity(
 			int64(0),
 			resource.BinarySI),
 	}
+	for name, quantity := range cm.capacity {
+		c[name] = quantity
+	}
+	for name, quantity := range cm.hugePagesCapacity {
+		c[name] = quantity
+	}
 	return c
 }
 
+// SetCapacity changes a single resource's reported machine capacity at
+// runtime; the next node status sync picks up the new value.
+func (cm *containerManagerStub) SetCapacity(name v1.ResourceName, quantity resource.Quantity) {
+	cm.capacityLock.Lock()
+	defer cm.capacityLock.Unlock()
+	if cm.capacity == nil {
+		cm.capacity = v1.ResourceList{}
+	}
+	cm.capacity[name] = quantity
+}
+
 func (cm *containerManagerStub) GetPluginRegistrationHandler() cache.PluginHandler {
 	return nil
 }
 
 func (cm *containerManagerStub) GetDevicePluginResourceCapacity() (v1.ResourceList, v1.ResourceList, []string) {
-	return cm.extendedPluginResources, cm.extendedPluginResources, []string{}
+	cm.extendedResourcesLock.RLock()
+	defer cm.extendedResourcesLock.RUnlock()
+	if len(cm.unhealthyPluginResources) == 0 {
+		return cm.extendedPluginResources, cm.extendedPluginResources, cm.removedPluginResources
+	}
+	// Allocatable shrinks by the currently unhealthy devices, as it does
+	// when a real device plugin reports devices Unhealthy.
+	allocatable := v1.ResourceList{}
+	for name, capacity := range cm.extendedPluginResources {
+		healthy := capacity.Value()
+		if unhealthy, ok := cm.unhealthyPluginResources[name]; ok {
+			healthy -= unhealthy.Value()
+			if healthy < 0 {
+				healthy = 0
+			}
+		}
+		allocatable[name] = *resource.NewQuantity(healthy, resource.DecimalSI)
+	}
+	return cm.extendedPluginResources, allocatable, cm.removedPluginResources
+}
+
+// SetDevicePluginResourceUnhealthy marks the given number of devices per
+// resource as unhealthy, shrinking the allocatable counts reported to the
+// kubelet until a later call clears or changes them.
+func (cm *containerManagerStub) SetDevicePluginResourceUnhealthy(unhealthy v1.ResourceList) {
+	cm.extendedResourcesLock.Lock()
+	defer cm.extendedResourcesLock.Unlock()
+	cm.unhealthyPluginResources = unhealthy
+}
+
+// SetDevicePluginResourceCapacity replaces the simulated device plugin
+// capacity at runtime. Resources no longer present keep being reported as
+// removed so the kubelet zeroes their capacity in node status.
+func (cm *containerManagerStub) SetDevicePluginResourceCapacity(resources v1.ResourceList) {
+	cm.extendedResourcesLock.Lock()
+	defer cm.extendedResourcesLock.Unlock()
+	for name := range cm.extendedPluginResources {
+		if _, stillPresent := resources[name]; !stillPresent {
+			cm.removedPluginResources = append(cm.removedPluginResources, string(name))
+		}
+	}
+	cm.extendedPluginResources = resources
 }
 
 func (cm *containerManagerStub) NewPodContainerManager() PodContainerManager {
-	return &podContainerManagerStub{}
+	// The kubelet constructs a fresh PodContainerManager per use, so the
+	// simulated pod cgroup state has to live on the container manager for
+	// Exists/GetAllPodsFromCgroups to see cgroups created earlier.
+	cm.podContainerManagerOnce.Do(func() {
+		cm.podContainerManager = &podContainerManagerStub{cgroupOpError: cm.cgroupOpError}
+	})
+	return cm.podContainerManager
 }
 
+// nvidiaGPUResourceName is the canonical GPU device plugin resource; for
+// containers requesting it, GetResources mimics the NVIDIA device plugin's
+// allocate response.
+const nvidiaGPUResourceName = v1.ResourceName("nvidia.com/gpu")
+
 func (cm *containerManagerStub) GetResources(pod *v1.Pod, container *v1.Container) (*kubecontainer.RunContainerOptions, error) {
-	return &kubecontainer.RunContainerOptions{}, nil
+	opts := &kubecontainer.RunContainerOptions{}
+	gpus, ok := container.Resources.Limits[nvidiaGPUResourceName]
+	if !ok || gpus.Value() <= 0 {
+		return opts, nil
+	}
+	// Assign device indices deterministically from the pod/container
+	// identity, as GetDevices does, so runtime options and podresources
+	// responses agree with each other.
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s/%s", pod.UID, container.Name)
+	cm.extendedResourcesLock.RLock()
+	capacity := cm.extendedPluginResources[nvidiaGPUResourceName]
+	cm.extendedResourcesLock.RUnlock()
+	total := capacity.Value()
+	if total <= 0 {
+		total = gpus.Value()
+	}
+	first := int64(hash.Sum32()) % total
+	indices := make([]string, 0, gpus.Value())
+	for i := int64(0); i < gpus.Value(); i++ {
+		index := (first + i) % total
+		indices = append(indices, strconv.FormatInt(index, 10))
+		opts.Devices = append(opts.Devices, kubecontainer.DeviceInfo{
+			PathOnHost:      fmt.Sprintf("/dev/nvidia%d", index),
+			PathInContainer: fmt.Sprintf("/dev/nvidia%d", index),
+			Permissions:     "mrw",
+		})
+	}
+	// The control devices and env the NVIDIA device plugin injects for
+	// every allocation.
+	for _, control := range []string{"/dev/nvidiactl", "/dev/nvidia-uvm", "/dev/nvidia-uvm-tools"} {
+		opts.Devices = append(opts.Devices, kubecontainer.DeviceInfo{
+			PathOnHost:      control,
+			PathInContainer: control,
+			Permissions:     "mrw",
+		})
+	}
+	opts.Envs = append(opts.Envs,
+		kubecontainer.EnvVar{Name: "NVIDIA_VISIBLE_DEVICES", Value: strings.Join(indices, ",")},
+		kubecontainer.EnvVar{Name: "NVIDIA_DRIVER_CAPABILITIES", Value: "compute,utility"},
+	)
+	opts.Annotations = append(opts.Annotations, kubecontainer.Annotation{
+		Name:  "nvidia.com/gpu.count",
+		Value: strconv.FormatInt(gpus.Value(), 10),
+	})
+	return opts, nil
 }
 
-func (cm *containerManagerStub) UpdatePluginResources(*schedulerframework.NodeInfo, *lifecycle.PodAdmitAttributes) error {
+func (cm *containerManagerStub) UpdatePluginResources(node *schedulerframework.NodeInfo, attrs *lifecycle.PodAdmitAttributes) error {
+	_, allocatable, _ := cm.GetDevicePluginResourceCapacity()
+	if len(allocatable) == 0 {
+		return nil
+	}
+	// Enforce the simulated device plugin capacity during admission, as the
+	// device manager does on a real node. The node object can be stale
+	// during scheduler races, so the predicate check runs against the
+	// stub's current allocatable; pods exceeding it are rejected with
+	// OutOf<resource> instead of silently accepted.
+	for name, quantity := range allocatable {
+		node.Allocatable.SetScalar(name, quantity.Value())
+	}
 	return nil
 }
 
 func (cm *containerManagerStub) InternalContainerLifecycle() InternalContainerLifecycle {
-	return &internalContainerLifecycleImpl{cpumanager.NewFakeManager(), memorymanager.NewFakeManager(), topologymanager.NewFakeManager()}
+	cpuManager := cm.cpuManager
+	if cpuManager == nil {
+		cpuManager = cpumanager.NewFakeManager()
+	}
+	memoryManager := cm.memoryManager
+	if memoryManager == nil {
+		memoryManager = memorymanager.NewFakeManager()
+	}
+	topologyManager := cm.topologyManager
+	if topologyManager == nil {
+		topologyManager = topologymanager.NewFakeManager()
+	}
+	return &internalContainerLifecycleImpl{cpuManager, memoryManager, topologyManager}
 }
 
 func (cm *containerManagerStub) GetPodCgroupRoot() string {
 	return ""
 }
 
-func (cm *containerManagerStub) GetDevices(_, _ string) []*podresourcesapi.ContainerDevices {
-	return nil
+// simulatedNUMANodes is how many NUMA nodes the stub pretends the machine
+// has; simulated devices are spread across them round-robin.
+const simulatedNUMANodes = 2
+
+// simulatedDevices fabricates ContainerDevices for the stub's device plugin
+// resources. Device IDs are deterministic ("<resource>-<i>") and each
+// carries a NUMA topology hint, so podresources API consumers such as
+// topology-aware schedulers can be exercised against hollow nodes.
+func (cm *containerManagerStub) simulatedDevices(perResource func(name v1.ResourceName, quantity int64) []int64) []*podresourcesapi.ContainerDevices {
+	cm.extendedResourcesLock.RLock()
+	defer cm.extendedResourcesLock.RUnlock()
+	var devices []*podresourcesapi.ContainerDevices
+	for name, quantity := range cm.extendedPluginResources {
+		for _, i := range perResource(name, quantity.Value()) {
+			devices = append(devices, &podresourcesapi.ContainerDevices{
+				ResourceName: string(name),
+				DeviceIds:    []string{fmt.Sprintf("%s-%d", name.String(), i)},
+				Topology: &podresourcesapi.TopologyInfo{
+					Nodes: []*podresourcesapi.NUMANode{{ID: i % simulatedNUMANodes}},
+				},
+			})
+		}
+	}
+	return devices
+}
+
+func (cm *containerManagerStub) GetDevices(podUID, containerName string) []*podresourcesapi.ContainerDevices {
+	cm.extendedResourcesLock.RLock()
+	empty := len(cm.extendedPluginResources) == 0
+	cm.extendedResourcesLock.RUnlock()
+	if empty {
+		return nil
+	}
+	// Pretend each container was allocated one device per plugin resource,
+	// picking a stable index from the pod/container identity so repeated
+	// queries agree with each other.
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s/%s", podUID, containerName)
+	return cm.simulatedDevices(func(_ v1.ResourceName, quantity int64) []int64 {
+		if quantity <= 0 {
+			return nil
+		}
+		return []int64{int64(hash.Sum32()) % quantity}
+	})
 }
 
 func (cm *containerManagerStub) GetAllocatableDevices() []*podresourcesapi.ContainerDevices {
-	return nil
+	return cm.simulatedDevices(func(name v1.ResourceName, quantity int64) []int64 {
+		// Unhealthy devices (the highest-numbered ones, for determinism)
+		// are not allocatable.
+		if unhealthy, ok := cm.unhealthyPluginResources[name]; ok {
+			quantity -= unhealthy.Value()
+		}
+		ids := make([]int64, 0, quantity)
+		for i := int64(0); i < quantity; i++ {
+			ids = append(ids, i)
+		}
+		return ids
+	})
 }
 
 func (cm *containerManagerStub) ShouldResetExtendedResourceCapacity() bool {
@@ -124,29 +454,116 @@ func (cm *containerManagerStub) ShouldResetExtendedResourceCapacity() bool {
 }
 
 func (cm *containerManagerStub) GetAllocateResourcesPodAdmitHandler() lifecycle.PodAdmitHandler {
+	// A real topology manager admits pods itself, merging the hints of the
+	// providers registered with it and allocating through them.
+	if cm.topologyManager != nil {
+		return cm.topologyManager
+	}
+	if cm.cpuManager != nil || cm.memoryManager != nil {
+		return &stubResourceAllocator{cpuManager: cm.cpuManager, memoryManager: cm.memoryManager}
+	}
 	return topologymanager.NewFakeManager()
 }
 
+// stubResourceAllocator routes pod admission through the real CPU and memory
+// managers so their static policies can reserve exclusive CPUs and
+// NUMA-pinned memory, mirroring the resource allocator used by the full
+// container manager.
+type stubResourceAllocator struct {
+	cpuManager    cpumanager.Manager
+	memoryManager memorymanager.Manager
+}
+
+func (a *stubResourceAllocator) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	pod := attrs.Pod
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if a.cpuManager != nil {
+			if err := a.cpuManager.Allocate(pod, &container); err != nil {
+				return admitRejectedByAllocation(err)
+			}
+		}
+		if a.memoryManager != nil {
+			if err := a.memoryManager.Allocate(pod, &container); err != nil {
+				return admitRejectedByAllocation(err)
+			}
+		}
+	}
+	return lifecycle.PodAdmitResult{Admit: true}
+}
+
+func admitRejectedByAllocation(err error) lifecycle.PodAdmitResult {
+	return lifecycle.PodAdmitResult{
+		Admit:   false,
+		Reason:  "UnexpectedAdmissionError",
+		Message: fmt.Sprintf("Allocate failed due to %v, which is unexpected", err),
+	}
+}
+
 func (cm *containerManagerStub) UpdateAllocatedDevices() {
 	return
 }
 
-func (cm *containerManagerStub) GetCPUs(_, _ string) []int64 {
+func (cm *containerManagerStub) GetCPUs(podUID, containerName string) []int64 {
+	if cm.cpuManager != nil {
+		return cm.cpuManager.GetExclusiveCPUs(podUID, containerName).ToSliceNoSortInt64()
+	}
 	return nil
 }
 
 func (cm *containerManagerStub) GetAllocatableCPUs() []int64 {
+	if cm.cpuManager != nil {
+		return cm.cpuManager.GetAllocatableCPUs().ToSliceNoSortInt64()
+	}
+	// Without a real CPU manager, enumerate CPU IDs from the configured
+	// capacity so podresources GetAllocatableResources still reports the
+	// CPU inventory monitoring agents expect.
+	cm.capacityLock.RLock()
+	defer cm.capacityLock.RUnlock()
+	if cpus, ok := cm.capacity[v1.ResourceCPU]; ok {
+		ids := make([]int64, 0, cpus.Value())
+		for i := int64(0); i < cpus.Value(); i++ {
+			ids = append(ids, i)
+		}
+		return ids
+	}
 	return nil
 }
 
-func (cm *containerManagerStub) GetMemory(_, _ string) []*podresourcesapi.ContainerMemory {
+func (cm *containerManagerStub) GetMemory(podUID, containerName string) []*podresourcesapi.ContainerMemory {
+	if cm.memoryManager != nil {
+		return stubContainerMemoryFromBlock(cm.memoryManager.GetMemory(podUID, containerName))
+	}
 	return nil
 }
 
 func (cm *containerManagerStub) GetAllocatableMemory() []*podresourcesapi.ContainerMemory {
+	if cm.memoryManager != nil {
+		return stubContainerMemoryFromBlock(cm.memoryManager.GetAllocatableMemory())
+	}
 	return nil
 }
 
+// stubContainerMemoryFromBlock converts memory manager blocks to the
+// podresources API shape, like containerMemoryFromBlock in the full
+// container manager.
+func stubContainerMemoryFromBlock(blocks []memorymanagerstate.Block) []*podresourcesapi.ContainerMemory {
+	var containerMemories []*podresourcesapi.ContainerMemory
+	for _, b := range blocks {
+		containerMemory := podresourcesapi.ContainerMemory{
+			MemoryType: string(b.Type),
+			Size_:      b.Size,
+			Topology: &podresourcesapi.TopologyInfo{
+				Nodes: []*podresourcesapi.NUMANode{},
+			},
+		}
+		for _, numaNodeID := range b.NUMAAffinity {
+			containerMemory.Topology.Nodes = append(containerMemory.Topology.Nodes, &podresourcesapi.NUMANode{ID: int64(numaNodeID)})
+		}
+		containerMemories = append(containerMemories, &containerMemory)
+	}
+	return containerMemories
+}
+
 func (cm *containerManagerStub) GetNodeAllocatableAbsolute() v1.ResourceList {
 	return nil
 }
@@ -165,3 +582,75 @@ func NewStubContainerManagerWithDevicePluginResource(extendedPluginResources v1.
 		extendedPluginResources:             extendedPluginResources,
 	}
 }
+
+// NewStubContainerManagerWithManagers creates a stub container manager that
+// delegates CPU and memory accounting to the given (real) managers,
+// typically ones running static policies over a synthetic topology. Either
+// manager may be nil, in which case the corresponding fake is used, and
+// hugePagesCapacity is extra hugepages-* capacity to report.
+func NewStubContainerManagerWithManagers(cpuManager cpumanager.Manager, memoryManager memorymanager.Manager, extendedPluginResources, hugePagesCapacity v1.ResourceList) ContainerManager {
+	return &containerManagerStub{
+		shouldResetExtendedResourceCapacity: false,
+		extendedPluginResources:             extendedPluginResources,
+		cpuManager:                          cpuManager,
+		memoryManager:                       memoryManager,
+		hugePagesCapacity:                   hugePagesCapacity,
+	}
+}
+
+// StubManagerOptions groups the optional pieces of a stub container manager,
+// for callers that combine several of them (e.g. a node profile plus real
+// CPU/memory managers).
+type StubManagerOptions struct {
+	CPUManager    cpumanager.Manager
+	MemoryManager memorymanager.Manager
+	// TopologyManager, when set, replaces the fake topology manager so
+	// single-numa-node/restricted/best-effort admission is enforced. The CPU
+	// and memory managers above are registered as its hint providers.
+	TopologyManager         topologymanager.Manager
+	ExtendedPluginResources v1.ResourceList
+	HugePagesCapacity       v1.ResourceList
+	// Capacity replaces the stub's (near-empty) GetCapacity response.
+	Capacity v1.ResourceList
+	// NodeAllocatableReservation is returned from
+	// GetNodeAllocatableReservation.
+	NodeAllocatableReservation v1.ResourceList
+	// NodeConfig is returned from GetNodeConfig.
+	NodeConfig NodeConfig
+	// CgroupOpError, when set, is consulted before each simulated cgroup
+	// operation (QoS cgroup updates, pod cgroup creation/destruction) and
+	// may return an injected failure, exercising the kubelet's cgroup
+	// error-handling paths.
+	CgroupOpError func(operation string) error
+	// CgroupVersion selects whether GetMountedSubsystems reports a cgroup
+	// v1 (per-controller) or v2 (unified) hierarchy; 0 reports none, the
+	// legacy stub behavior. The driver reported alongside it comes from
+	// NodeConfig.CgroupDriver.
+	CgroupVersion int
+}
+
+// NewStubContainerManagerWithOptions creates a stub container manager from
+// the given options.
+func NewStubContainerManagerWithOptions(options StubManagerOptions) ContainerManager {
+	if options.TopologyManager != nil {
+		if options.CPUManager != nil {
+			options.TopologyManager.AddHintProvider(options.CPUManager)
+		}
+		if options.MemoryManager != nil {
+			options.TopologyManager.AddHintProvider(options.MemoryManager)
+		}
+	}
+	return &containerManagerStub{
+		shouldResetExtendedResourceCapacity: false,
+		extendedPluginResources:             options.ExtendedPluginResources,
+		cpuManager:                          options.CPUManager,
+		memoryManager:                       options.MemoryManager,
+		topologyManager:                     options.TopologyManager,
+		hugePagesCapacity:                   options.HugePagesCapacity,
+		capacity:                            options.Capacity,
+		nodeAllocatableReservation:          options.NodeAllocatableReservation,
+		nodeConfig:                          options.NodeConfig,
+		cgroupOpError:                       options.CgroupOpError,
+		cgroupVersion:                       options.CgroupVersion,
+	}
+}
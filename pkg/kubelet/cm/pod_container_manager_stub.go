@@ -17,28 +17,86 @@ limitations under the License.
 package cm
 
 import (
+	"strings"
+	"sync"
+
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 )
 
+// podContainerManagerStub tracks simulated per-pod cgroups without touching
+// the host cgroup hierarchy. Cgroups are created by EnsureExists and removed
+// by Destroy, so Exists and GetAllPodsFromCgroups stay consistent with the
+// hollow kubelet's pod set and the kubelet's cgroup-reconciliation paths
+// (orphaned cgroup cleanup, resource reclamation checks) operate on real
+// state.
 type podContainerManagerStub struct {
+	lock sync.Mutex
+	// podCgroups maps the UID of each pod with a simulated cgroup to its
+	// cgroup name. Created lazily so the zero value is usable.
+	podCgroups map[types.UID]CgroupName
+	// cgroupOpError, when set, is consulted before each mutating operation
+	// and may return an injected failure.
+	cgroupOpError func(operation string) error
 }
 
 var _ PodContainerManager = &podContainerManagerStub{}
 
-func (m *podContainerManagerStub) Exists(_ *v1.Pod) bool {
-	return true
+func (m *podContainerManagerStub) Exists(pod *v1.Pod) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, exists := m.podCgroups[pod.UID]
+	return exists
 }
 
-func (m *podContainerManagerStub) EnsureExists(_ *v1.Pod) error {
+func (m *podContainerManagerStub) EnsureExists(pod *v1.Pod) error {
+	if m.cgroupOpError != nil {
+		if err := m.cgroupOpError("create pod cgroup"); err != nil {
+			return err
+		}
+	}
+	podContainerName, _ := m.GetPodContainerName(pod)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.podCgroups == nil {
+		m.podCgroups = make(map[types.UID]CgroupName)
+	}
+	m.podCgroups[pod.UID] = podContainerName
 	return nil
 }
 
-func (m *podContainerManagerStub) GetPodContainerName(_ *v1.Pod) (CgroupName, string) {
-	return nil, ""
+func (m *podContainerManagerStub) GetPodContainerName(pod *v1.Pod) (CgroupName, string) {
+	// Parents mirror the QOSContainersInfo reported by containerManagerStub.
+	var parentContainer CgroupName
+	switch v1qos.GetPodQOS(pod) {
+	case v1.PodQOSGuaranteed:
+		parentContainer = RootCgroupName
+	case v1.PodQOSBurstable:
+		parentContainer = NewCgroupName(RootCgroupName, "kubepods", strings.ToLower(string(v1.PodQOSBurstable)))
+	case v1.PodQOSBestEffort:
+		parentContainer = NewCgroupName(RootCgroupName, "kubepods", strings.ToLower(string(v1.PodQOSBestEffort)))
+	}
+	cgroupName := NewCgroupName(parentContainer, GetPodCgroupNameSuffix(pod.UID))
+	return cgroupName, cgroupName.ToCgroupfs()
 }
 
-func (m *podContainerManagerStub) Destroy(_ CgroupName) error {
+func (m *podContainerManagerStub) Destroy(name CgroupName) error {
+	if m.cgroupOpError != nil {
+		if err := m.cgroupOpError("destroy pod cgroup"); err != nil {
+			return err
+		}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for uid, podCgroup := range m.podCgroups {
+		if podCgroup.ToCgroupfs() == name.ToCgroupfs() {
+			klog.V(4).Infof("Destroyed simulated pod cgroup %v", name.ToCgroupfs())
+			delete(m.podCgroups, uid)
+			return nil
+		}
+	}
 	return nil
 }
 
@@ -47,9 +105,22 @@ func (m *podContainerManagerStub) ReduceCPULimits(_ CgroupName) error {
 }
 
 func (m *podContainerManagerStub) GetAllPodsFromCgroups() (map[types.UID]CgroupName, error) {
-	return nil, nil
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	pods := make(map[types.UID]CgroupName, len(m.podCgroups))
+	for uid, name := range m.podCgroups {
+		pods[uid] = name
+	}
+	return pods, nil
 }
 
 func (m *podContainerManagerStub) IsPodCgroup(cgroupfs string) (bool, types.UID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for uid, name := range m.podCgroups {
+		if name.ToCgroupfs() == cgroupfs {
+			return true, uid
+		}
+	}
 	return false, types.UID("")
 }
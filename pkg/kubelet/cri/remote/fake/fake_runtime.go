@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	kubeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
 	apitest "k8s.io/cri-api/pkg/apis/testing"
 	"k8s.io/kubernetes/pkg/kubelet/cri/remote/util"
@@ -35,6 +37,8 @@ type RemoteRuntime struct {
 	RuntimeService *apitest.FakeRuntimeService
 	// Fake image service.
 	ImageService *apitest.FakeImageService
+	// Health service so that socket liveness can be monitored externally.
+	Health *health.Server
 }
 
 // NewFakeRemoteRuntime creates a new RemoteRuntime.
@@ -46,9 +50,11 @@ func NewFakeRemoteRuntime() *RemoteRuntime {
 		server:         grpc.NewServer(),
 		RuntimeService: fakeRuntimeService,
 		ImageService:   fakeImageService,
+		Health:         health.NewServer(),
 	}
 	kubeapi.RegisterRuntimeServiceServer(f.server, f)
 	kubeapi.RegisterImageServiceServer(f.server, f)
+	healthpb.RegisterHealthServer(f.server, f.Health)
 
 	return f
 }
@@ -69,12 +75,14 @@ func (f *RemoteRuntime) Start(endpoint string) error {
 			{Type: kubeapi.NetworkReady, Status: true},
 		},
 	}
+	f.Health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
 
 	return nil
 }
 
 // Stop stops the fake remote runtime.
 func (f *RemoteRuntime) Stop() {
+	f.Health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
 	f.server.Stop()
 }
 
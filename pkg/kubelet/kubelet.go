@@ -1966,6 +1966,13 @@ func (kl *Kubelet) canAdmitPod(pods []*v1.Pod, pod *v1.Pod) (bool, string, strin
 	return true, "", ""
 }
 
+// AddPodAdmitHandler appends an additional pod admit handler, so embedders
+// (such as kubemark) can install simulation-specific admission policies via
+// Dependencies.Options.
+func (kl *Kubelet) AddPodAdmitHandler(handler lifecycle.PodAdmitHandler) {
+	kl.admitHandlers.AddPodAdmitHandler(handler)
+}
+
 func (kl *Kubelet) canRunPod(pod *v1.Pod) lifecycle.PodAdmitResult {
 	attrs := &lifecycle.PodAdmitAttributes{Pod: pod}
 	// Get "OtherPods". Rejected pods are failed, so only include admitted pods that are alive.
@@ -290,9 +290,9 @@ func (kl *Kubelet) initialNode(ctx context.Context) (*v1.Node, error) {
 			Labels: map[string]string{
 				v1.LabelHostname:      kl.hostname,
 				v1.LabelOSStable:      goruntime.GOOS,
-				v1.LabelArchStable:    goruntime.GOARCH,
+				v1.LabelArchStable:    nodestatus.NodeArchitecture,
 				kubeletapis.LabelOS:   goruntime.GOOS,
-				kubeletapis.LabelArch: goruntime.GOARCH,
+				kubeletapis.LabelArch: nodestatus.NodeArchitecture,
 			},
 		},
 		Spec: v1.NodeSpec{
@@ -510,11 +510,11 @@ func (kl *Kubelet) tryUpdateNodeStatus(tryNumber int) error {
 	}
 	// Set the arch if there is a mismatch
 	arch, archLabelExists := node.Labels[v1.LabelArchStable]
-	if !archLabelExists || arch != goruntime.GOARCH {
+	if !archLabelExists || arch != nodestatus.NodeArchitecture {
 		if len(node.Labels) == 0 {
 			node.Labels = make(map[string]string)
 		}
-		node.Labels[v1.LabelArchStable] = goruntime.GOARCH
+		node.Labels[v1.LabelArchStable] = nodestatus.NodeArchitecture
 		areRequiredLabelsNotPresent = true
 	}
 
@@ -436,11 +436,17 @@ func Images(nodeStatusMaxImages int32,
 	}
 }
 
+// NodeArchitecture is the CPU architecture reported in the node's nodeInfo
+// and reconciled into its kubernetes.io/arch label. It defaults to the build
+// architecture; kubemark overrides it so a simulated fleet can mix
+// architectures regardless of the hosts the hollow nodes run on.
+var NodeArchitecture = goruntime.GOARCH
+
 // GoRuntime returns a Setter that sets GOOS and GOARCH on the node.
 func GoRuntime() Setter {
 	return func(node *v1.Node) error {
 		node.Status.NodeInfo.OperatingSystem = goruntime.GOOS
-		node.Status.NodeInfo.Architecture = goruntime.GOARCH
+		node.Status.NodeInfo.Architecture = NodeArchitecture
 		return nil
 	}
 }
@@ -19,6 +19,7 @@ package stats
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -48,14 +49,24 @@ type summaryProviderImpl struct {
 
 var _ SummaryProvider = &summaryProviderImpl{}
 
+// BootTimeOverride, when non-zero, is reported as the node's boot time
+// instead of the host's. Kubemark sets it so every hollow node reports its
+// own plausible uptime rather than the boot time of the host all of them
+// share.
+var BootTimeOverride time.Time
+
 // NewSummaryProvider returns a SummaryProvider using the stats provided by the
 // specified statsProvider.
 func NewSummaryProvider(statsProvider Provider) SummaryProvider {
 	kubeletCreationTime := metav1.Now()
-	bootTime, err := util.GetBootTime()
-	if err != nil {
-		// bootTime will be zero if we encounter an error getting the boot time.
-		klog.InfoS("Error getting system boot time. Node metrics will have an incorrect start time", "err", err)
+	bootTime := BootTimeOverride
+	if bootTime.IsZero() {
+		var err error
+		bootTime, err = util.GetBootTime()
+		if err != nil {
+			// bootTime will be zero if we encounter an error getting the boot time.
+			klog.InfoS("Error getting system boot time. Node metrics will have an incorrect start time", "err", err)
+		}
 	}
 
 	return &summaryProviderImpl{
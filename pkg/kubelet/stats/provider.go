@@ -101,9 +101,14 @@ type rlimitStatsProvider interface {
 	RlimitStats() (*statsapi.RlimitStats, error)
 }
 
+// RlimitStatsFn sources the node's process count and limit. It defaults to
+// reading the host's values and is overridden by kubemark to report
+// simulated PID usage instead.
+var RlimitStatsFn = pidlimit.Stats
+
 // RlimitStats returns base information about process count
 func (p *Provider) RlimitStats() (*statsapi.RlimitStats, error) {
-	return pidlimit.Stats()
+	return RlimitStatsFn()
 }
 
 // GetCgroupStats returns the stats of the cgroup with the cgroupName. Note that
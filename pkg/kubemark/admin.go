@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// adminMux collects handlers registered by simulator components, so feature
+// packages can contribute endpoints from their own files. http.ServeMux is
+// safe for registration after the server has started serving.
+var adminMux = struct {
+	sync.Mutex
+	mux *http.ServeMux
+}{mux: http.NewServeMux()}
+
+// RegisterAdminHandler adds a handler to the hollow node's admin API.
+func RegisterAdminHandler(pattern string, handler http.Handler) {
+	adminMux.Lock()
+	defer adminMux.Unlock()
+	adminMux.mux.Handle(pattern, handler)
+}
+
+// StartAdminServer serves the admin API for quick experiment readouts and
+// runtime mutations on the given port. It returns immediately.
+func StartAdminServer(port int) {
+	adminMux.Lock()
+	mux := adminMux.mux
+	adminMux.Unlock()
+
+	go func() {
+		addr := fmt.Sprintf(":%d", port)
+		klog.Infof("Serving kubemark admin API on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			klog.Errorf("Kubemark admin API server failed: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// AffinityValidationHandler re-validates node affinity and pod
+// (anti-)affinity at hollow kubelet admission and reports mismatches as
+// events on the pod. It never rejects: scheduling remains authoritative, the
+// handler only makes scheduler regressions visible during kubemark runs.
+type AffinityValidationHandler struct {
+	Client   clientset.Interface
+	NodeName string
+	recorder record.EventRecorder
+}
+
+var _ lifecycle.PodAdmitHandler = &AffinityValidationHandler{}
+
+// NewAffinityValidationHandler creates the validation handler and its event
+// recorder.
+func NewAffinityValidationHandler(client clientset.Interface, nodeName string) *AffinityValidationHandler {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "hollow-kubelet", Host: nodeName})
+	return &AffinityValidationHandler{
+		Client:   client,
+		NodeName: nodeName,
+		recorder: recorder,
+	}
+}
+
+// Admit implements lifecycle.PodAdmitHandler.
+func (h *AffinityValidationHandler) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	pod := attrs.Pod
+	node, err := h.Client.CoreV1().Nodes().Get(context.TODO(), h.NodeName, metav1.GetOptions{})
+	if err != nil {
+		klog.V(3).Infof("Skipping affinity validation for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+
+	if mismatch := h.validateNodeAffinity(pod, node); mismatch != "" {
+		h.report(pod, mismatch)
+	}
+	if mismatch := h.validatePodAntiAffinity(pod, attrs.OtherPods); mismatch != "" {
+		h.report(pod, mismatch)
+	}
+	return lifecycle.PodAdmitResult{Admit: true}
+}
+
+func (h *AffinityValidationHandler) report(pod *v1.Pod, message string) {
+	klog.V(2).Infof("Affinity mismatch for pod %s/%s on node %s: %s", pod.Namespace, pod.Name, h.NodeName, message)
+	h.recorder.Eventf(pod, v1.EventTypeWarning, "AffinityMismatch", "%s", message)
+}
+
+// validateNodeAffinity checks nodeSelector and required node affinity
+// against the node's labels.
+func (h *AffinityValidationHandler) validateNodeAffinity(pod *v1.Pod, node *v1.Node) string {
+	selector := nodeaffinity.GetRequiredNodeAffinity(pod)
+	match, err := selector.Match(node)
+	if err != nil {
+		return fmt.Sprintf("cannot evaluate node affinity: %v", err)
+	}
+	if !match {
+		return fmt.Sprintf("pod was scheduled to node %s but its nodeSelector/required node affinity does not match the node", h.NodeName)
+	}
+	return ""
+}
+
+// validatePodAntiAffinity checks required pod anti-affinity terms with the
+// hostname topology key against the pods already on this node.
+func (h *AffinityValidationHandler) validatePodAntiAffinity(pod *v1.Pod, otherPods []*v1.Pod) string {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return ""
+	}
+	for _, term := range pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey != v1.LabelHostname {
+			// Other topology keys need a cluster-wide view; node-level
+			// validation only covers co-location on this node.
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			return fmt.Sprintf("cannot evaluate pod anti-affinity: %v", err)
+		}
+		for _, other := range otherPods {
+			if other.Namespace != pod.Namespace && len(term.Namespaces) == 0 {
+				continue
+			}
+			if selector.Matches(labels.Set(other.Labels)) {
+				return fmt.Sprintf("pod has required anti-affinity against pod %s/%s already running on node %s", other.Namespace, other.Name, h.NodeName)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"net/http"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	flowcontrolapi "k8s.io/api/flowcontrol/v1beta2"
+)
+
+var apfRequestsTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "apf_requests_total",
+		Help:           "Number of apiserver responses observed by this hollow component, partitioned by the priority-and-fairness FlowSchema and PriorityLevelConfiguration UIDs the apiserver attributed them to.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"method", "flow_schema_uid", "priority_level_uid"},
+)
+
+func init() {
+	legacyregistry.MustRegister(apfRequestsTotal)
+}
+
+// apfAttributionTransport records the priority-and-fairness classification
+// the apiserver reports in its response headers, so operators can verify
+// hollow-node traffic lands in the intended FlowSchemas during scale tests.
+type apfAttributionTransport struct {
+	delegate http.RoundTripper
+}
+
+func (t *apfAttributionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.delegate.RoundTrip(req)
+	if resp != nil {
+		flowSchemaUID := resp.Header.Get(flowcontrolapi.ResponseHeaderMatchedFlowSchemaUID)
+		priorityLevelUID := resp.Header.Get(flowcontrolapi.ResponseHeaderMatchedPriorityLevelConfigurationUID)
+		if flowSchemaUID != "" || priorityLevelUID != "" {
+			apfRequestsTotal.WithLabelValues(req.Method, flowSchemaUID, priorityLevelUID).Inc()
+			klog.V(4).InfoS("Observed APF attribution",
+				"method", req.Method, "url", req.URL.Path,
+				"flowSchemaUID", flowSchemaUID, "priorityLevelUID", priorityLevelUID)
+		}
+	}
+	return resp, err
+}
+
+// NewAPFAttributionWrapper returns a RoundTripper wrapper that surfaces the
+// apiserver's flow-control attribution of each request as metrics and logs.
+func NewAPFAttributionWrapper() func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &apfAttributionTransport{delegate: rt}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// auditRecord is one line of the API audit log: everything needed to derive
+// a traffic model (what was called, how long it took, how big it was).
+type auditRecord struct {
+	Time   string `json:"time"`
+	Method string `json:"method"`
+	// VerbGroup buckets the request as read, write or watch.
+	VerbGroup string `json:"verbGroup"`
+	Path      string `json:"path"`
+	Status    int    `json:"status,omitempty"`
+	// Error is set instead of Status when the request failed locally.
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+	// RequestBytes and ResponseBytes are the declared content lengths; -1
+	// means the length was not known (e.g. chunked watch responses).
+	RequestBytes  int64 `json:"requestBytes"`
+	ResponseBytes int64 `json:"responseBytes"`
+}
+
+// APIAuditLogger appends one JSON line per API request to a local file, so
+// traffic models for capacity planning can be derived from the requests an
+// actual simulated node makes rather than guesswork.
+type APIAuditLogger struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	file    *os.File
+}
+
+// NewAPIAuditLogger opens (appending) the audit log at the given path.
+func NewAPIAuditLogger(path string) (*APIAuditLogger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening API audit log %v: %w", path, err)
+	}
+	return &APIAuditLogger{
+		encoder: json.NewEncoder(file),
+		file:    file,
+	}, nil
+}
+
+func (l *APIAuditLogger) write(record auditRecord) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err := l.encoder.Encode(record); err != nil {
+		klog.Warningf("Failed to write API audit record: %v", err)
+	}
+}
+
+// apiAuditTransport records every request passing through it to the audit
+// log.
+type apiAuditTransport struct {
+	logger   *APIAuditLogger
+	delegate http.RoundTripper
+}
+
+func (t *apiAuditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.delegate.RoundTrip(req)
+	record := auditRecord{
+		Time:          start.UTC().Format(time.RFC3339Nano),
+		Method:        req.Method,
+		VerbGroup:     verbGroup(req),
+		Path:          req.URL.Path,
+		LatencyMs:     float64(time.Since(start)) / float64(time.Millisecond),
+		RequestBytes:  req.ContentLength,
+		ResponseBytes: -1,
+	}
+	if resp != nil {
+		record.Status = resp.StatusCode
+		record.ResponseBytes = resp.ContentLength
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	t.logger.write(record)
+	return resp, err
+}
+
+// NewAPIAuditWrapper returns a RoundTripper wrapper logging every API
+// request to the given audit logger.
+func NewAPIAuditWrapper(logger *APIAuditLogger) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &apiAuditTransport{logger: logger, delegate: rt}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package benchmark provides adapters for running scheduler_perf-style test
+// cases against a kubemark fleet, measuring not just scheduling decisions but
+// the end-to-end bind-to-running latency including the simulated kubelet ack
+// path.
+package benchmark
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// BindingLatencySummary aggregates observed bind-to-running latencies.
+type BindingLatencySummary struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// podTimes tracks when a pod was first observed bound to a node and when it
+// was first observed running.
+type podTimes struct {
+	bound   time.Time
+	running time.Time
+}
+
+// BindingLatencyMeasurer watches pods in a namespace and measures, for each
+// pod, the time from the pod first being observed bound (spec.nodeName set)
+// to the hollow kubelet reporting it Running. It can target an in-process
+// fleet or an external cluster; only a client is needed.
+type BindingLatencyMeasurer struct {
+	client    clientset.Interface
+	namespace string
+
+	lock sync.Mutex
+	pods map[string]*podTimes
+}
+
+// NewBindingLatencyMeasurer creates a measurer for pods in the given
+// namespace. An empty namespace measures all pods.
+func NewBindingLatencyMeasurer(client clientset.Interface, namespace string) *BindingLatencyMeasurer {
+	return &BindingLatencyMeasurer{
+		client:    client,
+		namespace: namespace,
+		pods:      make(map[string]*podTimes),
+	}
+}
+
+// Run starts watching pods until stopCh is closed. It returns once the
+// informer cache has synced so measurements cover all subsequent bindings.
+func (m *BindingLatencyMeasurer) Run(stopCh <-chan struct{}) error {
+	lw := cache.NewListWatchFromClient(m.client.CoreV1().RESTClient(), "pods", m.namespace, fields.Everything())
+	_, informer := cache.NewInformer(lw, &v1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { m.observe(obj) },
+		UpdateFunc: func(_, obj interface{}) { m.observe(obj) },
+	})
+	go informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return wait.ErrWaitTimeout
+	}
+	return nil
+}
+
+func (m *BindingLatencyMeasurer) observe(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return
+	}
+	now := time.Now()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := pod.Namespace + "/" + pod.Name
+	times := m.pods[key]
+	if times == nil {
+		times = &podTimes{}
+		m.pods[key] = times
+	}
+	if pod.Spec.NodeName != "" && times.bound.IsZero() {
+		times.bound = now
+	}
+	if pod.Status.Phase == v1.PodRunning && times.running.IsZero() {
+		times.running = now
+	}
+}
+
+// Latencies returns bind-to-running durations for all pods that completed
+// the transition so far.
+func (m *BindingLatencyMeasurer) Latencies() []time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	latencies := make([]time.Duration, 0, len(m.pods))
+	for _, times := range m.pods {
+		if !times.bound.IsZero() && !times.running.IsZero() {
+			latencies = append(latencies, times.running.Sub(times.bound))
+		}
+	}
+	return latencies
+}
+
+// Summarize computes percentiles over the latencies observed so far.
+func (m *BindingLatencyMeasurer) Summarize() BindingLatencySummary {
+	latencies := m.Latencies()
+	summary := BindingLatencySummary{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return summary
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		idx := int(p * float64(len(latencies)-1))
+		return latencies[idx]
+	}
+	summary.P50 = percentile(0.50)
+	summary.P90 = percentile(0.90)
+	summary.P99 = percentile(0.99)
+	summary.Max = latencies[len(latencies)-1]
+	return summary
+}
+
+// WaitForCompleted blocks until at least want pods have completed the
+// bind-to-running transition or the timeout elapses, returning the number of
+// completed pods.
+func (m *BindingLatencyMeasurer) WaitForCompleted(want int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for {
+		completed := len(m.Latencies())
+		if completed >= want || time.Now().After(deadline) {
+			return completed
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// ListNodes is a convenience for scheduler_perf-style cases that need the
+// current hollow node inventory before generating load.
+func (m *BindingLatencyMeasurer) ListNodes() (*v1.NodeList, error) {
+	return m.client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+}
@@ -28,6 +28,51 @@ type Fake struct {
 	NodeName   string
 	NodeCpu    int
 	NodeMemory int
+	// Pressure, when set, makes the root container report time-varying
+	// simulated system memory usage in stats.
+	Pressure *SystemPressure
+	// Topology, when set, is reported as the machine's NUMA/CPU topology
+	// and overrides NodeCpu as the core count, so CPU managers can run
+	// against a synthetic machine.
+	Topology []cadvisorapi.Node
+	// HugePages, when set, is reported as the machine's pre-allocated huge
+	// pages and becomes schedulable hugepages-* node capacity.
+	HugePages []cadvisorapi.HugePagesInfo
+	// Network, when set, makes the root container report synthetic network
+	// interface counters growing at the configured rates, which the summary
+	// API surfaces as node-level network stats.
+	Network *NetworkTraffic
+	// EphemeralStorage, when set, is served as the node's root filesystem,
+	// its usage tracking the simulated writable layers of running
+	// containers.
+	EphemeralStorage *EphemeralStorageSimulator
+}
+
+// SyntheticCPUTopology fabricates a cadvisor CPU topology with the given
+// numbers of sockets, cores per socket and threads per core. Each socket is
+// modelled as its own NUMA node and CPU IDs are assigned sequentially.
+func SyntheticCPUTopology(sockets, coresPerSocket, threadsPerCore int) []cadvisorapi.Node {
+	nodes := make([]cadvisorapi.Node, 0, sockets)
+	cpuID := 0
+	coreID := 0
+	for socket := 0; socket < sockets; socket++ {
+		node := cadvisorapi.Node{Id: socket}
+		for core := 0; core < coresPerSocket; core++ {
+			threads := make([]int, 0, threadsPerCore)
+			for thread := 0; thread < threadsPerCore; thread++ {
+				threads = append(threads, cpuID)
+				cpuID++
+			}
+			node.Cores = append(node.Cores, cadvisorapi.Core{
+				Id:       coreID,
+				Threads:  threads,
+				SocketID: socket,
+			})
+			coreID++
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
 }
 
 const (
@@ -55,6 +100,30 @@ func (c *Fake) ContainerInfo(name string, req *cadvisorapi.ContainerInfoRequest)
 
 // ContainerInfoV2 is a fake implementation of Interface.ContainerInfoV2.
 func (c *Fake) ContainerInfoV2(name string, options cadvisorapiv2.RequestOptions) (map[string]cadvisorapiv2.ContainerInfo, error) {
+	if name == "/" && (c.Pressure != nil || c.Network != nil) {
+		now := simClock.Now()
+		stats := &cadvisorapiv2.ContainerStats{Timestamp: now}
+		spec := cadvisorapiv2.ContainerSpec{}
+		if c.Pressure != nil {
+			spec.HasMemory = true
+			stats.Memory = &cadvisorapi.MemoryStats{
+				Usage:      c.Pressure.MemoryUsage(now),
+				WorkingSet: c.Pressure.MemoryUsage(now),
+			}
+		}
+		if c.Network != nil {
+			spec.HasNetwork = true
+			stats.Network = &cadvisorapiv2.NetworkStats{
+				Interfaces: c.Network.NodeNetworkStats(now).Interfaces,
+			}
+		}
+		return map[string]cadvisorapiv2.ContainerInfo{
+			"/": {
+				Spec:  spec,
+				Stats: []*cadvisorapiv2.ContainerStats{stats},
+			},
+		}, nil
+	}
 	return map[string]cadvisorapiv2.ContainerInfo{}, nil
 }
 
@@ -77,11 +146,41 @@ func (c *Fake) DockerContainer(name string, req *cadvisorapi.ContainerInfoReques
 func (c *Fake) MachineInfo() (*cadvisorapi.MachineInfo, error) {
 	// Simulate a machine with 1 core and 3.75GB of memory.
 	// We set it to non-zero values to make non-zero-capacity machines in Kubemark.
-	return &cadvisorapi.MachineInfo{
+	info := &cadvisorapi.MachineInfo{
 		NumCores:       c.NodeCpu,
 		InstanceID:     cadvisorapi.InstanceID(c.NodeName),
 		MemoryCapacity: uint64(c.NodeMemory * 1024 * 1024 * 1024),
-	}, nil
+	}
+	info.HugePages = c.HugePages
+	if len(c.Topology) > 0 {
+		info.Topology = c.Topology
+		info.NumSockets = len(c.Topology)
+		numCores := 0
+		for i := range info.Topology {
+			// Spread memory and huge pages evenly over the NUMA nodes so
+			// NUMA-aware managers (e.g. the static memory manager) can
+			// account against the synthetic topology.
+			info.Topology[i].Memory = info.MemoryCapacity / uint64(len(c.Topology))
+			info.Topology[i].HugePages = perNodeHugePages(c.HugePages, len(c.Topology))
+			for _, core := range info.Topology[i].Cores {
+				numCores += len(core.Threads)
+			}
+		}
+		info.NumCores = numCores
+	}
+	return info, nil
+}
+
+// perNodeHugePages splits the machine's huge pages evenly over numaNodes.
+func perNodeHugePages(hugePages []cadvisorapi.HugePagesInfo, numaNodes int) []cadvisorapi.HugePagesInfo {
+	perNode := make([]cadvisorapi.HugePagesInfo, 0, len(hugePages))
+	for _, pages := range hugePages {
+		perNode = append(perNode, cadvisorapi.HugePagesInfo{
+			PageSize: pages.PageSize,
+			NumPages: pages.NumPages / uint64(numaNodes),
+		})
+	}
+	return perNode
 }
 
 // VersionInfo is a fake implementation of Interface.VersionInfo.
@@ -95,11 +194,18 @@ func (c *Fake) VersionInfo() (*cadvisorapi.VersionInfo, error) {
 
 // ImagesFsInfo is a fake implementation of Interface.ImagesFsInfo.
 func (c *Fake) ImagesFsInfo() (cadvisorapiv2.FsInfo, error) {
+	if c.EphemeralStorage != nil {
+		// Images share the root filesystem, as on a typical single-disk node.
+		return c.EphemeralStorage.FsInfo(), nil
+	}
 	return cadvisorapiv2.FsInfo{}, nil
 }
 
 // RootFsInfo is a fake implementation of Interface.RootFsInfo.
 func (c *Fake) RootFsInfo() (cadvisorapiv2.FsInfo, error) {
+	if c.EphemeralStorage != nil {
+		return c.EphemeralStorage.FsInfo(), nil
+	}
 	return cadvisorapiv2.FsInfo{}, nil
 }
 
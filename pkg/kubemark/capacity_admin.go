@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+// DevicePluginCapacitySetter mutates the simulated device plugin capacity of
+// a running hollow node; the stub container manager implements it.
+type DevicePluginCapacitySetter interface {
+	SetDevicePluginResourceCapacity(v1.ResourceList)
+}
+
+// capacityMutationRequest is the admin API payload for changing simulated
+// capacity, e.g. {"resources": {"nvidia.com/gpu": "4"}}.
+type capacityMutationRequest struct {
+	Resources map[string]string `json:"resources"`
+}
+
+// RegisterCapacityMutationHandler exposes POST /capacity on the admin API,
+// letting operators change the node's device plugin capacity at runtime so
+// capacity-change reconciliation in the scheduler and autoscaler can be
+// tested live.
+func RegisterCapacityMutationHandler(setter DevicePluginCapacitySetter) {
+	RegisterAdminHandler("/capacity", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		var mutation capacityMutationRequest
+		if err := json.NewDecoder(req.Body).Decode(&mutation); err != nil {
+			http.Error(w, fmt.Sprintf("cannot decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+		resources := v1.ResourceList{}
+		for name, value := range mutation.Resources {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid quantity %q for resource %q: %v", value, name, err), http.StatusBadRequest)
+				return
+			}
+			resources[v1.ResourceName(name)] = quantity
+		}
+		klog.Infof("Admin API changed simulated device plugin capacity to %v", resources)
+		setter.SetDevicePluginResourceCapacity(resources)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
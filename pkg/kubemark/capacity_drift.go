@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+var driftedCapacity = metrics.NewGaugeVec(
+	&metrics.GaugeOpts{
+		Subsystem:      "kubemark",
+		Name:           "drifted_capacity",
+		Help:           "Current simulated capacity of resources under drift, per resource, in the resource's base unit.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"resource"},
+)
+
+func init() {
+	legacyregistry.MustRegister(driftedCapacity)
+}
+
+// CapacityDriftRule describes how one resource's capacity changes over
+// time. Exactly one of shrinkPerHour (linear drift) or flickerPeriod
+// (periodic disappearance) must be set.
+type CapacityDriftRule struct {
+	// Resource is the capacity entry to drift, e.g. "ephemeral-storage" or
+	// "nvidia.com/gpu". Device plugin resources are drifted through the
+	// simulated device plugin capacity, everything else through the machine
+	// capacity.
+	Resource string `json:"resource"`
+	// Initial overrides the starting capacity; by default the value the
+	// node currently reports is used.
+	Initial string `json:"initial,omitempty"`
+	// ShrinkPerHour shrinks the capacity linearly by this quantity per
+	// hour, e.g. a leaking disk losing "1Gi" of ephemeral-storage hourly.
+	ShrinkPerHour string `json:"shrinkPerHour,omitempty"`
+	// Floor is the value linear drift stops at; default zero.
+	Floor string `json:"floor,omitempty"`
+	// FlickerPeriod makes the capacity drop to zero once per period, e.g.
+	// GPUs intermittently disappearing.
+	FlickerPeriod metav1.Duration `json:"flickerPeriod,omitempty"`
+	// FlickerDowntime is how long the capacity stays at zero each period;
+	// default a tenth of the period.
+	FlickerDowntime metav1.Duration `json:"flickerDowntime,omitempty"`
+}
+
+// CapacityDriftProfile is a YAML description of time-varying node capacity,
+// so controllers reacting to capacity changes (Cluster Autoscaler,
+// descheduler) can be tested against a drifting fleet.
+//
+// Example:
+//
+//	rules:
+//	- resource: ephemeral-storage
+//	  initial: 100Gi
+//	  shrinkPerHour: 1Gi
+//	  floor: 10Gi
+//	- resource: nvidia.com/gpu
+//	  flickerPeriod: 30m
+//	  flickerDowntime: 5m
+type CapacityDriftProfile struct {
+	Rules []CapacityDriftRule `json:"rules"`
+}
+
+// LoadCapacityDriftProfile reads and validates a capacity drift profile
+// from a YAML file.
+func LoadCapacityDriftProfile(path string) (*CapacityDriftProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading capacity drift profile from %v: %w", path, err)
+	}
+	profile := &CapacityDriftProfile{}
+	if err := yaml.UnmarshalStrict(data, profile); err != nil {
+		return nil, fmt.Errorf("error while parsing capacity drift profile %v: %w", path, err)
+	}
+	for i, rule := range profile.Rules {
+		if rule.Resource == "" {
+			return nil, fmt.Errorf("invalid capacity drift profile %v: rule %d has no resource", path, i)
+		}
+		linear := rule.ShrinkPerHour != ""
+		flicker := rule.FlickerPeriod.Duration > 0
+		if linear == flicker {
+			return nil, fmt.Errorf("invalid capacity drift profile %v: rule for %s must set exactly one of shrinkPerHour and flickerPeriod", path, rule.Resource)
+		}
+		if rule.FlickerDowntime.Duration >= rule.FlickerPeriod.Duration && flicker {
+			return nil, fmt.Errorf("invalid capacity drift profile %v: rule for %s has flickerDowntime >= flickerPeriod", path, rule.Resource)
+		}
+		// Fail on malformed quantities at startup rather than on first use.
+		for _, value := range []string{rule.Initial, rule.ShrinkPerHour, rule.Floor} {
+			if value == "" {
+				continue
+			}
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return nil, fmt.Errorf("invalid capacity drift profile %v: rule for %s: %w", path, rule.Resource, err)
+			}
+		}
+	}
+	return profile, nil
+}
+
+// CapacityDriftTarget is the part of the stub container manager the drift
+// simulator drives: machine capacity for regular resources and simulated
+// device plugin capacity for extended resources.
+type CapacityDriftTarget interface {
+	GetCapacity() v1.ResourceList
+	SetCapacity(name v1.ResourceName, quantity resource.Quantity)
+	GetDevicePluginResourceCapacity() (v1.ResourceList, v1.ResourceList, []string)
+	SetDevicePluginResourceCapacity(v1.ResourceList)
+}
+
+// CapacityDriftSimulator applies a capacity drift profile to a hollow
+// node's reported capacity over time, so controllers that react to capacity
+// changes see nodes shrinking or losing devices as they would in a degrading
+// fleet.
+type CapacityDriftSimulator struct {
+	Profile *CapacityDriftProfile
+	Target  CapacityDriftTarget
+	// Start is when drift began; linear rules shrink relative to it.
+	Start time.Time
+
+	// initial holds each ruled resource's starting capacity, snapshotted on
+	// the first tick (or taken from the rule's initial override).
+	initial map[v1.ResourceName]resource.Quantity
+	// plugin marks ruled resources backed by the simulated device plugin.
+	plugin map[v1.ResourceName]bool
+}
+
+// NewCapacityDriftSimulator creates a simulator applying the given profile
+// to the target, starting now.
+func NewCapacityDriftSimulator(profile *CapacityDriftProfile, target CapacityDriftTarget) *CapacityDriftSimulator {
+	return &CapacityDriftSimulator{
+		Profile: profile,
+		Target:  target,
+		Start:   simClock.Now(),
+	}
+}
+
+// Run applies the profile every 30 seconds until stopCh is closed.
+func (s *CapacityDriftSimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.apply(simClock.Now())
+			}
+		}
+	}()
+}
+
+func (s *CapacityDriftSimulator) apply(now time.Time) {
+	if s.initial == nil {
+		s.snapshot()
+	}
+	pluginCapacity, _, _ := s.Target.GetDevicePluginResourceCapacity()
+	drifted := v1.ResourceList{}
+	for name, quantity := range pluginCapacity {
+		drifted[name] = quantity
+	}
+	pluginChanged := false
+	for _, rule := range s.Profile.Rules {
+		name := v1.ResourceName(rule.Resource)
+		current := s.currentValue(rule, now)
+		driftedCapacity.WithLabelValues(rule.Resource).Set(float64(current.Value()))
+		if s.plugin[name] {
+			if previous, ok := drifted[name]; !ok || previous.Value() != current.Value() {
+				klog.V(2).Infof("Drifting device plugin capacity of %s to %s", name, current.String())
+				pluginChanged = true
+			}
+			drifted[name] = current
+			continue
+		}
+		s.Target.SetCapacity(name, current)
+	}
+	if pluginChanged {
+		s.Target.SetDevicePluginResourceCapacity(drifted)
+	}
+}
+
+// snapshot records each ruled resource's starting capacity and whether it
+// is served by the simulated device plugin.
+func (s *CapacityDriftSimulator) snapshot() {
+	s.initial = map[v1.ResourceName]resource.Quantity{}
+	s.plugin = map[v1.ResourceName]bool{}
+	pluginCapacity, _, _ := s.Target.GetDevicePluginResourceCapacity()
+	machineCapacity := s.Target.GetCapacity()
+	for _, rule := range s.Profile.Rules {
+		name := v1.ResourceName(rule.Resource)
+		if _, ok := pluginCapacity[name]; ok {
+			s.plugin[name] = true
+		}
+		if rule.Initial != "" {
+			s.initial[name] = resource.MustParse(rule.Initial)
+			continue
+		}
+		if quantity, ok := pluginCapacity[name]; ok {
+			s.initial[name] = quantity
+		} else {
+			s.initial[name] = machineCapacity[name]
+		}
+	}
+}
+
+// currentValue computes one rule's capacity at the given time.
+func (s *CapacityDriftSimulator) currentValue(rule CapacityDriftRule, now time.Time) resource.Quantity {
+	name := v1.ResourceName(rule.Resource)
+	initial := s.initial[name]
+	elapsed := now.Sub(s.Start)
+	if rule.FlickerPeriod.Duration > 0 {
+		downtime := rule.FlickerDowntime.Duration
+		if downtime == 0 {
+			downtime = rule.FlickerPeriod.Duration / 10
+		}
+		if elapsed%rule.FlickerPeriod.Duration < downtime {
+			return *resource.NewQuantity(0, initial.Format)
+		}
+		return initial
+	}
+	shrinkPerHour := resource.MustParse(rule.ShrinkPerHour)
+	shrunk := int64(float64(shrinkPerHour.Value()) * elapsed.Hours())
+	current := initial.Value() - shrunk
+	floor := int64(0)
+	if rule.Floor != "" {
+		floorQuantity := resource.MustParse(rule.Floor)
+		floor = floorQuantity.Value()
+	}
+	if current < floor {
+		current = floor
+	}
+	return *resource.NewQuantity(current, initial.Format)
+}
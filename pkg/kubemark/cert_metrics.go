@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	kubeletmetrics "k8s.io/kubernetes/pkg/kubelet/metrics"
+)
+
+// CertificateExpirySimulator stands in for the kubelet's client and serving
+// certificate managers, which hollow nodes do not run because they
+// authenticate with a static kubeconfig. It exposes the same
+// kubelet_certificate_manager_* TTL and rotation metrics over synthetic
+// certificates, so alerting on certificate expiry can be validated against a
+// kubemark fleet. Certificates are "issued" at startup with the configured
+// lifetime and rotated when a fifth of the lifetime remains, mirroring the
+// rotation deadline jitter window of the real certificate manager.
+type CertificateExpirySimulator struct {
+	// Lifetime is the validity period of the synthetic certificates.
+	Lifetime time.Duration
+
+	lock           sync.Mutex
+	clientIssued   time.Time
+	clientNotAfter time.Time
+	serverIssued   time.Time
+	serverNotAfter time.Time
+	serverRotation metrics.ObserverMetric
+}
+
+// NewCertificateExpirySimulator issues the synthetic certificates and
+// registers their TTL and rotation metrics. It must be called at most once
+// per process.
+func NewCertificateExpirySimulator(lifetime time.Duration) *CertificateExpirySimulator {
+	now := simClock.Now()
+	s := &CertificateExpirySimulator{
+		Lifetime:       lifetime,
+		clientIssued:   now,
+		clientNotAfter: now.Add(lifetime),
+		serverIssued:   now,
+		serverNotAfter: now.Add(lifetime),
+	}
+
+	legacyregistry.RawMustRegister(metrics.NewGaugeFunc(
+		&metrics.GaugeOpts{
+			Subsystem: kubeletmetrics.KubeletSubsystem,
+			Name:      "certificate_manager_client_ttl_seconds",
+			Help: "Gauge of the TTL (time-to-live) of the Kubelet's client certificate. " +
+				"The value is in seconds until certificate expiry (negative if already expired). " +
+				"If client certificate is invalid or unused, the value will be +INF.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		func() float64 {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			return math.Trunc(time.Until(s.clientNotAfter).Seconds())
+		},
+	))
+	legacyregistry.RawMustRegister(metrics.NewGaugeFunc(
+		&metrics.GaugeOpts{
+			Subsystem: kubeletmetrics.KubeletSubsystem,
+			Name:      "certificate_manager_server_ttl_seconds",
+			Help: "Gauge of the shortest TTL (time-to-live) of " +
+				"the Kubelet's serving certificate. The value is in seconds " +
+				"until certificate expiry (negative if already expired). If " +
+				"serving certificate is invalid or unused, the value will " +
+				"be +INF.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		func() float64 {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			return math.Trunc(time.Until(s.serverNotAfter).Seconds())
+		},
+	))
+	serverRotation := metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Subsystem: kubeletmetrics.KubeletSubsystem,
+			Name:      "certificate_manager_server_rotation_seconds",
+			Help:      "Histogram of the number of seconds the previous certificate lived before being rotated.",
+			Buckets: []float64{
+				60,        // 1  minute
+				3600,      // 1  hour
+				14400,     // 4  hours
+				86400,     // 1  day
+				604800,    // 1  week
+				2592000,   // 1  month
+				7776000,   // 3  months
+				15552000,  // 6  months
+				31104000,  // 1  year
+				124416000, // 4  years
+			},
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	legacyregistry.MustRegister(serverRotation)
+	s.serverRotation = serverRotation
+	return s
+}
+
+// Run rotates the synthetic certificates as they approach expiry until
+// stopCh is closed.
+func (s *CertificateExpirySimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.rotateExpiring()
+			}
+		}
+	}()
+}
+
+func (s *CertificateExpirySimulator) rotateExpiring() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := simClock.Now()
+	deadline := s.Lifetime / 5
+	if time.Until(s.clientNotAfter) < deadline {
+		klog.V(2).Infof("Rotating simulated client certificate, previous lived %v", now.Sub(s.clientIssued))
+		s.clientIssued = now
+		s.clientNotAfter = now.Add(s.Lifetime)
+	}
+	if time.Until(s.serverNotAfter) < deadline {
+		klog.V(2).Infof("Rotating simulated serving certificate, previous lived %v", now.Sub(s.serverIssued))
+		s.serverRotation.Observe(now.Sub(s.serverIssued).Seconds())
+		s.serverIssued = now
+		s.serverNotAfter = now.Add(s.Lifetime)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"math/rand"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	"k8s.io/kubernetes/pkg/kubelet/events"
+)
+
+var cgroupOperationFailuresTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "cgroup_operation_failures_total",
+		Help:           "Number of simulated cgroup operation failures injected by the stub container manager, per operation.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"operation"},
+)
+
+func init() {
+	legacyregistry.MustRegister(cgroupOperationFailuresTotal)
+}
+
+// cgroupFailureMessages are the realistic error shapes injected failures
+// take, picked at random per failure.
+var cgroupFailureMessages = []string{
+	"failed to write to memory.limit_in_bytes: device or resource busy",
+	"failed to set supported cgroup subsystems: openat2 /sys/fs/cgroup/kubepods: no such file or directory",
+	"failed to apply oom score -998: write /proc/self/oom_score_adj: permission denied",
+}
+
+// CgroupFailureInjector makes a configurable fraction of the stub container
+// manager's cgroup operations fail with realistic errors, surfacing each
+// failure as a node event and a metric, so error-handling paths around
+// cgroup management get coverage in simulation.
+type CgroupFailureInjector struct {
+	NodeName string
+	// Rate is the fraction of cgroup operations that fail, in (0, 1].
+	Rate     float64
+	recorder record.EventRecorder
+}
+
+// NewCgroupFailureInjector creates an injector failing the given fraction
+// of cgroup operations. client may be nil (standalone mode), in which case
+// failures are only counted and returned, not recorded as events.
+func NewCgroupFailureInjector(client clientset.Interface, nodeName string, rate float64) *CgroupFailureInjector {
+	injector := &CgroupFailureInjector{
+		NodeName: nodeName,
+		Rate:     rate,
+	}
+	if client != nil {
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: client.CoreV1().Events("")})
+		injector.recorder = eventBroadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "hollow-kubelet", Host: nodeName})
+	}
+	return injector
+}
+
+// MaybeFail returns a synthetic error for the given cgroup operation with
+// the configured probability, and nil otherwise. It has the signature the
+// stub container manager's CgroupOpError hook expects.
+func (i *CgroupFailureInjector) MaybeFail(operation string) error {
+	if rand.Float64() >= i.Rate {
+		return nil
+	}
+	err := fmt.Errorf("%s: %s", operation, cgroupFailureMessages[rand.Intn(len(cgroupFailureMessages))])
+	cgroupOperationFailuresTotal.WithLabelValues(operation).Inc()
+	if i.recorder != nil {
+		nodeRef := &v1.ObjectReference{
+			Kind: "Node",
+			Name: i.NodeName,
+			UID:  types.UID(i.NodeName),
+		}
+		i.recorder.Eventf(nodeRef, v1.EventTypeWarning, events.FailedNodeAllocatableEnforcement, "Simulated cgroup failure: %v", err)
+	}
+	return err
+}
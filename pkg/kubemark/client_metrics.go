@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	clientRequestDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "kubemark",
+			Name:           "client_request_duration_seconds",
+			Help:           "Latency of apiserver requests made by this hollow node's clients, by Kubernetes verb and resource. Aggregated across the fleet this is a client-side apiserver health signal.",
+			Buckets:        []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"verb", "resource"},
+	)
+	clientRequestErrors = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "kubemark",
+			Name:           "client_request_errors_total",
+			Help:           "Failed apiserver requests from this hollow node's clients, by verb, resource and HTTP status code. Transport-level failures are counted under code \"<error>\".",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"verb", "resource", "code"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(clientRequestDuration)
+	legacyregistry.MustRegister(clientRequestErrors)
+}
+
+// requestVerb maps an outgoing request to the Kubernetes API verb it carries,
+// distinguishing get from list and watch on GETs by the request shape.
+func requestVerb(req *http.Request, hasName bool) string {
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("watch") == "true" {
+			return "watch"
+		}
+		if hasName {
+			return "get"
+		}
+		return "list"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		if hasName {
+			return "delete"
+		}
+		return "deletecollection"
+	}
+	return strings.ToLower(req.Method)
+}
+
+// parseRequestResource extracts the resource (with subresource, e.g.
+// "pods/status") from an apiserver request path and whether the path names a
+// single object. Non-resource paths (e.g. /healthz, /version) return the
+// first path segment.
+func parseRequestResource(path string) (resource string, hasName bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	// Strip the group/version prefix: /api/v1 or /apis/<group>/<version>.
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		if len(segments) > 0 && segments[0] != "" {
+			return segments[0], false
+		}
+		return "unknown", false
+	}
+	// Strip the namespace qualifier: namespaces/<ns>/<resource>/... A bare
+	// namespaces/<name> path addresses the Namespace object itself.
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		segments = segments[2:]
+	}
+	switch len(segments) {
+	case 0:
+		return "unknown", false
+	case 1:
+		return segments[0], false
+	case 2:
+		return segments[0], true
+	default:
+		return segments[0] + "/" + strings.Join(segments[2:], "/"), true
+	}
+}
+
+// clientMetricsTransport observes latency and errors for every request
+// passing through it.
+type clientMetricsTransport struct {
+	delegate http.RoundTripper
+}
+
+func (t *clientMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, hasName := parseRequestResource(req.URL.Path)
+	verb := requestVerb(req, hasName)
+	start := time.Now()
+	resp, err := t.delegate.RoundTrip(req)
+	clientRequestDuration.WithLabelValues(verb, resource).Observe(time.Since(start).Seconds())
+	switch {
+	case err != nil:
+		clientRequestErrors.WithLabelValues(verb, resource, "<error>").Inc()
+	case resp.StatusCode >= 400:
+		clientRequestErrors.WithLabelValues(verb, resource, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, err
+}
+
+// NewClientMetricsWrapper returns a RoundTripper wrapper exporting per-verb,
+// per-resource request latency and error counts for the wrapped client.
+func NewClientMetricsWrapper() func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &clientMetricsTransport{delegate: rt}
+	}
+}
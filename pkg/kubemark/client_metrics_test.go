@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParseRequestResource(t *testing.T) {
+	tests := []struct {
+		path     string
+		resource string
+		hasName  bool
+	}{
+		{"/api/v1/pods", "pods", false},
+		{"/api/v1/nodes/node-1", "nodes", true},
+		{"/api/v1/namespaces/default/pods", "pods", false},
+		{"/api/v1/namespaces/default/pods/web-0", "pods", true},
+		{"/api/v1/namespaces/default/pods/web-0/status", "pods/status", true},
+		{"/api/v1/namespaces/kube-system", "namespaces", true},
+		{"/apis/apps/v1/namespaces/default/deployments", "deployments", false},
+		{"/apis/apps/v1/namespaces/default/replicasets/web-abc/scale", "replicasets/scale", true},
+		{"/apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases/node-1", "leases", true},
+		{"/healthz", "healthz", false},
+		{"/version", "version", false},
+		{"/", "unknown", false},
+	}
+	for _, test := range tests {
+		resource, hasName := parseRequestResource(test.path)
+		if resource != test.resource || hasName != test.hasName {
+			t.Errorf("parseRequestResource(%q) = (%q, %v), want (%q, %v)",
+				test.path, resource, hasName, test.resource, test.hasName)
+		}
+	}
+}
+
+func TestRequestVerb(t *testing.T) {
+	tests := []struct {
+		method   string
+		rawQuery string
+		hasName  bool
+		want     string
+	}{
+		{http.MethodGet, "", true, "get"},
+		{http.MethodGet, "", false, "list"},
+		{http.MethodGet, "watch=true", false, "watch"},
+		{http.MethodPost, "", false, "create"},
+		{http.MethodPut, "", true, "update"},
+		{http.MethodPatch, "", true, "patch"},
+		{http.MethodDelete, "", true, "delete"},
+		{http.MethodDelete, "", false, "deletecollection"},
+		{http.MethodHead, "", false, "head"},
+	}
+	for _, test := range tests {
+		req := &http.Request{
+			Method: test.method,
+			URL:    &url.URL{Path: "/api/v1/pods", RawQuery: test.rawQuery},
+		}
+		if got := requestVerb(req, test.hasName); got != test.want {
+			t.Errorf("requestVerb(%s %s, hasName=%v) = %q, want %q",
+				test.method, test.rawQuery, test.hasName, got, test.want)
+		}
+	}
+}
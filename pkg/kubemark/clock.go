@@ -0,0 +1,34 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"k8s.io/utils/clock"
+)
+
+// simClock is the clock the latency/lifecycle simulations in this package
+// read time from. It defaults to the real clock; tests replace it through
+// SetSimulatedClock so simulated behavior is deterministic and can be
+// fast-forwarded.
+var simClock clock.WithTicker = clock.RealClock{}
+
+// SetSimulatedClock replaces the clock driving the simulations, e.g. with a
+// fake clock from k8s.io/utils/clock/testing. It must be called before any
+// simulator is created or started.
+func SetSimulatedClock(c clock.WithTicker) {
+	simClock = c
+}
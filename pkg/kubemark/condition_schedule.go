@@ -0,0 +1,209 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	nodeutil "k8s.io/component-helpers/node/util"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/yaml"
+)
+
+// ConditionEpisode is one timed condition/taint injection in a schedule.
+type ConditionEpisode struct {
+	// StartAfter is when the episode begins, relative to node start.
+	StartAfter metav1.Duration `json:"startAfter"`
+	// Duration is how long the episode lasts.
+	Duration metav1.Duration `json:"duration"`
+	// Condition is the node condition type to inject, e.g. "DiskPressure",
+	// "PIDPressure" or "Ready". During the episode the condition is held at
+	// True ("Ready" at False); afterwards the kubelet's own status sync
+	// takes back over.
+	Condition string `json:"condition,omitempty"`
+	// Reason and Message override the defaults derived from the condition.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Taint, when set, is applied to the node for the duration of the
+	// episode and removed afterwards.
+	Taint *v1.Taint `json:"taint,omitempty"`
+}
+
+// ConditionSchedule is a YAML timeline of node condition and taint
+// injections. It drives the consumers of node state rather than the node
+// itself: the node-lifecycle controller's reaction to pressure conditions,
+// taint-based eviction and descheduler policies can all be exercised on a
+// kubemark cluster by scheduling the states they react to.
+//
+// Example:
+//
+//	episodes:
+//	- startAfter: 10m
+//	  duration: 5m
+//	  condition: DiskPressure
+//	- startAfter: 30m
+//	  duration: 2m
+//	  condition: Ready
+//	- startAfter: 45m
+//	  duration: 10m
+//	  taint:
+//	    key: example.com/degraded
+//	    effect: NoSchedule
+type ConditionSchedule struct {
+	Episodes []ConditionEpisode `json:"episodes"`
+}
+
+// LoadConditionSchedule reads and validates a condition schedule from a
+// YAML file.
+func LoadConditionSchedule(path string) (*ConditionSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading condition schedule from %v: %w", path, err)
+	}
+	schedule := &ConditionSchedule{}
+	if err := yaml.UnmarshalStrict(data, schedule); err != nil {
+		return nil, fmt.Errorf("error while parsing condition schedule %v: %w", path, err)
+	}
+	for i, episode := range schedule.Episodes {
+		if episode.Duration.Duration <= 0 {
+			return nil, fmt.Errorf("invalid condition schedule %v: episode %d must have a positive duration", path, i)
+		}
+		if episode.Condition == "" && episode.Taint == nil {
+			return nil, fmt.Errorf("invalid condition schedule %v: episode %d must set a condition or a taint", path, i)
+		}
+		if episode.Taint != nil && (episode.Taint.Key == "" || episode.Taint.Effect == "") {
+			return nil, fmt.Errorf("invalid condition schedule %v: episode %d taint needs a key and an effect", path, i)
+		}
+	}
+	return schedule, nil
+}
+
+// ConditionScheduleRunner walks a node through its condition schedule.
+type ConditionScheduleRunner struct {
+	Client   clientset.Interface
+	NodeName string
+	Schedule *ConditionSchedule
+}
+
+// NewConditionScheduleRunner creates the runner for this node.
+func NewConditionScheduleRunner(client clientset.Interface, nodeName string, schedule *ConditionSchedule) *ConditionScheduleRunner {
+	return &ConditionScheduleRunner{
+		Client:   client,
+		NodeName: nodeName,
+		Schedule: schedule,
+	}
+}
+
+// Run evaluates the schedule every 5 seconds until stopCh is closed,
+// applying and clearing each episode's condition and taint as its window
+// opens and closes. Injected conditions are re-asserted on every tick while
+// their episode is active, since the kubelet's own status sync writes its
+// view of kubelet-owned conditions back between injections.
+func (r *ConditionScheduleRunner) Run(stopCh <-chan struct{}) {
+	go func() {
+		start := simClock.Now()
+		active := make([]bool, len(r.Schedule.Episodes))
+		ticker := simClock.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				elapsed := simClock.Now().Sub(start)
+				for i := range r.Schedule.Episodes {
+					episode := &r.Schedule.Episodes[i]
+					inWindow := elapsed >= episode.StartAfter.Duration && elapsed < episode.StartAfter.Duration+episode.Duration.Duration
+					switch {
+					case inWindow:
+						if !active[i] {
+							klog.V(2).Infof("Condition schedule episode %d starting on node %s", i, r.NodeName)
+						}
+						r.apply(episode)
+						active[i] = true
+					case active[i]:
+						klog.V(2).Infof("Condition schedule episode %d ending on node %s", i, r.NodeName)
+						r.clear(episode)
+						active[i] = false
+					}
+				}
+			}
+		}
+	}()
+}
+
+// apply asserts the episode's condition and taint on the node.
+func (r *ConditionScheduleRunner) apply(episode *ConditionEpisode) {
+	if episode.Condition != "" {
+		status := v1.ConditionTrue
+		if episode.Condition == string(v1.NodeReady) {
+			status = v1.ConditionFalse
+		}
+		if err := r.setCondition(episode, status); err != nil {
+			klog.Warningf("Failed to inject condition %s on node %s: %v", episode.Condition, r.NodeName, err)
+		}
+	}
+	if episode.Taint != nil {
+		if err := controller.AddOrUpdateTaintOnNode(context.TODO(), r.Client, r.NodeName, episode.Taint); err != nil {
+			klog.Warningf("Failed to apply scheduled taint %s on node %s: %v", episode.Taint.Key, r.NodeName, err)
+		}
+	}
+}
+
+// clear reverts the episode's condition and removes its taint.
+func (r *ConditionScheduleRunner) clear(episode *ConditionEpisode) {
+	if episode.Condition != "" {
+		status := v1.ConditionFalse
+		if episode.Condition == string(v1.NodeReady) {
+			status = v1.ConditionTrue
+		}
+		if err := r.setCondition(episode, status); err != nil {
+			klog.Warningf("Failed to clear condition %s on node %s: %v", episode.Condition, r.NodeName, err)
+		}
+	}
+	if episode.Taint != nil {
+		if err := controller.RemoveTaintOffNode(context.TODO(), r.Client, r.NodeName, nil, episode.Taint); err != nil {
+			klog.Warningf("Failed to remove scheduled taint %s from node %s: %v", episode.Taint.Key, r.NodeName, err)
+		}
+	}
+}
+
+func (r *ConditionScheduleRunner) setCondition(episode *ConditionEpisode, status v1.ConditionStatus) error {
+	reason := episode.Reason
+	if reason == "" {
+		reason = "KubemarkScheduledCondition"
+	}
+	message := episode.Message
+	if message == "" {
+		message = fmt.Sprintf("Condition %s injected by the kubemark condition schedule", episode.Condition)
+	}
+	return nodeutil.SetNodeCondition(r.Client, types.NodeName(r.NodeName), v1.NodeCondition{
+		Type:               v1.NodeConditionType(episode.Condition),
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+)
+
+// ContainerStartupSecondsAnnotation, when set on a pod, overrides the
+// node-wide container startup duration for that pod's containers.
+const ContainerStartupSecondsAnnotation = "kubemark.io/container-startup-seconds"
+
+// containerProgressionService makes hollow containers move through the same
+// observable status progression as real ones. Container starts take a
+// configurable amount of time, so pods spend it in Waiting
+// (ContainerCreating) before Running instead of jumping straight there, and
+// container statuses report digest-style image IDs. Together with the
+// ContainerID and startedAt/finishedAt timestamps the fake runtime already
+// maintains, this keeps controllers that read container statuses (Jobs,
+// kubectl wait) working correctly against hollow pods.
+type containerProgressionService struct {
+	internalapi.RuntimeService
+	// startupDuration is how long each container start takes, unless the
+	// pod overrides it via annotation.
+	startupDuration time.Duration
+
+	lock sync.Mutex
+	// startDelay remembers the per-container delay resolved at creation.
+	startDelay map[string]time.Duration
+}
+
+// NewContainerProgressionService wraps a runtime service so container starts
+// take the given duration and container statuses carry digest image IDs.
+func NewContainerProgressionService(delegate internalapi.RuntimeService, startupDuration time.Duration) internalapi.RuntimeService {
+	return &containerProgressionService{
+		RuntimeService:  delegate,
+		startupDuration: startupDuration,
+		startDelay:      make(map[string]time.Duration),
+	}
+}
+
+// CreateContainer resolves the container's startup delay from the pod's
+// annotations before delegating.
+func (s *containerProgressionService) CreateContainer(podSandboxID string, config *runtimeapi.ContainerConfig, sandboxConfig *runtimeapi.PodSandboxConfig) (string, error) {
+	delay := s.startupDuration
+	if value, ok := sandboxConfig.GetAnnotations()[ContainerStartupSecondsAnnotation]; ok {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else {
+			klog.Warningf("Ignoring invalid %s annotation %q", ContainerStartupSecondsAnnotation, value)
+		}
+	}
+	containerID, err := s.RuntimeService.CreateContainer(podSandboxID, config, sandboxConfig)
+	if err == nil && delay > 0 {
+		s.lock.Lock()
+		s.startDelay[containerID] = delay
+		s.lock.Unlock()
+	}
+	return containerID, err
+}
+
+// StartContainer sleeps for the container's startup delay before delegating,
+// keeping the container (and its pod) in the creating state for that long.
+func (s *containerProgressionService) StartContainer(containerID string) error {
+	s.lock.Lock()
+	delay := s.startDelay[containerID]
+	s.lock.Unlock()
+	if delay > 0 {
+		simClock.Sleep(delay)
+	}
+	return s.RuntimeService.StartContainer(containerID)
+}
+
+// RemoveContainer drops the remembered delay along with the container.
+func (s *containerProgressionService) RemoveContainer(containerID string) error {
+	s.lock.Lock()
+	delete(s.startDelay, containerID)
+	s.lock.Unlock()
+	return s.RuntimeService.RemoveContainer(containerID)
+}
+
+// ContainerStatus rewrites the image reference into the digest form real
+// runtimes report, which the kubelet surfaces as the container's imageID.
+func (s *containerProgressionService) ContainerStatus(containerID string, verbose bool) (*runtimeapi.ContainerStatusResponse, error) {
+	resp, err := s.RuntimeService.ContainerStatus(containerID, verbose)
+	if err == nil && resp != nil && resp.Status != nil {
+		resp.Status.ImageRef = imageDigestRef(resp.Status.GetImage().GetImage())
+	}
+	return resp, err
+}
+
+// imageDigestRef derives a stable digest-style reference from an image name,
+// e.g. "nginx:1.25" becomes "nginx@sha256:<64 hex chars>".
+func imageDigestRef(image string) string {
+	if image == "" {
+		return ""
+	}
+	name := image
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return fmt.Sprintf("%s@sha256:%x", name, sha256.Sum256([]byte(image)))
+}
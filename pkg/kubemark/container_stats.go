@@ -0,0 +1,315 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"hash/fnv"
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+)
+
+var simulatedLogBytes = metrics.NewGauge(
+	&metrics.GaugeOpts{
+		Subsystem:      "kubemark",
+		Name:           "simulated_log_bytes",
+		Help:           "Total bytes of simulated container logs retained on this hollow node after rotation.",
+		StabilityLevel: metrics.ALPHA,
+	},
+)
+
+func init() {
+	legacyregistry.MustRegister(simulatedLogBytes)
+}
+
+// SimulatedMemoryUsageAnnotation, when set on a pod, fixes the simulated
+// memory working set of each of its containers to the given quantity
+// (e.g. "200Mi"), overriding the QoS-derived default.
+const SimulatedMemoryUsageAnnotation = "kubemark.io/simulated-memory-working-set"
+
+// ContainerStatsSimulator periodically synthesizes CRI container stats for
+// every container in the fake runtime, so the kubelet's summary API reports
+// sensible utilization instead of zeros. Without the annotation override,
+// the memory working set is derived from the container's CRI resources by
+// QoS class: Guaranteed containers use 70-95% of their limit, Burstable
+// 60-90% of their request (reconstructed from the OOM score adjustment),
+// and BestEffort a few tens of MiB. The fraction is a stable per-container
+// value, so repeated scrapes are consistent. Pods can reshape the baseline
+// over time via the kubemark.io/usage-model annotation.
+type ContainerStatsSimulator struct {
+	Runtime *apitest.FakeRuntimeService
+	// MachineMemoryBytes is the node's memory capacity, used to invert the
+	// Burstable OOM score adjustment back into a memory request.
+	MachineMemoryBytes int64
+	// Network, when set, also feeds per-sandbox network usage into the fake
+	// runtime, so pod-level network stats are served wherever the kubelet
+	// sources them from CRI sandbox stats.
+	Network *NetworkTraffic
+	// StorageGrowthBytesPerHour, when positive, grows each running
+	// container's writable layer at a per-container share of this rate.
+	StorageGrowthBytesPerHour int64
+	// EphemeralStorage, when set, receives the summed writable-layer and log
+	// usage, so node-level rootfs stats track the simulated per-pod
+	// consumption.
+	EphemeralStorage *EphemeralStorageSimulator
+	// LogGrowthBytesPerHour, when positive, grows each running container's
+	// simulated log files at a per-container share of this rate.
+	LogGrowthBytesPerHour int64
+	// LogCapBytes caps the log bytes each container retains, modeling the
+	// kubelet's containerLogMaxSize x containerLogMaxFiles rotation policy:
+	// logs grow to the cap and rotation then discards the oldest file's
+	// worth, so retained usage plateaus there.
+	LogCapBytes int64
+
+	// cpuUsageNanos accumulates each container's cumulative CPU usage, so
+	// time-varying usage models translate into the instantaneous rates
+	// metrics-server derives from successive scrapes.
+	cpuUsageNanos map[string]float64
+	// lastRefresh is when CPU usage was last accumulated.
+	lastRefresh time.Time
+}
+
+// NewContainerStatsSimulator creates a simulator feeding stats into the
+// given fake runtime service.
+func NewContainerStatsSimulator(runtime *apitest.FakeRuntimeService, machineMemoryBytes int64) *ContainerStatsSimulator {
+	return &ContainerStatsSimulator{
+		Runtime:            runtime,
+		MachineMemoryBytes: machineMemoryBytes,
+		cpuUsageNanos:      make(map[string]float64),
+	}
+}
+
+// Run refreshes the simulated stats every 10 seconds until stopCh is closed.
+func (s *ContainerStatsSimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.refresh()
+			}
+		}
+	}()
+}
+
+func (s *ContainerStatsSimulator) refresh() {
+	// Snapshot the containers and their sandboxes' annotations under the
+	// fake's lock, then publish without holding it.
+	s.Runtime.Lock()
+	containers := make([]*apitest.FakeContainer, 0, len(s.Runtime.Containers))
+	sandboxAnnotations := make(map[string]map[string]string, len(s.Runtime.Sandboxes))
+	sandboxes := make([]*apitest.FakePodSandbox, 0, len(s.Runtime.Sandboxes))
+	for _, c := range s.Runtime.Containers {
+		containers = append(containers, c)
+	}
+	for id, sandbox := range s.Runtime.Sandboxes {
+		sandboxAnnotations[id] = sandbox.Annotations
+		sandboxes = append(sandboxes, sandbox)
+	}
+	s.Runtime.Unlock()
+
+	now := simClock.Now()
+	interval := time.Duration(0)
+	if !s.lastRefresh.IsZero() {
+		interval = now.Sub(s.lastRefresh)
+	}
+	s.lastRefresh = now
+
+	stats := make([]*runtimeapi.ContainerStats, 0, len(containers))
+	totalStorage := int64(0)
+	totalLogs := int64(0)
+	seen := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		if c.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		seen[c.Id] = true
+		annotations := sandboxAnnotations[c.SandboxID]
+		multiplier := usageModelFor(annotations).multiplier(c.Id, time.Unix(0, c.CreatedAt), now)
+		s.accumulateCPU(c, multiplier, interval, now)
+		storage := s.writableLayerBytes(c, now)
+		totalStorage += storage
+		totalLogs += s.logBytes(c, now)
+		stats = append(stats, s.containerStats(c, annotations, now, storage, multiplier))
+	}
+	for id := range s.cpuUsageNanos {
+		if !seen[id] {
+			delete(s.cpuUsageNanos, id)
+		}
+	}
+	s.Runtime.SetFakeContainerStats(stats)
+	simulatedLogBytes.Set(float64(totalLogs))
+	if s.EphemeralStorage != nil {
+		s.EphemeralStorage.SetUsedBytes(totalStorage + totalLogs)
+	}
+
+	if s.Network != nil {
+		sandboxStats := make([]*runtimeapi.PodSandboxStats, 0, len(sandboxes))
+		for _, sandbox := range sandboxes {
+			if sandbox.State != runtimeapi.PodSandboxState_SANDBOX_READY {
+				continue
+			}
+			sandboxStats = append(sandboxStats, &runtimeapi.PodSandboxStats{
+				Attributes: &runtimeapi.PodSandboxAttributes{
+					Id:          sandbox.Id,
+					Metadata:    sandbox.Metadata,
+					Labels:      sandbox.Labels,
+					Annotations: sandbox.Annotations,
+				},
+				Linux: &runtimeapi.LinuxPodSandboxStats{
+					Network: s.Network.SandboxNetworkUsage(sandbox.Id, sandbox.CreatedAt, now),
+				},
+			})
+		}
+		s.Runtime.SetFakePodSandboxStats(sandboxStats)
+	}
+}
+
+func (s *ContainerStatsSimulator) containerStats(c *apitest.FakeContainer, annotations map[string]string, now time.Time, storageBytes int64, multiplier float64) *runtimeapi.ContainerStats {
+	workingSet := int64(float64(s.workingSetBytes(c, annotations)) * multiplier)
+	// Even idle containers keep their binary and runtime resident.
+	if minimum := int64(8 * 1024 * 1024); workingSet < minimum {
+		workingSet = minimum
+	}
+	return &runtimeapi.ContainerStats{
+		Attributes: &runtimeapi.ContainerAttributes{
+			Id:          c.Id,
+			Metadata:    c.Metadata,
+			Labels:      c.Labels,
+			Annotations: c.Annotations,
+		},
+		Cpu: &runtimeapi.CpuUsage{
+			Timestamp:            now.UnixNano(),
+			UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: uint64(s.cpuUsageNanos[c.Id])},
+		},
+		Memory: &runtimeapi.MemoryUsage{
+			Timestamp:       now.UnixNano(),
+			WorkingSetBytes: &runtimeapi.UInt64Value{Value: uint64(workingSet)},
+			UsageBytes:      &runtimeapi.UInt64Value{Value: uint64(workingSet)},
+		},
+		WritableLayer: &runtimeapi.FilesystemUsage{
+			Timestamp: now.UnixNano(),
+			UsedBytes: &runtimeapi.UInt64Value{Value: uint64(storageBytes)},
+		},
+	}
+}
+
+// writableLayerBytes derives a container's simulated writable-layer size:
+// a per-container share of the configured growth rate, accumulating since
+// the container was created, so per-pod ephemeral-storage consumption grows
+// over time the way leaking logs and scratch files do.
+func (s *ContainerStatsSimulator) writableLayerBytes(c *apitest.FakeContainer, now time.Time) int64 {
+	if s.StorageGrowthBytesPerHour <= 0 {
+		return 0
+	}
+	elapsedHours := float64(now.UnixNano()-c.CreatedAt) / float64(time.Hour)
+	if elapsedHours < 0 {
+		elapsedHours = 0
+	}
+	// Each container grows at 50-150% of the configured rate.
+	rate := (0.5 + containerFraction(c.Id)) * float64(s.StorageGrowthBytesPerHour)
+	return int64(rate * elapsedHours)
+}
+
+// logBytes derives a container's simulated retained log size: a
+// per-container share of the configured growth rate accumulating since
+// creation, capped at the rotation policy's retained total. Logs count
+// toward node rootfs usage but not the container's writable layer, matching
+// where real runtimes keep them.
+func (s *ContainerStatsSimulator) logBytes(c *apitest.FakeContainer, now time.Time) int64 {
+	if s.LogGrowthBytesPerHour <= 0 {
+		return 0
+	}
+	elapsedHours := float64(now.UnixNano()-c.CreatedAt) / float64(time.Hour)
+	if elapsedHours < 0 {
+		elapsedHours = 0
+	}
+	// Chatty and quiet containers log at 50-150% of the configured rate.
+	written := int64((0.5 + containerFraction(c.Id)) * float64(s.LogGrowthBytesPerHour) * elapsedHours)
+	if s.LogCapBytes > 0 && written > s.LogCapBytes {
+		return s.LogCapBytes
+	}
+	return written
+}
+
+// workingSetBytes derives a container's simulated memory working set.
+func (s *ContainerStatsSimulator) workingSetBytes(c *apitest.FakeContainer, annotations map[string]string) int64 {
+	if value, ok := annotations[SimulatedMemoryUsageAnnotation]; ok {
+		if quantity, err := resource.ParseQuantity(value); err == nil {
+			return quantity.Value()
+		}
+	}
+	limit := c.LinuxResources.GetMemoryLimitInBytes()
+	oomScoreAdj := c.LinuxResources.GetOomScoreAdj()
+	fraction := containerFraction(c.Id)
+	switch {
+	case oomScoreAdj < 0 && limit > 0:
+		// Guaranteed: 70-95% of the limit.
+		return int64((0.70 + 0.25*fraction) * float64(limit))
+	case oomScoreAdj >= 1000 || (limit == 0 && oomScoreAdj == 0):
+		// BestEffort: a modest 30-80 MiB.
+		return int64((30 + 50*fraction) * 1024 * 1024)
+	default:
+		// Burstable: 60-90% of the request. The kubelet encodes the memory
+		// request into the OOM score adjustment as
+		// 1000 - 1000*request/capacity, which we invert here.
+		request := (1000 - int64(oomScoreAdj)) * s.MachineMemoryBytes / 1000
+		usage := int64((0.60 + 0.30*fraction) * float64(request))
+		if limit > 0 && usage > limit {
+			usage = limit
+		}
+		return usage
+	}
+}
+
+// accumulateCPU advances a container's cumulative CPU usage by its current
+// rate over the refresh interval. A newly observed container is seeded as if
+// it had run at that rate since creation.
+func (s *ContainerStatsSimulator) accumulateCPU(c *apitest.FakeContainer, multiplier float64, interval time.Duration, now time.Time) {
+	rate := s.baselineCPUCores(c) * multiplier
+	if _, ok := s.cpuUsageNanos[c.Id]; !ok {
+		s.cpuUsageNanos[c.Id] = math.Max(rate*float64(now.UnixNano()-c.CreatedAt), 0)
+		return
+	}
+	s.cpuUsageNanos[c.Id] += rate * float64(interval.Nanoseconds())
+}
+
+// baselineCPUCores is a container's baseline CPU rate before any usage
+// model applies: 40-80% of its CPU request, stable per container.
+func (s *ContainerStatsSimulator) baselineCPUCores(c *apitest.FakeContainer) float64 {
+	requestCores := float64(c.LinuxResources.GetCpuShares()) / 1024
+	if requestCores == 0 {
+		// BestEffort containers run with minimal shares; simulate a sliver.
+		requestCores = 0.01
+	}
+	return (0.40 + 0.40*containerFraction(c.Id)) * requestCores
+}
+
+// containerFraction maps a container ID to a stable value in [0, 1).
+func containerFraction(id string) float64 {
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	return float64(hash.Sum32()) / float64(math.MaxUint32)
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+// ScenarioStep is one globally coordinated step of a simulation scenario. The
+// coordinator divides ChurnQuota evenly between the member processes that are
+// alive when the step starts.
+type ScenarioStep struct {
+	// Name identifies the step to the per-process callback.
+	Name string `json:"name"`
+	// Duration is how long the step runs before the leader advances.
+	Duration metav1.Duration `json:"duration"`
+	// ChurnQuota is the fleet-wide churn budget for this step.
+	ChurnQuota int `json:"churnQuota"`
+}
+
+// coordinatorPlan is what the leader publishes for all members to consume.
+type coordinatorPlan struct {
+	Step       ScenarioStep `json:"step"`
+	Members    int          `json:"members"`
+	PerProcess int          `json:"perProcess"`
+}
+
+// Coordinator distributes scenario steps and churn quotas across many
+// simulator processes. One process is elected leader via a Lease and
+// publishes the current step and per-process quota in a ConfigMap; every
+// member (including the leader) watches the ConfigMap and invokes OnStep, so
+// multi-process kubemark deployments execute coherent global scenarios
+// instead of each process acting independently.
+type Coordinator struct {
+	Client    clientset.Interface
+	Namespace string
+	// Name is the base name for the coordination Lease and plan ConfigMap.
+	Name string
+	// Identity uniquely identifies this process, e.g. pod name.
+	Identity string
+	// Scenario is the ordered list of steps the leader walks through.
+	Scenario []ScenarioStep
+	// OnStep is called on every member whenever the published step or this
+	// process's quota changes.
+	OnStep func(step ScenarioStep, perProcessQuota int)
+
+	memberLeaseDuration time.Duration
+}
+
+// NewCoordinator creates a coordinator for this process.
+func NewCoordinator(client clientset.Interface, namespace, name, identity string, scenario []ScenarioStep, onStep func(ScenarioStep, int)) *Coordinator {
+	return &Coordinator{
+		Client:              client,
+		Namespace:           namespace,
+		Name:                name,
+		Identity:            identity,
+		Scenario:            scenario,
+		OnStep:              onStep,
+		memberLeaseDuration: 30 * time.Second,
+	}
+}
+
+// Run starts membership heartbeating, leader election and plan consumption.
+// It blocks until the context is cancelled.
+func (c *Coordinator) Run(ctx context.Context) error {
+	go c.heartbeatMembership(ctx)
+	go c.consumePlan(ctx)
+
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock,
+		c.Namespace, c.Name,
+		c.Client.CoreV1(), c.Client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: c.Identity})
+	if err != nil {
+		return fmt.Errorf("error while creating resource lock: %w", err)
+	}
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Coordinator %s became leader", c.Identity)
+				c.lead(ctx)
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("Coordinator %s lost leadership", c.Identity)
+			},
+		},
+	})
+	return ctx.Err()
+}
+
+// heartbeatMembership renews this process's member Lease so the leader can
+// count live members when splitting quotas.
+func (c *Coordinator) heartbeatMembership(ctx context.Context) {
+	leaseName := fmt.Sprintf("%s-member-%s", c.Name, c.Identity)
+	for {
+		now := metav1.NewMicroTime(time.Now())
+		lease, err := c.Client.CoordinationV1().Leases(c.Namespace).Get(ctx, leaseName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			lease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      leaseName,
+					Namespace: c.Namespace,
+					Labels:    map[string]string{"kubemark-coordinator": c.Name},
+				},
+				Spec: coordinationv1.LeaseSpec{
+					HolderIdentity:       &c.Identity,
+					LeaseDurationSeconds: utilpointer.Int32(int32(c.memberLeaseDuration.Seconds())),
+					RenewTime:            &now,
+				},
+			}
+			_, err = c.Client.CoordinationV1().Leases(c.Namespace).Create(ctx, lease, metav1.CreateOptions{})
+		} else if err == nil {
+			lease.Spec.RenewTime = &now
+			_, err = c.Client.CoordinationV1().Leases(c.Namespace).Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			klog.Warningf("Failed to heartbeat coordinator membership: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.memberLeaseDuration / 3):
+		}
+	}
+}
+
+// countMembers counts members whose Lease has been renewed recently.
+func (c *Coordinator) countMembers(ctx context.Context) int {
+	leases, err := c.Client.CoordinationV1().Leases(c.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "kubemark-coordinator=" + c.Name,
+	})
+	if err != nil {
+		klog.Warningf("Failed to list coordinator members: %v", err)
+		return 1
+	}
+	members := 0
+	for _, lease := range leases.Items {
+		if lease.Spec.RenewTime != nil && time.Since(lease.Spec.RenewTime.Time) < c.memberLeaseDuration {
+			members++
+		}
+	}
+	if members == 0 {
+		members = 1
+	}
+	return members
+}
+
+// lead walks the scenario, publishing each step with a per-process quota
+// derived from the current member count. It returns when the scenario is
+// exhausted or leadership is lost.
+func (c *Coordinator) lead(ctx context.Context) {
+	for _, step := range c.Scenario {
+		members := c.countMembers(ctx)
+		plan := coordinatorPlan{
+			Step:       step,
+			Members:    members,
+			PerProcess: step.ChurnQuota / members,
+		}
+		if err := c.publishPlan(ctx, plan); err != nil {
+			klog.Warningf("Failed to publish scenario step %q: %v", step.Name, err)
+		} else {
+			klog.Infof("Published scenario step %q for %d members (%d churn per process)", step.Name, members, plan.PerProcess)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(step.Duration.Duration):
+		}
+	}
+	<-ctx.Done()
+}
+
+func (c *Coordinator) publishPlan(ctx context.Context, plan coordinatorPlan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	name := c.Name + "-plan"
+	configMap, err := c.Client.CoreV1().ConfigMaps(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: c.Namespace},
+			Data:       map[string]string{"plan": string(data)},
+		}
+		_, err = c.Client.CoreV1().ConfigMaps(c.Namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data["plan"] = string(data)
+	_, err = c.Client.CoreV1().ConfigMaps(c.Namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// consumePlan polls the published plan and invokes OnStep on changes.
+func (c *Coordinator) consumePlan(ctx context.Context) {
+	var lastPlan string
+	for {
+		configMap, err := c.Client.CoreV1().ConfigMaps(c.Namespace).Get(ctx, c.Name+"-plan", metav1.GetOptions{})
+		if err == nil {
+			if raw := configMap.Data["plan"]; raw != "" && raw != lastPlan {
+				var plan coordinatorPlan
+				if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+					klog.Warningf("Failed to decode coordinator plan: %v", err)
+				} else {
+					lastPlan = raw
+					if c.OnStep != nil {
+						c.OnStep(plan.Step, plan.PerProcess)
+					}
+				}
+			}
+		} else if !apierrors.IsNotFound(err) {
+			klog.Warningf("Failed to read coordinator plan: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
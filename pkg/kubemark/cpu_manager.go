@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"time"
+
+	cadvisorapi "github.com/google/cadvisor/info/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+	"k8s.io/kubernetes/pkg/kubelet/cm/memorymanager"
+	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
+)
+
+// NewTopologyManager creates a real topology manager with the given policy
+// (best-effort, restricted or single-numa-node) over the synthetic NUMA
+// topology, so TopologyAffinityError admission rejections can be reproduced
+// on hollow nodes. The CPU and memory managers should be constructed with
+// the returned manager as their affinity store.
+func NewTopologyManager(machineInfo *cadvisorapi.MachineInfo, policy string) (topologymanager.Manager, error) {
+	return topologymanager.NewManager(machineInfo.Topology, policy, kubeletconfig.ContainerTopologyManagerScope)
+}
+
+// NewStaticCPUManager creates a real CPU manager running the static policy
+// over the given (typically synthetic) machine topology, so exclusive CPU
+// assignment behavior can be exercised on hollow nodes without real
+// hardware. One CPU is reserved for the system, as the static policy
+// requires a non-empty reservation. affinity is the topology manager used as
+// the affinity store; pass nil when no real topology manager is configured.
+func NewStaticCPUManager(machineInfo *cadvisorapi.MachineInfo, stateFileDirectory string, affinity topologymanager.Store) (cpumanager.Manager, error) {
+	if affinity == nil {
+		affinity = topologymanager.NewFakeManager()
+	}
+	reservation := v1.ResourceList{
+		v1.ResourceCPU: *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	return cpumanager.NewManager(
+		string(cpumanager.PolicyStatic),
+		nil,
+		10*time.Second,
+		machineInfo,
+		cpuset.NewCPUSet(),
+		reservation,
+		stateFileDirectory,
+		affinity,
+	)
+}
+
+// NewStaticMemoryManager creates a real memory manager running the static
+// policy over the given synthetic NUMA topology, so pods requesting
+// hugepages and pinned memory are admitted and accounted on hollow nodes.
+// One GiB on NUMA node 0 is reserved for the system, as the static policy
+// requires the reservation to be declared per NUMA node. affinity is the
+// topology manager used as the affinity store; pass nil when no real
+// topology manager is configured.
+func NewStaticMemoryManager(machineInfo *cadvisorapi.MachineInfo, stateFileDirectory string, affinity topologymanager.Store) (memorymanager.Manager, error) {
+	if affinity == nil {
+		affinity = topologymanager.NewFakeManager()
+	}
+	reserved := v1.ResourceList{
+		v1.ResourceMemory: *resource.NewQuantity(1024*1024*1024, resource.BinarySI),
+	}
+	return memorymanager.NewManager(
+		"Static",
+		machineInfo,
+		reserved,
+		[]kubeletconfig.MemoryReservation{
+			{NumaNode: 0, Limits: reserved},
+		},
+		stateFileDirectory,
+		affinity,
+	)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CrashAfterSecondsAnnotation, when set on a pod, makes each of its
+	// containers exit that many seconds after starting.
+	CrashAfterSecondsAnnotation = "kubemark.io/crash-after-seconds"
+	// CrashExitCodeAnnotation sets the exit code of the simulated crash.
+	// Zero makes the container terminate successfully; without the
+	// annotation crashes exit 1.
+	CrashExitCodeAnnotation = "kubemark.io/crash-exit-code"
+)
+
+var containerCrashesTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "simulated_container_crashes_total",
+		Help:           "Number of simulated container exits injected on this hollow node, partitioned by exit code.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"exit_code"},
+)
+
+func init() {
+	legacyregistry.MustRegister(containerCrashesTotal)
+}
+
+// CrashRule matches pods in a crash policy file and describes how their
+// containers fail.
+type CrashRule struct {
+	// Namespace, when set, restricts the rule to pods in that namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// NamePrefix, when set, restricts the rule to pods whose name starts
+	// with the prefix (e.g. a deployment or statefulset name).
+	NamePrefix string `json:"namePrefix,omitempty"`
+	// FailAfter is how long after starting each container exits.
+	FailAfter metav1.Duration `json:"failAfter"`
+	// ExitCode is the exit code of the simulated failure. Zero simulates a
+	// successful termination.
+	ExitCode int32 `json:"exitCode,omitempty"`
+}
+
+// CrashPolicy is a YAML list of crash rules; the first matching rule wins.
+//
+// Example:
+//
+//	rules:
+//	- namespace: load-test
+//	  namePrefix: flaky-
+//	  failAfter: 30s
+//	  exitCode: 137
+type CrashPolicy struct {
+	Rules []CrashRule `json:"rules"`
+}
+
+// LoadCrashPolicy reads and validates a crash policy from a YAML file.
+func LoadCrashPolicy(path string) (*CrashPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading crash policy from %v: %w", path, err)
+	}
+	policy := &CrashPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("error while parsing crash policy %v: %w", path, err)
+	}
+	for i, rule := range policy.Rules {
+		if rule.FailAfter.Duration <= 0 {
+			return nil, fmt.Errorf("invalid crash policy %v: rule %d must set a positive failAfter", path, i)
+		}
+	}
+	return policy, nil
+}
+
+// match returns the crash parameters for a pod, preferring its annotations
+// over the policy file, or false if the pod should not crash.
+func (p *CrashPolicy) match(sandbox *apitest.FakePodSandbox) (time.Duration, int32, bool) {
+	if value, ok := sandbox.Annotations[CrashAfterSecondsAnnotation]; ok {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			klog.Warningf("Ignoring invalid %s annotation %q on pod %s/%s", CrashAfterSecondsAnnotation, value, sandbox.Metadata.Namespace, sandbox.Metadata.Name)
+			return 0, 0, false
+		}
+		exitCode := int32(1)
+		if codeValue, ok := sandbox.Annotations[CrashExitCodeAnnotation]; ok {
+			if code, err := strconv.Atoi(codeValue); err == nil {
+				exitCode = int32(code)
+			}
+		}
+		return time.Duration(seconds) * time.Second, exitCode, true
+	}
+	if p == nil {
+		return 0, 0, false
+	}
+	for _, rule := range p.Rules {
+		if rule.Namespace != "" && rule.Namespace != sandbox.Metadata.Namespace {
+			continue
+		}
+		if rule.NamePrefix != "" && !strings.HasPrefix(sandbox.Metadata.Name, rule.NamePrefix) {
+			continue
+		}
+		return rule.FailAfter.Duration, rule.ExitCode, true
+	}
+	return 0, 0, false
+}
+
+// CrashSimulator makes containers of matched pods exit after a configured
+// time with a configured exit code. It only flips the container state in the
+// fake runtime; the real kubelet machinery then honors the pod's
+// restartPolicy, increments restartCount, and transitions the pod through
+// CrashLoopBackOff exactly as it would for a genuinely crashing container.
+type CrashSimulator struct {
+	Runtime *apitest.FakeRuntimeService
+	// Policy, when set, selects pods by namespace and name prefix. Pods can
+	// also opt in individually via the crash annotations, which take
+	// precedence.
+	Policy *CrashPolicy
+}
+
+// NewCrashSimulator creates a crash simulator over the given fake runtime.
+func NewCrashSimulator(runtime *apitest.FakeRuntimeService, policy *CrashPolicy) *CrashSimulator {
+	return &CrashSimulator{Runtime: runtime, Policy: policy}
+}
+
+// Run scans for due crashes every 5 seconds until stopCh is closed.
+func (s *CrashSimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.crashDueContainers()
+			}
+		}
+	}()
+}
+
+// crashDueContainers marks running containers of matched pods as exited once
+// they have run for their configured lifetime.
+func (s *CrashSimulator) crashDueContainers() {
+	now := simClock.Now()
+	s.Runtime.Lock()
+	defer s.Runtime.Unlock()
+	for _, c := range s.Runtime.Containers {
+		if c.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		sandbox, ok := s.Runtime.Sandboxes[c.SandboxID]
+		if !ok {
+			continue
+		}
+		failAfter, exitCode, matched := s.Policy.match(sandbox)
+		if !matched {
+			continue
+		}
+		startedAt := c.StartedAt
+		if startedAt == 0 {
+			startedAt = c.CreatedAt
+		}
+		if now.UnixNano()-startedAt < failAfter.Nanoseconds() {
+			continue
+		}
+		c.State = runtimeapi.ContainerState_CONTAINER_EXITED
+		c.FinishedAt = now.UnixNano()
+		c.ExitCode = exitCode
+		if exitCode == 0 {
+			c.Reason = "Completed"
+		} else {
+			c.Reason = "Error"
+			c.Message = fmt.Sprintf("simulated crash after %v", failAfter)
+		}
+		containerCrashesTotal.WithLabelValues(strconv.Itoa(int(exitCode))).Inc()
+		klog.V(3).Infof("Simulated exit %d of container %s in pod %s/%s", exitCode, c.Id, sandbox.Metadata.Namespace, sandbox.Metadata.Name)
+	}
+}
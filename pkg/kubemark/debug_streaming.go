@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"k8s.io/client-go/tools/remotecommand"
+	internalapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubelet/cri/streaming"
+)
+
+// debugStreamingService gives hollow containers working exec, attach and
+// port-forward streams. The fake runtime answers those requests with empty
+// URLs, which the kubelet rejects, so `kubectl exec` and the interactive
+// half of `kubectl debug` fail against hollow pods even though the kubelet
+// happily accepts the ephemeralcontainers update, starts the debug container
+// and reports it Running. The wrapper serves real streaming URLs from an
+// in-process loopback server; the kubelet proxies client connections to it.
+type debugStreamingService struct {
+	internalapi.RuntimeService
+	streams streaming.Server
+}
+
+// NewDebugStreamingService wraps a runtime service so streaming requests
+// return URLs served by a loopback streaming server, where a banner and an
+// echo loop stand in for the container's shell.
+func NewDebugStreamingService(delegate internalapi.RuntimeService) (internalapi.RuntimeService, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("error while creating streaming listener: %w", err)
+	}
+	config := streaming.DefaultConfig
+	config.Addr = listener.Addr().String()
+	server, err := streaming.NewServer(config, debugRuntime{})
+	if err != nil {
+		return nil, fmt.Errorf("error while creating streaming server: %w", err)
+	}
+	go func() {
+		if err := http.Serve(listener, server); err != nil {
+			klog.Errorf("Debug streaming server failed: %v", err)
+		}
+	}()
+	klog.V(2).Infof("Debug streaming server listening on %s", config.Addr)
+	return &debugStreamingService{RuntimeService: delegate, streams: server}, nil
+}
+
+// Exec returns a serving URL for the exec request.
+func (s *debugStreamingService) Exec(req *runtimeapi.ExecRequest) (*runtimeapi.ExecResponse, error) {
+	return s.streams.GetExec(req)
+}
+
+// Attach returns a serving URL for the attach request.
+func (s *debugStreamingService) Attach(req *runtimeapi.AttachRequest) (*runtimeapi.AttachResponse, error) {
+	return s.streams.GetAttach(req)
+}
+
+// PortForward returns a serving URL for the port-forward request.
+func (s *debugStreamingService) PortForward(req *runtimeapi.PortForwardRequest) (*runtimeapi.PortForwardResponse, error) {
+	return s.streams.GetPortForward(req)
+}
+
+// debugRuntime implements the streaming server's runtime: hollow containers
+// have no processes, so streams get a banner and echo input back until the
+// client disconnects.
+type debugRuntime struct{}
+
+func (debugRuntime) Exec(containerID string, cmd []string, in io.Reader, out, _ io.WriteCloser, tty bool, _ <-chan remotecommand.TerminalSize) error {
+	fmt.Fprintf(out, "hollow container %s: exec %v\r\n", containerID, cmd)
+	return echoStream(in, out)
+}
+
+func (debugRuntime) Attach(containerID string, in io.Reader, out, _ io.WriteCloser, tty bool, _ <-chan remotecommand.TerminalSize) error {
+	fmt.Fprintf(out, "hollow container %s: attached\r\n", containerID)
+	return echoStream(in, out)
+}
+
+func (debugRuntime) PortForward(podSandboxID string, port int32, stream io.ReadWriteCloser) error {
+	// Nothing listens inside a hollow pod; close the stream immediately, the
+	// behavior of forwarding to a closed port.
+	return stream.Close()
+}
+
+// echoStream copies input back to the client until it disconnects.
+func echoStream(in io.Reader, out io.WriteCloser) error {
+	defer out.Close()
+	if in == nil {
+		return nil
+	}
+	_, err := io.Copy(out, in)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
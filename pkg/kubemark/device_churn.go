@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"math"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var unhealthyDevices = metrics.NewGaugeVec(
+	&metrics.GaugeOpts{
+		Subsystem:      "kubemark",
+		Name:           "unhealthy_devices",
+		Help:           "Number of simulated device plugin devices currently reported Unhealthy, per resource.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"resource"},
+)
+
+func init() {
+	legacyregistry.MustRegister(unhealthyDevices)
+}
+
+// DeviceHealthSetter is the part of the stub container manager the churner
+// drives: reading the simulated device plugin capacity and marking some of
+// the devices unhealthy.
+type DeviceHealthSetter interface {
+	GetDevicePluginResourceCapacity() (v1.ResourceList, v1.ResourceList, []string)
+	SetDevicePluginResourceUnhealthy(v1.ResourceList)
+}
+
+// DeviceHealthChurner periodically flips the configured fraction of each
+// device plugin resource to Unhealthy and back, shrinking allocatable on the
+// node, so device-failure handling in the scheduler and device manager can
+// be exercised at scale.
+type DeviceHealthChurner struct {
+	Setter DeviceHealthSetter
+	// Fraction of each resource's devices to mark unhealthy, in (0, 1].
+	Fraction float64
+	// Period between health flips.
+	Period time.Duration
+}
+
+// NewDeviceHealthChurner creates a churner marking the given fraction of
+// devices unhealthy every period, recovering them the period after.
+func NewDeviceHealthChurner(setter DeviceHealthSetter, fraction float64, period time.Duration) *DeviceHealthChurner {
+	return &DeviceHealthChurner{
+		Setter:   setter,
+		Fraction: fraction,
+		Period:   period,
+	}
+}
+
+// Run alternates between degraded and healthy states until stopCh is closed.
+func (c *DeviceHealthChurner) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(c.Period)
+		defer ticker.Stop()
+		degraded := false
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				degraded = !degraded
+				c.apply(degraded)
+			}
+		}
+	}()
+}
+
+func (c *DeviceHealthChurner) apply(degraded bool) {
+	capacity, _, _ := c.Setter.GetDevicePluginResourceCapacity()
+	unhealthy := v1.ResourceList{}
+	for name, quantity := range capacity {
+		count := int64(0)
+		if degraded {
+			count = int64(math.Round(c.Fraction * float64(quantity.Value())))
+		}
+		unhealthy[name] = *resource.NewQuantity(count, resource.DecimalSI)
+		unhealthyDevices.WithLabelValues(string(name)).Set(float64(count))
+		if count > 0 {
+			klog.V(2).Infof("Marking %d of %d %s devices unhealthy", count, quantity.Value(), name)
+		}
+	}
+	c.Setter.SetDevicePluginResourceUnhealthy(unhealthy)
+}
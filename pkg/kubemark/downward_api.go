@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	apiv1resource "k8s.io/kubernetes/pkg/api/v1/resource"
+	podshelper "k8s.io/kubernetes/pkg/apis/core/pods"
+	"k8s.io/kubernetes/pkg/fieldpath"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// DownwardAPIValidationHandler resolves every Downward API env var and
+// volume item of a pod at hollow kubelet admission and reports resolution
+// failures as events. The real kubelet machinery in the hollow node already
+// performs Downward API resolution at container start and volume setup, but
+// against the fake runtime those paths never fail a pod visibly; this
+// handler makes bad fieldRef/resourceFieldRef selectors (including
+// resourceFieldRefs evaluated against the node's simulated allocatable)
+// show up as pod events so Downward API workloads can be validated at
+// scale. It never rejects.
+type DownwardAPIValidationHandler struct {
+	Client   clientset.Interface
+	NodeName string
+	recorder record.EventRecorder
+}
+
+var _ lifecycle.PodAdmitHandler = &DownwardAPIValidationHandler{}
+
+// NewDownwardAPIValidationHandler creates the validation handler and its
+// event recorder.
+func NewDownwardAPIValidationHandler(client clientset.Interface, nodeName string) *DownwardAPIValidationHandler {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "hollow-kubelet", Host: nodeName})
+	return &DownwardAPIValidationHandler{
+		Client:   client,
+		NodeName: nodeName,
+		recorder: recorder,
+	}
+}
+
+// Admit implements lifecycle.PodAdmitHandler.
+func (h *DownwardAPIValidationHandler) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	pod := attrs.Pod
+	var allocatable v1.ResourceList
+	if node, err := h.Client.CoreV1().Nodes().Get(context.TODO(), h.NodeName, metav1.GetOptions{}); err == nil {
+		allocatable = node.Status.Allocatable
+	} else {
+		klog.V(3).Infof("Validating downward API for pod %s/%s without node allocatable: %v", pod.Namespace, pod.Name, err)
+	}
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for i := range containers {
+		container := &containers[i]
+		for _, envVar := range container.Env {
+			if envVar.ValueFrom == nil {
+				continue
+			}
+			switch {
+			case envVar.ValueFrom.FieldRef != nil:
+				if err := resolveFieldRef(pod, envVar.ValueFrom.FieldRef); err != nil {
+					h.report(pod, fmt.Sprintf("cannot resolve env %s of container %s: %v", envVar.Name, container.Name, err))
+				}
+			case envVar.ValueFrom.ResourceFieldRef != nil:
+				if err := resolveResourceFieldRef(pod, envVar.ValueFrom.ResourceFieldRef, container.Name, allocatable); err != nil {
+					h.report(pod, fmt.Sprintf("cannot resolve env %s of container %s: %v", envVar.Name, container.Name, err))
+				}
+			}
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		var items []v1.DownwardAPIVolumeFile
+		if volume.DownwardAPI != nil {
+			items = volume.DownwardAPI.Items
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.DownwardAPI != nil {
+					items = append(items, source.DownwardAPI.Items...)
+				}
+			}
+		}
+		for _, item := range items {
+			var err error
+			switch {
+			case item.FieldRef != nil:
+				err = resolveFieldRef(pod, item.FieldRef)
+			case item.ResourceFieldRef != nil:
+				err = resolveResourceFieldRef(pod, item.ResourceFieldRef, item.ResourceFieldRef.ContainerName, allocatable)
+			}
+			if err != nil {
+				h.report(pod, fmt.Sprintf("cannot resolve item %s of downward API volume %s: %v", item.Path, volume.Name, err))
+			}
+		}
+	}
+	return lifecycle.PodAdmitResult{Admit: true}
+}
+
+func (h *DownwardAPIValidationHandler) report(pod *v1.Pod, message string) {
+	klog.V(2).Infof("Downward API resolution failure for pod %s/%s on node %s: %s", pod.Namespace, pod.Name, h.NodeName, message)
+	h.recorder.Eventf(pod, v1.EventTypeWarning, "DownwardAPIResolutionFailed", "%s", message)
+}
+
+// resolveFieldRef resolves a pod fieldRef the way the kubelet does at
+// container start.
+func resolveFieldRef(pod *v1.Pod, fs *v1.ObjectFieldSelector) error {
+	internalFieldPath, _, err := podshelper.ConvertDownwardAPIFieldLabel(fs.APIVersion, fs.FieldPath, "")
+	if err != nil {
+		return err
+	}
+	switch internalFieldPath {
+	case "spec.nodeName", "spec.serviceAccountName", "status.hostIP", "status.podIP", "status.podIPs":
+		// Resolved by the kubelet from runtime state; always available.
+		return nil
+	}
+	_, err = fieldpath.ExtractFieldPathAsString(pod, internalFieldPath)
+	return err
+}
+
+// resolveResourceFieldRef resolves a resourceFieldRef against the named
+// container, defaulting missing limits from the node's simulated allocatable
+// as the kubelet does.
+func resolveResourceFieldRef(pod *v1.Pod, fs *v1.ResourceFieldSelector, defaultContainerName string, allocatable v1.ResourceList) error {
+	containerName := fs.ContainerName
+	if containerName == "" {
+		containerName = defaultContainerName
+	}
+	var container *v1.Container
+	for i := range pod.Spec.InitContainers {
+		if pod.Spec.InitContainers[i].Name == containerName {
+			container = &pod.Spec.InitContainers[i]
+		}
+	}
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == containerName {
+			container = &pod.Spec.Containers[i]
+		}
+	}
+	if container == nil {
+		return fmt.Errorf("container %q not found", containerName)
+	}
+
+	defaulted := container.DeepCopy()
+	if defaulted.Resources.Limits == nil {
+		defaulted.Resources.Limits = v1.ResourceList{}
+	}
+	for name, quantity := range allocatable {
+		if _, exists := defaulted.Resources.Limits[name]; !exists {
+			defaulted.Resources.Limits[name] = quantity
+		}
+	}
+	resolved := *fs
+	resolved.ContainerName = defaulted.Name
+	_, err := apiv1resource.ExtractContainerResourceValue(&resolved, defaulted)
+	return err
+}
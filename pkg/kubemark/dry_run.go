@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// PlannedNode is one node of a dry-run fleet plan.
+type PlannedNode struct {
+	Name string
+	// Morph is the hollow component the node would run.
+	Morph string
+	// Profile names the node profile the shape came from, if any.
+	Profile  string
+	Capacity v1.ResourceList
+	// WritesPerSecond is the expected steady-state API write rate of the
+	// node: node status updates, lease renewals and simulated churn. Pod
+	// status traffic depends on the workload and is not included.
+	WritesPerSecond float64
+	// WatchStreams is the number of long-lived watch connections the node
+	// holds open against the apiserver.
+	WatchStreams int
+}
+
+// FleetPlan is the node inventory a kubemark invocation would simulate,
+// evaluated without connecting to a cluster so control planes can be sized
+// before a run.
+type FleetPlan struct {
+	Nodes []PlannedNode
+}
+
+// TotalCapacity sums the capacity of all planned nodes per resource.
+func (p *FleetPlan) TotalCapacity() v1.ResourceList {
+	total := v1.ResourceList{}
+	for _, node := range p.Nodes {
+		for name, quantity := range node.Capacity {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+	return total
+}
+
+// TotalWritesPerSecond sums the expected steady-state write rate of the fleet.
+func (p *FleetPlan) TotalWritesPerSecond() float64 {
+	total := 0.0
+	for _, node := range p.Nodes {
+		total += node.WritesPerSecond
+	}
+	return total
+}
+
+// TotalWatchStreams sums the watch connections the fleet would hold open.
+func (p *FleetPlan) TotalWatchStreams() int {
+	total := 0
+	for _, node := range p.Nodes {
+		total += node.WatchStreams
+	}
+	return total
+}
+
+// Print writes the plan in a human-readable form: the node inventory, the
+// total simulated capacity and the expected steady-state API load.
+func (p *FleetPlan) Print(w io.Writer) {
+	fmt.Fprintf(w, "Planned fleet: %d node(s)\n\n", len(p.Nodes))
+	for _, node := range p.Nodes {
+		fmt.Fprintf(w, "  %s (morph=%s", node.Name, node.Morph)
+		if node.Profile != "" {
+			fmt.Fprintf(w, ", profile=%s", node.Profile)
+		}
+		fmt.Fprintf(w, "): %s; %.2f writes/s, %d watch stream(s)\n",
+			formatResourceList(node.Capacity), node.WritesPerSecond, node.WatchStreams)
+	}
+	fmt.Fprintf(w, "\nTotal simulated capacity: %s\n", formatResourceList(p.TotalCapacity()))
+	fmt.Fprintf(w, "Expected steady-state API load: %.2f writes/s, %d watch stream(s)\n",
+		p.TotalWritesPerSecond(), p.TotalWatchStreams())
+	fmt.Fprintln(w, "(Pod status and event traffic depend on the workload and are not included.)")
+}
+
+// formatResourceList renders a ResourceList with stable resource ordering.
+func formatResourceList(resources v1.ResourceList) string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	out := ""
+	for i, name := range names {
+		if i > 0 {
+			out += ", "
+		}
+		quantity := resources[v1.ResourceName(name)]
+		out += fmt.Sprintf("%s=%s", name, quantity.String())
+	}
+	if out == "" {
+		return "no capacity"
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"sync/atomic"
+
+	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
+)
+
+// simulatedFsInodes is the inode capacity reported for the simulated
+// filesystem; plenty so inode eviction signals stay quiet.
+const simulatedFsInodes = uint64(50 * 1000 * 1000)
+
+// EphemeralStorageSimulator models the node's writable filesystem: a fixed
+// capacity with usage fed in by the container stats simulator as the
+// simulated writable layers of running containers grow. The fake cadvisor
+// serves it as the root filesystem, so ephemeral-storage scheduling and
+// nodefs eviction can be validated on kubemark clusters.
+type EphemeralStorageSimulator struct {
+	CapacityBytes int64
+	used          int64
+	// floor is a lower bound on reported usage, set by pressure scenarios
+	// to drive the node over its nodefs eviction threshold.
+	floor int64
+}
+
+// NewEphemeralStorageSimulator creates a simulator for a filesystem of the
+// given capacity.
+func NewEphemeralStorageSimulator(capacityBytes int64) *EphemeralStorageSimulator {
+	return &EphemeralStorageSimulator{CapacityBytes: capacityBytes}
+}
+
+// SetUsedBytes replaces the current node-wide usage.
+func (s *EphemeralStorageSimulator) SetUsedBytes(used int64) {
+	if used > s.CapacityBytes {
+		used = s.CapacityBytes
+	}
+	atomic.StoreInt64(&s.used, used)
+}
+
+// SetPressureFloorFraction floors reported usage at the given fraction of
+// capacity; 0 removes the floor.
+func (s *EphemeralStorageSimulator) SetPressureFloorFraction(fraction float64) {
+	atomic.StoreInt64(&s.floor, int64(fraction*float64(s.CapacityBytes)))
+}
+
+// UsedBytes returns the current node-wide usage.
+func (s *EphemeralStorageSimulator) UsedBytes() int64 {
+	used := atomic.LoadInt64(&s.used)
+	if floor := atomic.LoadInt64(&s.floor); used < floor {
+		used = floor
+	}
+	return used
+}
+
+// FsInfo returns the simulated filesystem in the cadvisor shape the summary
+// API reads for rootfs stats.
+func (s *EphemeralStorageSimulator) FsInfo() cadvisorapiv2.FsInfo {
+	used := uint64(s.UsedBytes())
+	inodes := simulatedFsInodes
+	inodesFree := simulatedFsInodes
+	return cadvisorapiv2.FsInfo{
+		Device:     "/dev/sda1",
+		Mountpoint: "/",
+		Capacity:   uint64(s.CapacityBytes),
+		Available:  uint64(s.CapacityBytes) - used,
+		Usage:      used,
+		Inodes:     &inodes,
+		InodesFree: &inodesFree,
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+)
+
+// ConfigureEventBroadcaster replaces the kubelet's default event recorder
+// with one whose client-side rate limits and correlator (aggregation and
+// spam filter) settings come from the node profile. It must be called
+// before the hollow kubelet runs; the kubelet's own recorder setup is a
+// no-op once KubeletDeps.Recorder is populated.
+func (hk *HollowKubelet) ConfigureEventBroadcaster(clientConfig *restclient.Config, nodeName string, events *NodeProfileEvents) error {
+	eventClientConfig := *clientConfig
+	if events.RecordQPS > 0 {
+		eventClientConfig.QPS = events.RecordQPS
+	}
+	if events.RecordBurst > 0 {
+		eventClientConfig.Burst = events.RecordBurst
+	}
+	eventClient, err := clientset.NewForConfig(&eventClientConfig)
+	if err != nil {
+		return fmt.Errorf("error while creating event client: %w", err)
+	}
+	// Zero-valued fields fall back to the correlator defaults, so a profile
+	// only needs to name the knobs it wants to change.
+	broadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		MaxEvents:            events.MaxEvents,
+		MaxIntervalInSeconds: int(events.AggregationInterval.Duration.Seconds()),
+		QPS:                  events.SpamQPS,
+		BurstSize:            events.SpamBurst,
+	})
+	broadcaster.StartStructuredLogging(3)
+	broadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: eventClient.CoreV1().Events("")})
+	hk.KubeletDeps.Recorder = broadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "kubelet", Host: nodeName})
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var taintEvictionLatency = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Subsystem:      "kubemark",
+		Name:           "taint_eviction_latency_seconds",
+		Help:           "Time from a hollow node turning NotReady to each of its pods receiving deletion, measuring node-lifecycle controller NoExecute eviction behavior during failure-injection experiments.",
+		Buckets:        metrics.ExponentialBuckets(1, 2, 12),
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"node"},
+)
+
+func init() {
+	legacyregistry.MustRegister(taintEvictionLatency)
+}
+
+// TaintEvictionObserver measures how long after this hollow node turns
+// NotReady its pods start being deleted by taint-based (NoExecute) eviction.
+type TaintEvictionObserver struct {
+	Client   clientset.Interface
+	NodeName string
+
+	lock          sync.Mutex
+	notReadySince time.Time
+	observed      map[string]bool
+}
+
+// NewTaintEvictionObserver creates an observer for the given hollow node.
+func NewTaintEvictionObserver(client clientset.Interface, nodeName string) *TaintEvictionObserver {
+	return &TaintEvictionObserver{
+		Client:   client,
+		NodeName: nodeName,
+		observed: make(map[string]bool),
+	}
+}
+
+// Run starts watching the node and its pods until stopCh is closed.
+func (o *TaintEvictionObserver) Run(stopCh <-chan struct{}) {
+	nodeLW := cache.NewListWatchFromClient(
+		o.Client.CoreV1().RESTClient(), "nodes", "",
+		fields.OneTermEqualSelector("metadata.name", o.NodeName))
+	_, nodeInformer := cache.NewInformer(nodeLW, &v1.Node{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { o.observeNode(obj) },
+		UpdateFunc: func(_, obj interface{}) { o.observeNode(obj) },
+	})
+	go nodeInformer.Run(stopCh)
+
+	podLW := cache.NewListWatchFromClient(
+		o.Client.CoreV1().RESTClient(), "pods", "",
+		fields.OneTermEqualSelector("spec.nodeName", o.NodeName))
+	_, podInformer := cache.NewInformer(podLW, &v1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { o.observePod(obj) },
+	})
+	go podInformer.Run(stopCh)
+}
+
+func (o *TaintEvictionObserver) observeNode(obj interface{}) {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return
+	}
+	ready := false
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+			ready = true
+		}
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if !ready && o.notReadySince.IsZero() {
+		o.notReadySince = time.Now()
+		o.observed = make(map[string]bool)
+		klog.V(2).Infof("Node %s turned NotReady, observing taint-based eviction", o.NodeName)
+	} else if ready && !o.notReadySince.IsZero() {
+		o.notReadySince = time.Time{}
+		klog.V(2).Infof("Node %s is Ready again, stopping eviction observation", o.NodeName)
+	}
+}
+
+func (o *TaintEvictionObserver) observePod(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok || pod.DeletionTimestamp == nil {
+		return
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.notReadySince.IsZero() {
+		return
+	}
+	key := pod.Namespace + "/" + pod.Name
+	if o.observed[key] {
+		return
+	}
+	o.observed[key] = true
+	taintEvictionLatency.WithLabelValues(o.NodeName).Observe(time.Since(o.notReadySince).Seconds())
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// PressureEpisode is one timed pressure event in a scenario.
+type PressureEpisode struct {
+	// StartAfter is when the episode begins, relative to node start.
+	StartAfter metav1.Duration `json:"startAfter"`
+	// Duration is how long the episode lasts.
+	Duration metav1.Duration `json:"duration"`
+	// MemoryFraction, when positive, floors node memory usage at this
+	// fraction of capacity for the episode.
+	MemoryFraction float64 `json:"memoryFraction,omitempty"`
+	// DiskFraction, when positive, floors node rootfs usage at this
+	// fraction of capacity for the episode.
+	DiskFraction float64 `json:"diskFraction,omitempty"`
+}
+
+// PressureScenario is a YAML schedule of memory/disk pressure episodes.
+// While an episode is active, node-level usage is floored at the configured
+// fractions, so crossing the kubelet's eviction thresholds makes the real
+// eviction manager set MemoryPressure/DiskPressure conditions and evict pods
+// in genuine priority/QoS order with Evicted statuses and events — the full
+// eviction path, at scale, without consuming real resources.
+//
+// Example:
+//
+//	episodes:
+//	- startAfter: 10m
+//	  duration: 5m
+//	  memoryFraction: 0.99
+//	- startAfter: 30m
+//	  duration: 10m
+//	  diskFraction: 0.95
+type PressureScenario struct {
+	Episodes []PressureEpisode `json:"episodes"`
+}
+
+// LoadPressureScenario reads and validates a pressure scenario from a YAML
+// file.
+func LoadPressureScenario(path string) (*PressureScenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading pressure scenario from %v: %w", path, err)
+	}
+	scenario := &PressureScenario{}
+	if err := yaml.UnmarshalStrict(data, scenario); err != nil {
+		return nil, fmt.Errorf("error while parsing pressure scenario %v: %w", path, err)
+	}
+	for i, episode := range scenario.Episodes {
+		if episode.Duration.Duration <= 0 {
+			return nil, fmt.Errorf("invalid pressure scenario %v: episode %d must set a positive duration", path, i)
+		}
+		for _, fraction := range []float64{episode.MemoryFraction, episode.DiskFraction} {
+			if fraction < 0 || fraction > 1 {
+				return nil, fmt.Errorf("invalid pressure scenario %v: episode %d fractions must be within [0, 1]", path, i)
+			}
+		}
+		if episode.MemoryFraction == 0 && episode.DiskFraction == 0 {
+			return nil, fmt.Errorf("invalid pressure scenario %v: episode %d must set memoryFraction or diskFraction", path, i)
+		}
+	}
+	return scenario, nil
+}
+
+// fractionsAt returns the memory and disk usage floors active at the given
+// offset from node start, taking the maximum over overlapping episodes.
+func (s *PressureScenario) fractionsAt(elapsed time.Duration) (memory, disk float64) {
+	for _, episode := range s.Episodes {
+		if elapsed < episode.StartAfter.Duration || elapsed >= episode.StartAfter.Duration+episode.Duration.Duration {
+			continue
+		}
+		if episode.MemoryFraction > memory {
+			memory = episode.MemoryFraction
+		}
+		if episode.DiskFraction > disk {
+			disk = episode.DiskFraction
+		}
+	}
+	return memory, disk
+}
+
+// PressureScenarioRunner applies a pressure scenario to the node's simulated
+// memory and rootfs usage.
+type PressureScenarioRunner struct {
+	Scenario *PressureScenario
+	// Memory receives the memory usage floor. Required for episodes with a
+	// memoryFraction.
+	Memory *SystemPressure
+	// Disk receives the rootfs usage floor. Required for episodes with a
+	// diskFraction.
+	Disk *EphemeralStorageSimulator
+}
+
+// Run applies the scenario every 10 seconds until stopCh is closed.
+func (r *PressureScenarioRunner) Run(stopCh <-chan struct{}) {
+	start := simClock.Now()
+	go func() {
+		ticker := simClock.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		active := false
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				memory, disk := r.Scenario.fractionsAt(simClock.Since(start))
+				if r.Memory != nil {
+					r.Memory.SetScenarioFraction(memory)
+				}
+				if r.Disk != nil {
+					r.Disk.SetPressureFloorFraction(disk)
+				}
+				if inEpisode := memory > 0 || disk > 0; inEpisode != active {
+					active = inEpisode
+					klog.Infof("Pressure scenario episode active=%v (memory=%.2f, disk=%.2f)", active, memory, disk)
+				}
+			}
+		}
+	}()
+}
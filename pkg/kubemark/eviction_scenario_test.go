@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestFractionsAt(t *testing.T) {
+	scenario := &PressureScenario{
+		Episodes: []PressureEpisode{
+			{
+				StartAfter:     metav1.Duration{Duration: 10 * time.Minute},
+				Duration:       metav1.Duration{Duration: 5 * time.Minute},
+				MemoryFraction: 0.99,
+			},
+			{
+				StartAfter:   metav1.Duration{Duration: 12 * time.Minute},
+				Duration:     metav1.Duration{Duration: 10 * time.Minute},
+				DiskFraction: 0.95,
+			},
+			{
+				StartAfter:     metav1.Duration{Duration: 12 * time.Minute},
+				Duration:       metav1.Duration{Duration: 1 * time.Minute},
+				MemoryFraction: 0.5,
+			},
+		},
+	}
+	tests := []struct {
+		elapsed      time.Duration
+		memory, disk float64
+	}{
+		{0, 0, 0},
+		{10 * time.Minute, 0.99, 0},
+		{13 * time.Minute, 0.99, 0.95}, // overlapping episodes take the max
+		{16 * time.Minute, 0, 0.95},
+		{22 * time.Minute, 0, 0},
+	}
+	for _, test := range tests {
+		memory, disk := scenario.fractionsAt(test.elapsed)
+		if memory != test.memory || disk != test.disk {
+			t.Errorf("fractionsAt(%v) = (%v, %v), want (%v, %v)", test.elapsed, memory, disk, test.memory, test.disk)
+		}
+	}
+}
+
+func writeScenarioFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write scenario file: %v", err)
+	}
+	return path
+}
+
+func TestLoadPressureScenario(t *testing.T) {
+	path := writeScenarioFile(t, `episodes:
+- startAfter: 10m
+  duration: 5m
+  memoryFraction: 0.99
+- startAfter: 30m
+  duration: 10m
+  diskFraction: 0.95
+`)
+	scenario, err := LoadPressureScenario(path)
+	if err != nil {
+		t.Fatalf("LoadPressureScenario returned unexpected error: %v", err)
+	}
+	if len(scenario.Episodes) != 2 {
+		t.Fatalf("loaded %d episodes, want 2", len(scenario.Episodes))
+	}
+	if scenario.Episodes[0].StartAfter.Duration != 10*time.Minute || scenario.Episodes[0].MemoryFraction != 0.99 {
+		t.Errorf("episode 0 loaded as %+v", scenario.Episodes[0])
+	}
+}
+
+func TestLoadPressureScenarioInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "missing duration",
+			content: `episodes:
+- startAfter: 10m
+  memoryFraction: 0.99
+`,
+		},
+		{
+			name: "fraction out of range",
+			content: `episodes:
+- duration: 5m
+  memoryFraction: 1.5
+`,
+		},
+		{
+			name: "no fraction set",
+			content: `episodes:
+- duration: 5m
+`,
+		},
+		{
+			name: "unknown field",
+			content: `episodes:
+- duration: 5m
+  memoryFraction: 0.9
+  surprise: true
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeScenarioFile(t, test.content)
+			if _, err := LoadPressureScenario(path); err == nil {
+				t.Error("LoadPressureScenario succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestPressureScenarioRunner(t *testing.T) {
+	fakeClock := withFakeClock(t)
+	scenario := &PressureScenario{
+		Episodes: []PressureEpisode{
+			{
+				StartAfter:     metav1.Duration{Duration: 10 * time.Second},
+				Duration:       metav1.Duration{Duration: 20 * time.Second},
+				MemoryFraction: 0.9,
+			},
+		},
+	}
+	memory := NewSystemPressure(PressureProfile{}, 1000)
+	runner := &PressureScenarioRunner{Scenario: scenario, Memory: memory}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	runner.Run(stopCh)
+
+	// Wait for the runner's ticker to exist before moving the clock.
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatalf("runner never started its ticker: %v", err)
+	}
+
+	// Step into the episode: the memory floor must reach 90% of capacity.
+	fakeClock.Step(10 * time.Second)
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return memory.MemoryUsage(fakeClock.Now()) == 900, nil
+	}); err != nil {
+		t.Fatalf("memory floor not applied during the episode: %v", err)
+	}
+
+	// Step past the episode: the floor must be removed again.
+	fakeClock.Step(20 * time.Second)
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return memory.MemoryUsage(fakeClock.Now()) == 0, nil
+	}); err != nil {
+		t.Fatalf("memory floor not removed after the episode: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ScrapeTargetGroup is one entry of a Prometheus file_sd JSON file: a set of
+// scrape targets sharing labels.
+type ScrapeTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// WriteScrapeTargets writes the given target groups as a Prometheus file_sd
+// JSON file named after the node, in the given directory. Simulator
+// processes sharing the directory (e.g. a mounted volume the Prometheus
+// file_sd watcher also reads) each contribute their own file, so monitoring
+// of thousands of synthetic metrics endpoints assembles itself without
+// per-node scrape configuration. The file is written atomically, as the
+// file_sd reader requires.
+func WriteScrapeTargets(dir, nodeName string, groups []ScrapeTargetGroup) error {
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding scrape targets for %v: %w", nodeName, err)
+	}
+	path := filepath.Join(dir, nodeName+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error while writing scrape targets to %v: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error while writing scrape targets to %v: %w", path, err)
+	}
+	return nil
+}
+
+// ScrapeTargetGroups builds the file_sd entries for the metrics endpoints a
+// hollow node serves on the given host, one group per endpoint so each can
+// be selected by its "endpoint" label. Zero ports are skipped.
+func ScrapeTargetGroups(host, nodeName, morph string, endpointPorts map[string]int, extraLabels map[string]string) []ScrapeTargetGroup {
+	var groups []ScrapeTargetGroup
+	for _, endpoint := range []string{"kubelet", "kubelet-readonly", "admin"} {
+		port := endpointPorts[endpoint]
+		if port <= 0 {
+			continue
+		}
+		labels := map[string]string{
+			"node":     nodeName,
+			"morph":    morph,
+			"endpoint": endpoint,
+		}
+		for name, value := range extraLabels {
+			labels[name] = value
+		}
+		groups = append(groups, ScrapeTargetGroup{
+			Targets: []string{net.JoinHostPort(host, strconv.Itoa(port))},
+			Labels:  labels,
+		})
+	}
+	return groups
+}
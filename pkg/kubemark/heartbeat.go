@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var heartbeatFailuresTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "heartbeat_failures_total",
+		Help:           "Number of failed node status and lease writes from this hollow node, partitioned by resource and HTTP response code. Failed writes are retried by the kubelet with the configured client backoff.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"resource", "code"},
+)
+
+func init() {
+	legacyregistry.MustRegister(heartbeatFailuresTotal)
+}
+
+// SetHeartbeatClientBackoff configures the client-go request backoff applied
+// after failed writes (such as node status and lease updates). client-go only
+// reads these settings from the environment, so this must be called before
+// any client is created.
+func SetHeartbeatClientBackoff(baseSeconds, maxDurationSeconds int) error {
+	if err := os.Setenv("KUBE_CLIENT_BACKOFF_BASE", strconv.Itoa(baseSeconds)); err != nil {
+		return fmt.Errorf("error while setting client backoff base: %w", err)
+	}
+	if err := os.Setenv("KUBE_CLIENT_BACKOFF_DURATION", strconv.Itoa(maxDurationSeconds)); err != nil {
+		return fmt.Errorf("error while setting client backoff duration: %w", err)
+	}
+	return nil
+}
+
+// heartbeatMetricsTransport counts failed node status and lease writes so
+// that recovery behavior after control-plane outages can be measured.
+type heartbeatMetricsTransport struct {
+	delegate http.RoundTripper
+}
+
+func (t *heartbeatMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.delegate.RoundTrip(req)
+	if req.Method == http.MethodGet {
+		return resp, err
+	}
+	var resource string
+	switch {
+	case strings.Contains(req.URL.Path, "/leases/"):
+		resource = "leases"
+		RecordLatency("lease_renew", time.Since(start))
+	case strings.HasSuffix(req.URL.Path, "/status") && strings.Contains(req.URL.Path, "/nodes/"):
+		resource = "nodestatus"
+		RecordLatency("node_status_write", time.Since(start))
+	default:
+		return resp, err
+	}
+	if err != nil {
+		heartbeatFailuresTotal.WithLabelValues(resource, "<error>").Inc()
+	} else if resp.StatusCode >= 400 {
+		heartbeatFailuresTotal.WithLabelValues(resource, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, err
+}
+
+// NewHeartbeatMetricsWrapper returns a RoundTripper wrapper for the heartbeat
+// client that exports retry-relevant failure counts for status/lease writes.
+func NewHeartbeatMetricsWrapper() func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &heartbeatMetricsTransport{delegate: rt}
+	}
+}
@@ -0,0 +1,403 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+var (
+	controllerSyncsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "kubemark",
+			Name:           "controller_syncs_total",
+			Help:           "Reconciliations run by the hollow controller-manager, by controller.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"controller"},
+	)
+	controllerWatchEventsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "kubemark",
+			Name:           "controller_watch_events_total",
+			Help:           "Watch events delivered to the hollow controller-manager's informers, by resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"resource"},
+	)
+	controllerDryRunWritesTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "kubemark",
+			Name:           "controller_dry_run_writes_total",
+			Help:           "Writes the hollow controller-manager issued with dryRun=All, by controller.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"controller"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(controllerSyncsTotal)
+	legacyregistry.MustRegister(controllerWatchEventsTotal)
+	legacyregistry.MustRegister(controllerDryRunWritesTotal)
+}
+
+// HollowControllerManager runs the read side of a controller-manager against
+// a kubemark cluster: the informers a real kube-controller-manager holds
+// open, plus the reconciliation computation for the chattiest controllers
+// (endpoints and replicaset). Every write it would issue goes to the
+// apiserver with dryRun=All, so admission and validation run but nothing is
+// persisted and the real controller-manager owning the cluster is not
+// interfered with. This isolates the watch-propagation and list/cache cost
+// of controllers at fleet scale.
+type HollowControllerManager struct {
+	Client clientset.Interface
+	// ResyncPeriod is the informer resync period, matching the real
+	// controller-manager's min-resync-period.
+	ResyncPeriod time.Duration
+	// Workers is the reconcile concurrency per controller.
+	Workers int
+
+	podLister        corelisters.PodLister
+	serviceLister    corelisters.ServiceLister
+	endpointsLister  corelisters.EndpointsLister
+	replicaSetLister appslisters.ReplicaSetLister
+
+	endpointsQueue  workqueue.RateLimitingInterface
+	replicaSetQueue workqueue.RateLimitingInterface
+}
+
+// NewHollowControllerManager creates a hollow controller-manager using the
+// given client.
+func NewHollowControllerManager(client clientset.Interface, resyncPeriod time.Duration, workers int) *HollowControllerManager {
+	if workers < 1 {
+		workers = 1
+	}
+	return &HollowControllerManager{
+		Client:          client,
+		ResyncPeriod:    resyncPeriod,
+		Workers:         workers,
+		endpointsQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "hollow-endpoints"),
+		replicaSetQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "hollow-replicaset"),
+	}
+}
+
+// Run starts the informers and reconcile workers and blocks until stopCh
+// is closed.
+func (cm *HollowControllerManager) Run(stopCh <-chan struct{}) error {
+	defer cm.endpointsQueue.ShutDown()
+	defer cm.replicaSetQueue.ShutDown()
+	factory := informers.NewSharedInformerFactory(cm.Client, cm.ResyncPeriod)
+
+	cm.podLister = factory.Core().V1().Pods().Lister()
+	cm.serviceLister = factory.Core().V1().Services().Lister()
+	cm.endpointsLister = factory.Core().V1().Endpoints().Lister()
+	cm.replicaSetLister = factory.Apps().V1().ReplicaSets().Lister()
+
+	factory.Core().V1().Services().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("services").Inc()
+			cm.enqueue(cm.endpointsQueue, obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("services").Inc()
+			cm.enqueue(cm.endpointsQueue, obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("services").Inc()
+		},
+	})
+	factory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("pods").Inc()
+			cm.enqueueForPod(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("pods").Inc()
+			cm.enqueueForPod(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("pods").Inc()
+		},
+	})
+	factory.Apps().V1().ReplicaSets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("replicasets").Inc()
+			cm.enqueue(cm.replicaSetQueue, obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("replicasets").Inc()
+			cm.enqueue(cm.replicaSetQueue, obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			controllerWatchEventsTotal.WithLabelValues("replicasets").Inc()
+		},
+	})
+
+	// Watch-only informers for the remaining resources a real
+	// controller-manager keeps in its caches, so its steady-state watch
+	// footprint is represented even where no reconciler runs here.
+	watchOnly := map[string]cache.SharedIndexInformer{
+		"endpoints":    factory.Core().V1().Endpoints().Informer(),
+		"nodes":        factory.Core().V1().Nodes().Informer(),
+		"namespaces":   factory.Core().V1().Namespaces().Informer(),
+		"deployments":  factory.Apps().V1().Deployments().Informer(),
+		"daemonsets":   factory.Apps().V1().DaemonSets().Informer(),
+		"statefulsets": factory.Apps().V1().StatefulSets().Informer(),
+	}
+	for resource, informer := range watchOnly {
+		resource := resource
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { controllerWatchEventsTotal.WithLabelValues(resource).Inc() },
+			UpdateFunc: func(_, obj interface{}) { controllerWatchEventsTotal.WithLabelValues(resource).Inc() },
+			DeleteFunc: func(obj interface{}) { controllerWatchEventsTotal.WithLabelValues(resource).Inc() },
+		})
+	}
+
+	factory.Start(stopCh)
+	for informerType, synced := range factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("timed out waiting for %v cache to sync", informerType)
+		}
+	}
+	klog.Infof("Hollow controller-manager caches synced, starting %d worker(s) per controller", cm.Workers)
+
+	for i := 0; i < cm.Workers; i++ {
+		go cm.runWorker(cm.endpointsQueue, "endpoints", cm.reconcileEndpoints)
+		go cm.runWorker(cm.replicaSetQueue, "replicaset", cm.reconcileReplicaSet)
+	}
+
+	<-stopCh
+	return nil
+}
+
+// enqueue adds the object's namespace/name key to the queue.
+func (cm *HollowControllerManager) enqueue(queue workqueue.RateLimitingInterface, obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	queue.Add(key)
+}
+
+// enqueueForPod routes a pod event to the controllers interested in it: the
+// services whose selectors match the pod, and the ReplicaSet owning it.
+func (cm *HollowControllerManager) enqueueForPod(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return
+	}
+	if services, err := cm.serviceLister.Services(pod.Namespace).List(labels.Everything()); err == nil {
+		for _, service := range services {
+			if len(service.Spec.Selector) == 0 {
+				continue
+			}
+			if labels.Set(service.Spec.Selector).AsSelectorPreValidated().Matches(labels.Set(pod.Labels)) {
+				cm.endpointsQueue.Add(pod.Namespace + "/" + service.Name)
+			}
+		}
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "ReplicaSet" {
+		cm.replicaSetQueue.Add(pod.Namespace + "/" + owner.Name)
+	}
+}
+
+// runWorker drains the queue until it shuts down, retrying failed keys with
+// backoff.
+func (cm *HollowControllerManager) runWorker(queue workqueue.RateLimitingInterface, controller string, reconcile func(string) error) {
+	for {
+		key, quit := queue.Get()
+		if quit {
+			return
+		}
+		controllerSyncsTotal.WithLabelValues(controller).Inc()
+		err := reconcile(key.(string))
+		if err != nil {
+			klog.V(3).Infof("Hollow %s controller failed to sync %q: %v", controller, key, err)
+			queue.AddRateLimited(key)
+		} else {
+			queue.Forget(key)
+		}
+		queue.Done(key)
+	}
+}
+
+// reconcileEndpoints recomputes the endpoints of a service from the pod
+// cache, the way the real endpoints controller does, and issues the
+// resulting write with dryRun=All.
+func (cm *HollowControllerManager) reconcileEndpoints(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	service, err := cm.serviceLister.Services(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(service.Spec.Selector) == 0 {
+		// Headless-without-selector and ExternalName services are not the
+		// endpoints controller's to manage.
+		return nil
+	}
+
+	pods, err := cm.podLister.Pods(namespace).List(labels.Set(service.Spec.Selector).AsSelectorPreValidated())
+	if err != nil {
+		return err
+	}
+	subset := v1.EndpointSubset{}
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		address := v1.EndpointAddress{IP: pod.Status.PodIP, NodeName: &pod.Spec.NodeName}
+		if podutil.IsPodReady(pod) {
+			subset.Addresses = append(subset.Addresses, address)
+		} else {
+			subset.NotReadyAddresses = append(subset.NotReadyAddresses, address)
+		}
+	}
+	for i := range service.Spec.Ports {
+		svcPort := &service.Spec.Ports[i]
+		portNum := svcPort.TargetPort.IntValue()
+		if svcPort.TargetPort.Type == intstr.String {
+			// Resolve named targetPorts against the pods' container ports,
+			// the way the real controller does. An unresolvable name is
+			// skipped: a 0 port would fail apiserver validation even under
+			// dryRun=All and requeue the service forever.
+			portNum = 0
+			for _, pod := range pods {
+				if n, err := podutil.FindPort(pod, svcPort); err == nil {
+					portNum = n
+					break
+				}
+			}
+			if portNum == 0 {
+				continue
+			}
+		}
+		subset.Ports = append(subset.Ports, v1.EndpointPort{
+			Name:     svcPort.Name,
+			Port:     int32(portNum),
+			Protocol: svcPort.Protocol,
+		})
+	}
+	desired := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: service.Labels},
+	}
+	if len(subset.Addresses) > 0 || len(subset.NotReadyAddresses) > 0 {
+		desired.Subsets = []v1.EndpointSubset{subset}
+	}
+
+	ctx := context.TODO()
+	existing, err := cm.endpointsLister.Endpoints(namespace).Get(name)
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = cm.Client.CoreV1().Endpoints(namespace).Create(ctx, desired, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	case err == nil:
+		desired.ResourceVersion = existing.ResourceVersion
+		_, err = cm.Client.CoreV1().Endpoints(namespace).Update(ctx, desired, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	default:
+		return err
+	}
+	if apierrors.IsConflict(err) {
+		// The real controller-manager updated the object under us; the next
+		// watch event re-enqueues the service.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	controllerDryRunWritesTotal.WithLabelValues("endpoints").Inc()
+	return nil
+}
+
+// reconcileReplicaSet recomputes a ReplicaSet's status from the pod cache
+// and issues the status write with dryRun=All.
+func (cm *HollowControllerManager) reconcileReplicaSet(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	rs, err := cm.replicaSetLister.ReplicaSets(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	if err != nil {
+		return err
+	}
+	pods, err := cm.podLister.Pods(namespace).List(selector)
+	if err != nil {
+		return err
+	}
+
+	var active, ready int32
+	for _, pod := range pods {
+		if metav1.GetControllerOf(pod) == nil || metav1.GetControllerOf(pod).UID != rs.UID {
+			continue
+		}
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		active++
+		if podutil.IsPodReady(pod) {
+			ready++
+		}
+	}
+
+	updated := rs.DeepCopy()
+	updated.Status = appsv1.ReplicaSetStatus{
+		Replicas:           active,
+		ReadyReplicas:      ready,
+		AvailableReplicas:  ready,
+		ObservedGeneration: rs.Generation,
+	}
+	_, err = cm.Client.AppsV1().ReplicaSets(namespace).UpdateStatus(context.TODO(), updated, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	if apierrors.IsConflict(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	controllerDryRunWritesTotal.WithLabelValues("replicaset").Inc()
+	return nil
+}
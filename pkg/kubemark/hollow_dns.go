@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var dnsConfigSyncsTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "node_dns_config_syncs_total",
+		Help:           "Number of node-local DNS config and upstream service sync events observed by this hollow node-dns instance.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	legacyregistry.MustRegister(dnsConfigSyncsTotal)
+}
+
+// HollowNodeDNS is a stub of the node-local DNS cache. It does not serve DNS,
+// but it consumes the same apiserver resources a real node-local-dns instance
+// does (its ConfigMap and the cluster DNS service), so rollout load patterns
+// of node-local-dns are included in full-fidelity cluster simulations.
+type HollowNodeDNS struct {
+	NodeName           string
+	Client             clientset.Interface
+	ConfigMapNamespace string
+	ConfigMapName      string
+	MetricsPort        int
+}
+
+// NewHollowNodeDNS creates a stub node-local DNS cache for the given node.
+func NewHollowNodeDNS(client clientset.Interface, nodeName, configMapNamespace, configMapName string, metricsPort int) *HollowNodeDNS {
+	return &HollowNodeDNS{
+		NodeName:           nodeName,
+		Client:             client,
+		ConfigMapNamespace: configMapNamespace,
+		ConfigMapName:      configMapName,
+		MetricsPort:        metricsPort,
+	}
+}
+
+// Run starts the stub DNS cache and blocks.
+func (d *HollowNodeDNS) Run() error {
+	stopCh := make(chan struct{})
+
+	// Watch the node-local-dns ConfigMap the way the real component does.
+	configMapLW := cache.NewListWatchFromClient(
+		d.Client.CoreV1().RESTClient(), "configmaps", d.ConfigMapNamespace,
+		fields.OneTermEqualSelector("metadata.name", d.ConfigMapName))
+	_, configMapInformer := cache.NewInformer(configMapLW, &v1.ConfigMap{}, 5*time.Minute, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { dnsConfigSyncsTotal.WithLabelValues("configmap").Inc() },
+		UpdateFunc: func(_, obj interface{}) { dnsConfigSyncsTotal.WithLabelValues("configmap").Inc() },
+	})
+	go configMapInformer.Run(stopCh)
+
+	// Watch the cluster DNS service endpoints to pick up upstream changes.
+	endpointsLW := cache.NewListWatchFromClient(
+		d.Client.CoreV1().RESTClient(), "endpoints", "kube-system",
+		fields.OneTermEqualSelector("metadata.name", "kube-dns"))
+	_, endpointsInformer := cache.NewInformer(endpointsLW, &v1.Endpoints{}, 5*time.Minute, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { dnsConfigSyncsTotal.WithLabelValues("endpoints").Inc() },
+		UpdateFunc: func(_, obj interface{}) { dnsConfigSyncsTotal.WithLabelValues("endpoints").Inc() },
+	})
+	go endpointsInformer.Run(stopCh)
+
+	klog.Infof("Hollow node-dns for node %s watching %s/%s", d.NodeName, d.ConfigMapNamespace, d.ConfigMapName)
+
+	// Expose metrics the way node-local-dns does, so scrape configs work.
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", legacyregistry.Handler())
+	return http.ListenAndServe(fmt.Sprintf(":%d", d.MetricsPort), mux)
+}
@@ -18,6 +18,9 @@ package kubemark
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -26,11 +29,14 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/component-base/configz"
 	internalapi "k8s.io/cri-api/pkg/apis"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
 	kubeletapp "k8s.io/kubernetes/cmd/kubelet/app"
 	"k8s.io/kubernetes/cmd/kubelet/app/options"
 	"k8s.io/kubernetes/pkg/kubelet"
 	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+	kubeletscheme "k8s.io/kubernetes/pkg/kubelet/apis/config/scheme"
 	"k8s.io/kubernetes/pkg/kubelet/cadvisor"
 	"k8s.io/kubernetes/pkg/kubelet/cm"
 	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
@@ -102,8 +108,6 @@ func NewHollowKubelet(
 	runtimeService internalapi.RuntimeService,
 	containerManager cm.ContainerManager) *HollowKubelet {
 	d := &kubelet.Dependencies{
-		KubeClient:           client,
-		HeartbeatClient:      heartbeatClient,
 		ProbeManager:         probetest.FakeManager{},
 		RemoteRuntimeService: runtimeService,
 		RemoteImageService:   imageService,
@@ -118,6 +122,15 @@ func NewHollowKubelet(
 		Subpather:            &subpath.FakeSubpath{},
 		HostUtil:             hostutil.NewFakeHostUtil(nil),
 	}
+	// Leave the clients nil in standalone mode so that the kubelet does not
+	// try to talk to an apiserver. Assigning a nil *Clientset directly would
+	// produce a non-nil interface and defeat the kubelet's standalone checks.
+	if client != nil {
+		d.KubeClient = client
+	}
+	if heartbeatClient != nil {
+		d.HeartbeatClient = heartbeatClient
+	}
 
 	return &HollowKubelet{
 		KubeletFlags:         flags,
@@ -126,8 +139,137 @@ func NewHollowKubelet(
 	}
 }
 
+// EnablePriorityAdmission installs priority-aware admission ordering for
+// when this hollow node is saturated, so higher-priority pods preempt
+// lower-priority ones at the kubelet layer too.
+func (hk *HollowKubelet) EnablePriorityAdmission(client *clientset.Clientset, nodeName string, maxPods int) {
+	// As in NewHollowKubelet, avoid turning a nil *Clientset into a non-nil
+	// interface in standalone mode.
+	var evictionClient clientset.Interface
+	if client != nil {
+		evictionClient = client
+	}
+	hk.KubeletDeps.Options = append(hk.KubeletDeps.Options, func(klet *kubelet.Kubelet) {
+		klet.AddPodAdmitHandler(NewPriorityAdmissionHandler(evictionClient, nodeName, maxPods, klet.GetActivePods))
+	})
+}
+
+// EnforceMaxPods installs plain max-pods capacity enforcement at admission:
+// once the node holds maxPods active pods, further pods are rejected with
+// OutOfpods regardless of priority, as on a real kubelet.
+func (hk *HollowKubelet) EnforceMaxPods(maxPods int) {
+	hk.KubeletDeps.Options = append(hk.KubeletDeps.Options, func(klet *kubelet.Kubelet) {
+		klet.AddPodAdmitHandler(NewMaxPodsAdmissionHandler(maxPods, klet.GetActivePods))
+	})
+}
+
+// EnableAffinityValidation installs the strict-admission affinity validation
+// pass, which reports scheduler affinity mismatches as pod events.
+func (hk *HollowKubelet) EnableAffinityValidation(client *clientset.Clientset, nodeName string) {
+	if client == nil {
+		return
+	}
+	hk.KubeletDeps.Options = append(hk.KubeletDeps.Options, func(klet *kubelet.Kubelet) {
+		klet.AddPodAdmitHandler(NewAffinityValidationHandler(client, nodeName))
+	})
+}
+
+// EnableHostPortAdmission installs hostPort conflict detection at admission,
+// rejecting pods whose hostPorts collide with a pod already on this node.
+func (hk *HollowKubelet) EnableHostPortAdmission(nodeName string) {
+	hk.KubeletDeps.Options = append(hk.KubeletDeps.Options, func(klet *kubelet.Kubelet) {
+		klet.AddPodAdmitHandler(NewHostPortAdmissionHandler(nodeName, klet.GetActivePods))
+	})
+}
+
+// EnableDownwardAPIValidation installs the downward API validation pass,
+// which resolves every downward API env var and volume item at admission
+// and reports failures as pod events.
+func (hk *HollowKubelet) EnableDownwardAPIValidation(client *clientset.Clientset, nodeName string) {
+	if client == nil {
+		return
+	}
+	hk.KubeletDeps.Options = append(hk.KubeletDeps.Options, func(klet *kubelet.Kubelet) {
+		klet.AddPodAdmitHandler(NewDownwardAPIValidationHandler(client, nodeName))
+	})
+}
+
+// EnableBindingLatencyObservation installs the binding-to-watch latency
+// observer, which records how long each pod took from Binding creation to
+// first arriving on this kubelet's watch.
+func (hk *HollowKubelet) EnableBindingLatencyObservation() {
+	hk.KubeletDeps.Options = append(hk.KubeletDeps.Options, func(klet *kubelet.Kubelet) {
+		klet.AddPodAdmitHandler(NewBindingLatencyObserver())
+	})
+}
+
+// InjectStaticPods copies pod manifests from the given directory into the
+// static pod path of this HollowKubelet. It is used by the standalone mode
+// to drive the full pod lifecycle pipeline without an apiserver, so that
+// the simulator's own throughput and per-pod overhead can be benchmarked
+// in isolation.
+func (hk *HollowKubelet) InjectStaticPods(manifestDir string) error {
+	entries, err := os.ReadDir(manifestDir)
+	if err != nil {
+		return fmt.Errorf("error while reading pod manifests from %v: %w", manifestDir, err)
+	}
+	injected := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(manifestDir, name))
+		if err != nil {
+			return fmt.Errorf("error while reading pod manifest %v: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(hk.KubeletConfiguration.StaticPodPath, name), data, 0640); err != nil {
+			return fmt.Errorf("error while injecting pod manifest %v: %w", name, err)
+		}
+		injected++
+	}
+	klog.Infof("Injected %d local pod manifests from %s", injected, manifestDir)
+	return nil
+}
+
+// registerConfigz publishes the effective kubelet configuration in the
+// configz registry, which the kubelet server exposes as /configz. The real
+// kubelet does this in its command wiring, which the hollow kubelet
+// bypasses; registering here keeps fleet-config auditing tools working
+// unchanged against kubemark nodes.
+func (hk *HollowKubelet) registerConfigz() {
+	cz, err := configz.New("kubeletconfig")
+	if err != nil {
+		klog.Errorf("Failed to register configz: %v", err)
+		return
+	}
+	scheme, _, err := kubeletscheme.NewSchemeAndCodecs()
+	if err != nil {
+		klog.Errorf("Failed to create kubelet config scheme: %v", err)
+		return
+	}
+	versioned := kubeletconfigv1beta1.KubeletConfiguration{}
+	if err := scheme.Convert(hk.KubeletConfiguration, &versioned, nil); err != nil {
+		klog.Errorf("Failed to convert kubelet configuration for configz: %v", err)
+		return
+	}
+	cz.Set(versioned)
+}
+
 // Starts this HollowKubelet and blocks.
 func (hk *HollowKubelet) Run() {
+	NotifyNodeStart(hk.KubeletFlags.HostnameOverride)
+	defer NotifyNodeShutdown(hk.KubeletFlags.HostnameOverride)
+	hk.registerConfigz()
+	// The kubelet serves the podresources gRPC API under its root directory,
+	// which is per-node for hollow kubelets; log the socket so monitoring
+	// agents and GPU operators can be pointed at it.
+	klog.Infof("Serving podresources API on %s",
+		filepath.Join(hk.KubeletFlags.RootDirectory, "pod-resources", "kubelet.sock"))
 	if err := kubeletapp.RunKubelet(&options.KubeletServer{
 		KubeletFlags:         *hk.KubeletFlags,
 		KubeletConfiguration: *hk.KubeletConfiguration,
@@ -150,13 +292,43 @@ type HollowKubletOptions struct {
 	NodeStatusReportFrequency int
 	NodeLeaseDurationSeconds  int
 	PodCIDR                   string
+	RootDirBase               string
+	// NodeIP, when set, is the address this node registers in its status
+	// instead of the host's default interface address. Hollow nodes running
+	// as pods register their pod IP here, so the apiserver's
+	// egress/konnectivity path reaches each node's serving endpoints
+	// (exec, logs) through the cluster network rather than all nodes
+	// appearing at the shared host address.
+	NodeIP string
+	// ConfigMapSecretStrategy selects how the hollow kubelet's secret and
+	// configmap managers talk to the apiserver ("Watch", "Cache" or "Get").
+	// The hollow kubelet mounts real secret/configmap volume plugins, so the
+	// chosen strategy generates the same GET/WATCH traffic a real kubelet
+	// would and secret/configmap propagation load can be measured at scale.
+	// Empty keeps the kubelet default (Watch).
+	ConfigMapSecretStrategy string
 }
 
 // Builds a KubeletConfiguration for the HollowKubelet, ensuring that the
 // usual defaults are applied for fields we do not override.
 func GetHollowKubeletConfig(opt *HollowKubletOptions) (*options.KubeletFlags, *kubeletconfig.KubeletConfiguration) {
-	testRootDir := utils.MakeTempDirOrDie("hollow-kubelet.", "")
-	podFilePath := utils.MakeTempDirOrDie("static-pods", testRootDir)
+	// Each hollow node gets a root directory isolated by node name, so that
+	// several nodes in one host/process do not collide on pods dirs, plugin
+	// sockets or checkpoints. Without a configured base we fall back to a
+	// unique temporary directory.
+	var testRootDir string
+	if opt.RootDirBase != "" {
+		testRootDir = filepath.Join(opt.RootDirBase, opt.NodeName)
+		if err := os.MkdirAll(testRootDir, 0750); err != nil {
+			klog.Fatalf("Failed to create root dir %s for hollow-kubelet: %v", testRootDir, err)
+		}
+	} else {
+		testRootDir = utils.MakeTempDirOrDie("hollow-kubelet.", "")
+	}
+	podFilePath := filepath.Join(testRootDir, "static-pods")
+	if err := os.MkdirAll(podFilePath, 0750); err != nil {
+		klog.Fatalf("Failed to create static pod dir %s for hollow-kubelet: %v", podFilePath, err)
+	}
 	klog.Infof("Using %s as root dir for hollow-kubelet", testRootDir)
 
 	// Flags struct
@@ -169,6 +341,7 @@ func GetHollowKubeletConfig(opt *HollowKubletOptions) (*options.KubeletFlags, *k
 	f.NodeLabels = opt.NodeLabels
 	f.RegisterSchedulable = true
 	f.RemoteImageEndpoint = "unix:///run/containerd/containerd.sock"
+	f.NodeIP = opt.NodeIP
 
 	// Config struct
 	c, err := options.NewKubeletConfiguration()
@@ -214,6 +387,9 @@ func GetHollowKubeletConfig(opt *HollowKubletOptions) (*options.KubeletFlags, *k
 	c.ProtectKernelDefaults = false
 	c.RegisterWithTaints = opt.RegisterWithTaints
 	c.RegisterNode = true
+	if opt.ConfigMapSecretStrategy != "" {
+		c.ConfigMapAndSecretChangeDetectionStrategy = kubeletconfig.ResourceChangeDetectionStrategy(opt.ConfigMapSecretStrategy)
+	}
 
 	// node status report
 	c.NodeStatusUpdateFrequency.Duration = time.Duration(opt.NodeStatusUpdateFrequency) * time.Second
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kubernetes/pkg/kubemark/lifecycle"
+)
+
+// LifecycleHook receives callbacks on hollow node lifecycle and simulated pod
+// transitions. Downstream users register implementations at build time (from
+// an init function in their own package) to extend simulation behavior
+// without forking pkg/kubemark. Implementations must be fast and must not
+// block; long-running work should be handed off to a goroutine.
+type LifecycleHook interface {
+	// Name identifies the hook in logs.
+	Name() string
+	// OnNodeStart is called just before a hollow node starts running.
+	OnNodeStart(nodeName string)
+	// OnNodeShutdown is called when a hollow node begins shutting down.
+	OnNodeShutdown(nodeName string)
+	// OnPodTransition is called when a simulated pod changes phase.
+	OnPodTransition(pod *v1.Pod, phase v1.PodPhase)
+}
+
+var (
+	lifecycleHookLock sync.RWMutex
+	lifecycleHooks    []LifecycleHook
+)
+
+// RegisterLifecycleHook adds a hook to the registry. It is intended to be
+// called from init functions, before any hollow component starts.
+func RegisterLifecycleHook(hook LifecycleHook) {
+	lifecycleHookLock.Lock()
+	defer lifecycleHookLock.Unlock()
+	lifecycleHooks = append(lifecycleHooks, hook)
+	klog.V(2).Infof("Registered kubemark lifecycle hook %q", hook.Name())
+}
+
+// NotifyNodeStart invokes OnNodeStart on all registered hooks.
+func NotifyNodeStart(nodeName string) {
+	lifecycleHookLock.RLock()
+	defer lifecycleHookLock.RUnlock()
+	for _, hook := range lifecycleHooks {
+		hook.OnNodeStart(nodeName)
+	}
+}
+
+// NotifyNodeShutdown invokes OnNodeShutdown on all registered hooks.
+func NotifyNodeShutdown(nodeName string) {
+	lifecycleHookLock.RLock()
+	defer lifecycleHookLock.RUnlock()
+	for _, hook := range lifecycleHooks {
+		hook.OnNodeShutdown(nodeName)
+	}
+}
+
+// podLifecycle is the process-wide pod lifecycle state machine. Registered
+// LifecycleHooks are wired in as observers, so transitions routed through
+// the machine still reach them.
+var podLifecycle = func() *lifecycle.Machine {
+	m := lifecycle.NewMachine()
+	m.AddObserver(lifecycle.ObserverFunc(func(pod *v1.Pod, from, to v1.PodPhase) {
+		lifecycleHookLock.RLock()
+		defer lifecycleHookLock.RUnlock()
+		for _, hook := range lifecycleHooks {
+			hook.OnPodTransition(pod, to)
+		}
+	}))
+	return m
+}()
+
+// PodLifecycle returns the process-wide pod lifecycle state machine.
+// Simulators use it to record the transitions they drive, and extensions can
+// attach their own lifecycle.Deciders and Observers to it.
+func PodLifecycle() *lifecycle.Machine {
+	return podLifecycle
+}
+
+// NotifyPodTransition records the transition in the pod lifecycle state
+// machine, which notifies all registered hooks. Transitions the machine
+// rejects are logged and otherwise ignored; callers that need the verdict
+// (or the requested delay) should use PodLifecycle().Transition directly.
+func NotifyPodTransition(pod *v1.Pod, phase v1.PodPhase) {
+	if _, _, err := podLifecycle.Transition(pod, phase); err != nil {
+		klog.V(2).Infof("Pod transition not recorded: %v", err)
+	}
+}
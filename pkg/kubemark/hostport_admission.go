@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/nodeports"
+)
+
+// HostPortAdmissionHandler rejects pods whose hostPorts collide with a pod
+// already on this hollow node, using the same NodePorts predicate and
+// failure reason as the scheduler and a real kubelet. Hollow kubelets
+// otherwise admit everything, so a scheduler whose hostPort predicate has
+// drifted double-books ports silently in kubemark runs; with this handler
+// the second pod fails admission and the drift shows up as pod failures.
+type HostPortAdmissionHandler struct {
+	NodeName      string
+	GetActivePods func() []*v1.Pod
+}
+
+var _ lifecycle.PodAdmitHandler = &HostPortAdmissionHandler{}
+
+// NewHostPortAdmissionHandler creates the handler for the given node.
+func NewHostPortAdmissionHandler(nodeName string, getActivePods func() []*v1.Pod) *HostPortAdmissionHandler {
+	return &HostPortAdmissionHandler{
+		NodeName:      nodeName,
+		GetActivePods: getActivePods,
+	}
+}
+
+// Admit implements lifecycle.PodAdmitHandler.
+func (h *HostPortAdmissionHandler) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	active := attrs.OtherPods
+	if active == nil && h.GetActivePods != nil {
+		active = h.GetActivePods()
+	}
+	if nodeports.Fits(attrs.Pod, schedulerframework.NewNodeInfo(active...)) {
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+	return lifecycle.PodAdmitResult{
+		Admit:   false,
+		Reason:  nodeports.Name,
+		Message: nodeports.ErrReason,
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"math/rand"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+)
+
+// PopulateImageCatalog fills the fake image service with a synthetic image
+// catalog of the given size. Image sizes are spread uniformly around
+// meanSizeMB (±spreadMB) with a fixed seed, so every hollow node reports the
+// same catalog and image-locality scheduling sees consistent
+// node.status.images across the fleet.
+func PopulateImageCatalog(imageService *apitest.FakeImageService, count, meanSizeMB, spreadMB int) {
+	sizes := rand.New(rand.NewSource(0))
+	images := make(map[string]*runtimeapi.Image, count)
+	for i := 0; i < count; i++ {
+		sizeMB := int64(meanSizeMB)
+		if spreadMB > 0 {
+			sizeMB += sizes.Int63n(int64(2*spreadMB)) - int64(spreadMB)
+		}
+		if sizeMB < 1 {
+			sizeMB = 1
+		}
+		name := fmt.Sprintf("registry.k8s.io/kubemark/synthetic-image-%04d:v%d", i, i%10)
+		spec := &runtimeapi.ImageSpec{Image: name, Annotations: make(map[string]string)}
+		images[name] = &runtimeapi.Image{
+			Id:       name,
+			Size_:    uint64(sizeMB) * 1024 * 1024,
+			Spec:     spec,
+			RepoTags: []string{name},
+		}
+	}
+
+	imageService.Lock()
+	defer imageService.Unlock()
+	imageService.Images = images
+}
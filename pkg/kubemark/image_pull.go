@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	internalapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+)
+
+var imagePullsTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "simulated_image_pulls_total",
+		Help:           "Image pulls through the pull simulation, by outcome (first, cached or failed).",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"outcome"},
+)
+
+func init() {
+	legacyregistry.MustRegister(imagePullsTotal)
+}
+
+// simulatedImageService makes image pulls behave like a real node's: the
+// first pull of an image takes the configured pull latency, subsequent
+// pulls of a cached image return quickly, and pulls from registries with a
+// configured failure rate fail randomly. Failed pulls surface through the
+// real kubelet as ErrImagePull and then ImagePullBackOff, so rollout timing
+// and imagePullPolicy interactions are realistic in kubemark runs.
+type simulatedImageService struct {
+	internalapi.ImageManagerService
+	firstPullLatency  time.Duration
+	cachedPullLatency time.Duration
+	// failureRates maps a registry host (e.g. "registry.k8s.io") to the
+	// probability of a pull from it failing.
+	failureRates map[string]float64
+
+	lock   sync.Mutex
+	pulled map[string]bool
+}
+
+// NewSimulatedImageService wraps an image service with pull latency and
+// per-registry failure simulation.
+func NewSimulatedImageService(delegate internalapi.ImageManagerService, firstPullLatency, cachedPullLatency time.Duration, failureRates map[string]float64) internalapi.ImageManagerService {
+	return &simulatedImageService{
+		ImageManagerService: delegate,
+		firstPullLatency:    firstPullLatency,
+		cachedPullLatency:   cachedPullLatency,
+		failureRates:        failureRates,
+		pulled:              map[string]bool{},
+	}
+}
+
+// imageRegistry extracts the registry host from an image reference,
+// defaulting to docker.io for bare references.
+func imageRegistry(image string) string {
+	first := strings.SplitN(image, "/", 2)[0]
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return first
+	}
+	return "docker.io"
+}
+
+// PullImage delays, fails or caches the pull before delegating.
+func (s *simulatedImageService) PullImage(image *runtimeapi.ImageSpec, auth *runtimeapi.AuthConfig, podSandboxConfig *runtimeapi.PodSandboxConfig) (string, error) {
+	registry := imageRegistry(image.Image)
+	if rate := s.failureRates[registry]; rate > 0 && rand.Float64() < rate {
+		imagePullsTotal.WithLabelValues("failed").Inc()
+		klog.V(3).Infof("Simulated pull failure of image %s from registry %s", image.Image, registry)
+		return "", fmt.Errorf("simulated pull failure: registry %s did not serve image %s", registry, image.Image)
+	}
+
+	s.lock.Lock()
+	cached := s.pulled[image.Image]
+	s.pulled[image.Image] = true
+	s.lock.Unlock()
+
+	latency := s.firstPullLatency
+	outcome := "first"
+	if cached {
+		latency = s.cachedPullLatency
+		outcome = "cached"
+	}
+	if latency > 0 {
+		simClock.Sleep(latency)
+	}
+	imagePullsTotal.WithLabelValues(outcome).Inc()
+	return s.ImageManagerService.PullImage(image, auth, podSandboxConfig)
+}
+
+// RemoveImage forgets the cached pull so the next one is slow again.
+func (s *simulatedImageService) RemoveImage(image *runtimeapi.ImageSpec) error {
+	s.lock.Lock()
+	delete(s.pulled, image.Image)
+	s.lock.Unlock()
+	return s.ImageManagerService.RemoveImage(image)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// withFakeClock installs a fake simulation clock for the duration of a test.
+func withFakeClock(t *testing.T) *clocktesting.FakeClock {
+	t.Helper()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	SetSimulatedClock(fakeClock)
+	t.Cleanup(func() { SetSimulatedClock(clock.RealClock{}) })
+	return fakeClock
+}
+
+func TestImageRegistry(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"busybox", "docker.io"},
+		{"library/busybox:1.36", "docker.io"},
+		{"registry.k8s.io/pause:3.6", "registry.k8s.io"},
+		{"localhost/pause", "localhost"},
+		{"localhost:5000/pause", "localhost:5000"},
+		{"example.com:443/group/image:tag", "example.com:443"},
+	}
+	for _, test := range tests {
+		if got := imageRegistry(test.image); got != test.want {
+			t.Errorf("imageRegistry(%q) = %q, want %q", test.image, got, test.want)
+		}
+	}
+}
+
+func TestSimulatedImagePullLatency(t *testing.T) {
+	fakeClock := withFakeClock(t)
+	first := 10 * time.Second
+	cached := 500 * time.Millisecond
+	service := NewSimulatedImageService(apitest.NewFakeImageService(), first, cached, nil)
+	spec := &runtimeapi.ImageSpec{Image: "registry.k8s.io/pause:3.6"}
+
+	before := fakeClock.Now()
+	if _, err := service.PullImage(spec, nil, nil); err != nil {
+		t.Fatalf("first PullImage returned unexpected error: %v", err)
+	}
+	if elapsed := fakeClock.Since(before); elapsed != first {
+		t.Errorf("first pull took %v of simulated time, want %v", elapsed, first)
+	}
+
+	before = fakeClock.Now()
+	if _, err := service.PullImage(spec, nil, nil); err != nil {
+		t.Fatalf("cached PullImage returned unexpected error: %v", err)
+	}
+	if elapsed := fakeClock.Since(before); elapsed != cached {
+		t.Errorf("cached pull took %v of simulated time, want %v", elapsed, cached)
+	}
+}
+
+func TestSimulatedImagePullRemoveForgetsCache(t *testing.T) {
+	fakeClock := withFakeClock(t)
+	first := 10 * time.Second
+	cached := 500 * time.Millisecond
+	service := NewSimulatedImageService(apitest.NewFakeImageService(), first, cached, nil)
+	spec := &runtimeapi.ImageSpec{Image: "registry.k8s.io/pause:3.6"}
+
+	if _, err := service.PullImage(spec, nil, nil); err != nil {
+		t.Fatalf("PullImage returned unexpected error: %v", err)
+	}
+	if err := service.RemoveImage(spec); err != nil {
+		t.Fatalf("RemoveImage returned unexpected error: %v", err)
+	}
+
+	before := fakeClock.Now()
+	if _, err := service.PullImage(spec, nil, nil); err != nil {
+		t.Fatalf("PullImage after remove returned unexpected error: %v", err)
+	}
+	if elapsed := fakeClock.Since(before); elapsed != first {
+		t.Errorf("pull after RemoveImage took %v of simulated time, want the first-pull latency %v", elapsed, first)
+	}
+}
+
+func TestSimulatedImagePullFailure(t *testing.T) {
+	fakeClock := withFakeClock(t)
+	rates := map[string]float64{"flaky.example.com": 1.0}
+	service := NewSimulatedImageService(apitest.NewFakeImageService(), 10*time.Second, time.Second, rates)
+
+	before := fakeClock.Now()
+	_, err := service.PullImage(&runtimeapi.ImageSpec{Image: "flaky.example.com/app:v1"}, nil, nil)
+	if err == nil {
+		t.Fatal("PullImage from a registry with failure rate 1.0 succeeded, want error")
+	}
+	if !strings.Contains(err.Error(), "flaky.example.com") {
+		t.Errorf("error %q does not name the failing registry", err)
+	}
+	if elapsed := fakeClock.Since(before); elapsed != 0 {
+		t.Errorf("failed pull consumed %v of simulated time, want none", elapsed)
+	}
+
+	// Other registries are unaffected by the rate.
+	if _, err := service.PullImage(&runtimeapi.ImageSpec{Image: "registry.k8s.io/pause:3.6"}, nil, nil); err != nil {
+		t.Errorf("PullImage from an unlisted registry returned unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// InitContainersAnnotation lists a pod's init container names
+	// (comma-separated). The CRI carries no container-type marker, so the
+	// simulator relies on this annotation to know which containers must run
+	// to completion before the app containers start.
+	InitContainersAnnotation = "kubemark.io/init-containers"
+	// InitContainerSecondsAnnotation overrides the node-wide init container
+	// duration for one pod.
+	InitContainerSecondsAnnotation = "kubemark.io/init-container-seconds"
+	// FailingInitContainerAnnotation names an init container that exits 1
+	// instead of completing, so the pod sticks in Init:Error and
+	// Init:CrashLoopBackOff per its restartPolicy.
+	FailingInitContainerAnnotation = "kubemark.io/failing-init-container"
+)
+
+// InitContainerSimulator completes init containers of hollow pods. The fake
+// runtime leaves every started container running forever, which stalls pods
+// with init containers in Init:0/N; this simulator exits declared init
+// containers after their configured duration, and the real kubelet then
+// starts the next init container (or the app containers) exactly as on a
+// real node, producing genuine Init status progression for kubectl and
+// controllers waiting on init completion.
+type InitContainerSimulator struct {
+	Runtime *apitest.FakeRuntimeService
+	// Duration is how long each init container runs, unless the pod
+	// overrides it via annotation.
+	Duration time.Duration
+}
+
+// NewInitContainerSimulator creates a simulator over the given fake runtime.
+func NewInitContainerSimulator(runtime *apitest.FakeRuntimeService, duration time.Duration) *InitContainerSimulator {
+	return &InitContainerSimulator{Runtime: runtime, Duration: duration}
+}
+
+// Run scans for due init containers every 2 seconds until stopCh is closed.
+// The scan period bounds how much the simulated durations stretch, since an
+// init container can only complete on a tick.
+func (s *InitContainerSimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.completeDueInitContainers()
+			}
+		}
+	}()
+}
+
+// completeDueInitContainers exits running init containers that have run for
+// their configured duration: successfully, or with exit 1 for a container
+// named by the failure annotation.
+func (s *InitContainerSimulator) completeDueInitContainers() {
+	now := simClock.Now()
+	s.Runtime.Lock()
+	defer s.Runtime.Unlock()
+	for _, c := range s.Runtime.Containers {
+		if c.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		sandbox, ok := s.Runtime.Sandboxes[c.SandboxID]
+		if !ok {
+			continue
+		}
+		name := c.Metadata.GetName()
+		if !initContainerNamed(sandbox.Annotations[InitContainersAnnotation], name) {
+			continue
+		}
+		duration := s.Duration
+		if value, ok := sandbox.Annotations[InitContainerSecondsAnnotation]; ok {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				duration = time.Duration(seconds) * time.Second
+			} else {
+				klog.Warningf("Ignoring invalid %s annotation %q on pod %s/%s", InitContainerSecondsAnnotation, value, sandbox.Metadata.Namespace, sandbox.Metadata.Name)
+			}
+		}
+		startedAt := c.StartedAt
+		if startedAt == 0 {
+			startedAt = c.CreatedAt
+		}
+		if now.UnixNano()-startedAt < duration.Nanoseconds() {
+			continue
+		}
+		c.State = runtimeapi.ContainerState_CONTAINER_EXITED
+		c.FinishedAt = now.UnixNano()
+		if sandbox.Annotations[FailingInitContainerAnnotation] == name {
+			c.ExitCode = 1
+			c.Reason = "Error"
+			c.Message = "simulated init container failure"
+		} else {
+			c.ExitCode = 0
+			c.Reason = "Completed"
+		}
+		klog.V(3).Infof("Init container %s of pod %s/%s finished with exit %d", name, sandbox.Metadata.Namespace, sandbox.Metadata.Name, c.ExitCode)
+	}
+}
+
+// initContainerNamed reports whether name appears in the comma-separated
+// init container list.
+func initContainerNamed(list, name string) bool {
+	if list == "" || name == "" {
+		return false
+	}
+	for _, entry := range strings.Split(list, ",") {
+		if strings.TrimSpace(entry) == name {
+			return true
+		}
+	}
+	return false
+}
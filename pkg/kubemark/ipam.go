@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	internalapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+)
+
+var podIPAnomaliesTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "pod_ip_anomalies_total",
+		Help:           "Number of pod sandbox status reads answered with an injected IP anomaly, partitioned by anomaly type (conflict, delayed).",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"type"},
+)
+
+func init() {
+	legacyregistry.MustRegister(podIPAnomaliesTotal)
+}
+
+// podIPSimulatorService assigns each pod sandbox a distinct IP from the
+// node's pod CIDR, and injects IPAM failure modes for configured fractions
+// of pods: duplicate IPs (several pods reporting the same address) and
+// delayed assignment (no IP until some time after sandbox creation). This
+// gives IPAM validation and EndpointSlice correctness checks real failure
+// cases to catch during simulation runs.
+type podIPSimulatorService struct {
+	internalapi.RuntimeService
+	// base and size describe the usable host range of the pod CIDR.
+	base net.IP
+	size int64
+	// conflictFraction of pods report the CIDR's first host IP, so they
+	// duplicate each other (and possibly a legitimately assigned pod).
+	conflictFraction float64
+	// delayFraction of pods report no IP until assignmentDelay after
+	// sandbox creation.
+	delayFraction   float64
+	assignmentDelay time.Duration
+}
+
+// NewPodIPSimulatorService wraps a runtime service so sandbox statuses carry
+// per-pod IPs from podCIDR, with the given fractions of pods subjected to
+// duplicate or delayed IP assignment.
+func NewPodIPSimulatorService(delegate internalapi.RuntimeService, podCIDR string, conflictFraction, delayFraction float64, assignmentDelay time.Duration) (internalapi.RuntimeService, error) {
+	_, network, err := net.ParseCIDR(podCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing pod CIDR %q: %w", podCIDR, err)
+	}
+	if network.IP.To4() == nil {
+		return nil, fmt.Errorf("pod CIDR %q: only IPv4 pod CIDRs are supported for IP simulation", podCIDR)
+	}
+	ones, bits := network.Mask.Size()
+	// Usable host addresses, excluding network, gateway and broadcast.
+	size := int64(1)<<(bits-ones) - 3
+	if size < 1 {
+		return nil, fmt.Errorf("pod CIDR %q is too small to assign pod IPs from", podCIDR)
+	}
+	return &podIPSimulatorService{
+		RuntimeService:   delegate,
+		base:             network.IP,
+		size:             size,
+		conflictFraction: conflictFraction,
+		delayFraction:    delayFraction,
+		assignmentDelay:  assignmentDelay,
+	}, nil
+}
+
+// PodSandboxStatus rewrites the sandbox's reported IP according to the
+// simulated IPAM behavior.
+func (s *podIPSimulatorService) PodSandboxStatus(podSandboxID string, verbose bool) (*runtimeapi.PodSandboxStatusResponse, error) {
+	resp, err := s.RuntimeService.PodSandboxStatus(podSandboxID, verbose)
+	if err != nil || resp == nil || resp.Status == nil || resp.Status.Network == nil {
+		return resp, err
+	}
+	fraction := containerFraction(podSandboxID)
+	switch {
+	case fraction < s.conflictFraction:
+		// All conflicted pods share the CIDR's first host address.
+		resp.Status.Network.Ip = s.hostIP(0)
+		resp.Status.Network.AdditionalIps = nil
+		podIPAnomaliesTotal.WithLabelValues("conflict").Inc()
+		klog.V(4).Infof("Reporting conflicting IP %s for sandbox %s", resp.Status.Network.Ip, podSandboxID)
+	case fraction < s.conflictFraction+s.delayFraction && simClock.Since(time.Unix(0, resp.Status.CreatedAt)) < s.assignmentDelay:
+		resp.Status.Network.Ip = ""
+		resp.Status.Network.AdditionalIps = nil
+		podIPAnomaliesTotal.WithLabelValues("delayed").Inc()
+	default:
+		// A stable per-pod address from the pod CIDR's host range.
+		offset := int64(containerFraction(podSandboxID) * float64(s.size-1))
+		resp.Status.Network.Ip = s.hostIP(1 + offset)
+		resp.Status.Network.AdditionalIps = nil
+	}
+	return resp, nil
+}
+
+// hostIP returns the pod CIDR's host address at the given offset, counting
+// from the first usable address after the gateway.
+func (s *podIPSimulatorService) hostIP(offset int64) string {
+	base := s.base.To4()
+	addr := uint32(base[0])<<24 | uint32(base[1])<<16 | uint32(base[2])<<8 | uint32(base[3])
+	// Skip the network and gateway addresses.
+	addr += uint32(offset + 2)
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr)).String()
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyDigestCapacity bounds the per-series memory used for percentile
+// estimation. Beyond it, reservoir sampling keeps a uniform sample.
+const latencyDigestCapacity = 10000
+
+// latencyDigest estimates percentiles for one latency series. It keeps a
+// bounded uniform sample of observations, which is accurate enough for quick
+// experiment readouts without pulling in a t-digest dependency.
+type latencyDigest struct {
+	count   int64
+	samples []float64
+}
+
+func (d *latencyDigest) observe(seconds float64) {
+	d.count++
+	if len(d.samples) < latencyDigestCapacity {
+		d.samples = append(d.samples, seconds)
+		return
+	}
+	if i := rand.Int63n(d.count); i < int64(latencyDigestCapacity) {
+		d.samples[i] = seconds
+	}
+}
+
+// LatencySummary is the admin API readout for one latency series.
+type LatencySummary struct {
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+func (d *latencyDigest) summarize() LatencySummary {
+	sorted := make([]float64, len(d.samples))
+	copy(sorted, d.samples)
+	sort.Float64s(sorted)
+	quantile := func(q float64) float64 {
+		if len(sorted) == 0 {
+			return 0
+		}
+		i := int(q * float64(len(sorted)-1))
+		return sorted[i]
+	}
+	summary := LatencySummary{Count: d.count, P50: quantile(0.50), P90: quantile(0.90), P99: quantile(0.99)}
+	if len(sorted) > 0 {
+		summary.Max = sorted[len(sorted)-1]
+	}
+	return summary
+}
+
+// latencyDigests holds the in-process digests for the key simulated
+// latencies (pod startup, node status writes, lease renewals, ...), keyed by
+// series name.
+var latencyDigests = struct {
+	sync.Mutex
+	series map[string]*latencyDigest
+}{series: make(map[string]*latencyDigest)}
+
+// RecordLatency adds an observation to the named latency series.
+func RecordLatency(name string, d time.Duration) {
+	latencyDigests.Lock()
+	defer latencyDigests.Unlock()
+	digest, ok := latencyDigests.series[name]
+	if !ok {
+		digest = &latencyDigest{}
+		latencyDigests.series[name] = digest
+	}
+	digest.observe(d.Seconds())
+}
+
+// LatencySummaries returns a percentile summary per recorded series.
+func LatencySummaries() map[string]LatencySummary {
+	latencyDigests.Lock()
+	defer latencyDigests.Unlock()
+	summaries := make(map[string]LatencySummary, len(latencyDigests.series))
+	for name, digest := range latencyDigests.series {
+		summaries[name] = digest.summarize()
+	}
+	return summaries
+}
+
+func init() {
+	RegisterAdminHandler("/latency", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(LatencySummaries()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lifecycle implements the simulated pod lifecycle state machine
+// shared by kubemark simulators. It tracks the phase each pod was last
+// observed in, validates that requested transitions are legal, and lets
+// callers plug in Deciders (which may deny, delay or redirect a transition)
+// and Observers (which are notified after a transition takes effect), so
+// other simulators and tests can reuse and extend the state machine without
+// copying transition logic.
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Decision is a Decider's verdict on a pod transition.
+type Decision struct {
+	// Allow indicates whether the transition should proceed. A disallowed
+	// transition is treated as a simulated failure.
+	Allow bool
+	// Delay postpones the transition by the given amount. The machine does
+	// not sleep itself; the aggregated delay is returned to the caller so it
+	// can be applied against whatever clock drives the simulation.
+	Delay time.Duration
+	// PhaseOverride, if set, replaces the phase the pod transitions to.
+	PhaseOverride v1.PodPhase
+	// Reason is surfaced in logs and events for disallowed transitions.
+	Reason string
+}
+
+// Decider is consulted before a transition takes effect.
+type Decider interface {
+	// DecideTransition returns the decider's verdict on moving pod to the
+	// given phase. Implementations must be safe for concurrent use.
+	DecideTransition(pod *v1.Pod, to v1.PodPhase) Decision
+}
+
+// Observer is notified after a transition has been recorded.
+type Observer interface {
+	// ObserveTransition is called with the phase the pod left and the phase
+	// it entered. Implementations must be fast and must not block.
+	ObserveTransition(pod *v1.Pod, from, to v1.PodPhase)
+}
+
+// ObserverFunc adapts a plain function to the Observer interface.
+type ObserverFunc func(pod *v1.Pod, from, to v1.PodPhase)
+
+// ObserveTransition implements Observer.
+func (f ObserverFunc) ObserveTransition(pod *v1.Pod, from, to v1.PodPhase) {
+	f(pod, from, to)
+}
+
+// validNext lists the phases a pod may legally move to from each phase.
+// Succeeded and Failed are terminal. An empty "from" (a pod the machine has
+// not seen before) may move to any phase, since the machine may start
+// observing a pod mid-lifecycle.
+var validNext = map[v1.PodPhase][]v1.PodPhase{
+	v1.PodPending: {v1.PodRunning, v1.PodSucceeded, v1.PodFailed, v1.PodUnknown},
+	v1.PodRunning: {v1.PodSucceeded, v1.PodFailed, v1.PodUnknown},
+	v1.PodUnknown: {v1.PodPending, v1.PodRunning, v1.PodSucceeded, v1.PodFailed},
+}
+
+// ValidTransition reports whether a pod in phase from may move to phase to.
+// A transition to the current phase is a no-op and always valid.
+func ValidTransition(from, to v1.PodPhase) bool {
+	if from == "" || from == to {
+		return true
+	}
+	for _, next := range validNext[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Machine tracks the lifecycle phase of a set of simulated pods and applies
+// registered Deciders and Observers at every transition. The zero value is
+// not usable; create one with NewMachine.
+type Machine struct {
+	lock      sync.RWMutex
+	phases    map[types.UID]v1.PodPhase
+	deciders  []Decider
+	observers []Observer
+}
+
+// NewMachine creates an empty pod lifecycle state machine.
+func NewMachine() *Machine {
+	return &Machine{phases: make(map[types.UID]v1.PodPhase)}
+}
+
+// AddDecider registers a decider consulted on every subsequent transition.
+func (m *Machine) AddDecider(d Decider) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.deciders = append(m.deciders, d)
+}
+
+// AddObserver registers an observer notified of every subsequent transition.
+func (m *Machine) AddObserver(o Observer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.observers = append(m.observers, o)
+}
+
+// Phase returns the phase the pod was last observed in, or the empty phase
+// if the machine has not seen it.
+func (m *Machine) Phase(uid types.UID) v1.PodPhase {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.phases[uid]
+}
+
+// Decide aggregates the registered deciders' verdicts on moving pod to the
+// given phase: the first denial wins outright, delays accumulate to the
+// longest requested, and a later phase override replaces an earlier one.
+func (m *Machine) Decide(pod *v1.Pod, to v1.PodPhase) Decision {
+	m.lock.RLock()
+	deciders := m.deciders
+	m.lock.RUnlock()
+
+	merged := Decision{Allow: true}
+	for _, d := range deciders {
+		decision := d.DecideTransition(pod, to)
+		if !decision.Allow {
+			return decision
+		}
+		if decision.Delay > merged.Delay {
+			merged.Delay = decision.Delay
+		}
+		if decision.PhaseOverride != "" {
+			merged.PhaseOverride = decision.PhaseOverride
+		}
+	}
+	return merged
+}
+
+// Transition moves pod to the given phase, first validating the transition
+// against the pod phase graph and consulting the registered deciders. It
+// returns the phase actually entered (which may differ from the request if
+// a decider overrode it) and any delay the deciders asked for; the caller
+// is responsible for applying the delay. Invalid and denied transitions
+// return an error and leave the recorded phase unchanged.
+func (m *Machine) Transition(pod *v1.Pod, to v1.PodPhase) (v1.PodPhase, time.Duration, error) {
+	from := m.Phase(pod.UID)
+	if from == "" {
+		// Fall back on the pod's reported status for pods the machine is
+		// observing for the first time.
+		from = pod.Status.Phase
+	}
+	decision := m.Decide(pod, to)
+	if !decision.Allow {
+		return from, 0, fmt.Errorf("transition of pod %s/%s to %s denied: %s", pod.Namespace, pod.Name, to, decision.Reason)
+	}
+	if decision.PhaseOverride != "" {
+		to = decision.PhaseOverride
+	}
+	if !ValidTransition(from, to) {
+		return from, 0, fmt.Errorf("invalid transition of pod %s/%s from %s to %s", pod.Namespace, pod.Name, from, to)
+	}
+
+	m.lock.Lock()
+	m.phases[pod.UID] = to
+	observers := m.observers
+	m.lock.Unlock()
+
+	for _, o := range observers {
+		o.ObserveTransition(pod, from, to)
+	}
+	return to, decision.Delay, nil
+}
+
+// Forget drops the recorded phase for a pod, typically once it has been
+// deleted from the node.
+func (m *Machine) Forget(uid types.UID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.phases, uid)
+}
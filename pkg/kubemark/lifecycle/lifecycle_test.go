@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func makePod(uid, name string, phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(uid),
+			Namespace: "default",
+			Name:      name,
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+// deciderFunc adapts a function to the Decider interface for tests.
+type deciderFunc func(pod *v1.Pod, to v1.PodPhase) Decision
+
+func (f deciderFunc) DecideTransition(pod *v1.Pod, to v1.PodPhase) Decision {
+	return f(pod, to)
+}
+
+func TestValidTransition(t *testing.T) {
+	tests := []struct {
+		from, to v1.PodPhase
+		want     bool
+	}{
+		{"", v1.PodRunning, true},
+		{v1.PodPending, v1.PodPending, true},
+		{v1.PodPending, v1.PodRunning, true},
+		{v1.PodPending, v1.PodFailed, true},
+		{v1.PodRunning, v1.PodSucceeded, true},
+		{v1.PodRunning, v1.PodPending, false},
+		{v1.PodSucceeded, v1.PodRunning, false},
+		{v1.PodFailed, v1.PodPending, false},
+		{v1.PodUnknown, v1.PodRunning, true},
+	}
+	for _, test := range tests {
+		if got := ValidTransition(test.from, test.to); got != test.want {
+			t.Errorf("ValidTransition(%q, %q) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestTransitionRecordsPhase(t *testing.T) {
+	m := NewMachine()
+	pod := makePod("uid-1", "pod-1", v1.PodPending)
+
+	entered, delay, err := m.Transition(pod, v1.PodRunning)
+	if err != nil {
+		t.Fatalf("Transition returned unexpected error: %v", err)
+	}
+	if entered != v1.PodRunning || delay != 0 {
+		t.Errorf("Transition = (%q, %v), want (%q, 0)", entered, delay, v1.PodRunning)
+	}
+	if got := m.Phase(pod.UID); got != v1.PodRunning {
+		t.Errorf("Phase = %q, want %q", got, v1.PodRunning)
+	}
+}
+
+func TestTransitionInvalid(t *testing.T) {
+	m := NewMachine()
+	pod := makePod("uid-1", "pod-1", v1.PodPending)
+	if _, _, err := m.Transition(pod, v1.PodRunning); err != nil {
+		t.Fatalf("Transition returned unexpected error: %v", err)
+	}
+	if _, _, err := m.Transition(pod, v1.PodPending); err == nil {
+		t.Error("Transition back to Pending succeeded, want error")
+	}
+	if got := m.Phase(pod.UID); got != v1.PodRunning {
+		t.Errorf("Phase after invalid transition = %q, want %q", got, v1.PodRunning)
+	}
+}
+
+func TestTransitionFirstObservationUsesPodStatus(t *testing.T) {
+	m := NewMachine()
+	// A pod the machine has not seen, already reported Succeeded, must not
+	// move back to Running.
+	pod := makePod("uid-1", "pod-1", v1.PodSucceeded)
+	if _, _, err := m.Transition(pod, v1.PodRunning); err == nil {
+		t.Error("Transition from reported Succeeded to Running succeeded, want error")
+	}
+}
+
+func TestDecideAggregation(t *testing.T) {
+	m := NewMachine()
+	m.AddDecider(deciderFunc(func(pod *v1.Pod, to v1.PodPhase) Decision {
+		return Decision{Allow: true, Delay: 2 * time.Second}
+	}))
+	m.AddDecider(deciderFunc(func(pod *v1.Pod, to v1.PodPhase) Decision {
+		return Decision{Allow: true, Delay: 5 * time.Second, PhaseOverride: v1.PodFailed}
+	}))
+	pod := makePod("uid-1", "pod-1", v1.PodRunning)
+
+	entered, delay, err := m.Transition(pod, v1.PodSucceeded)
+	if err != nil {
+		t.Fatalf("Transition returned unexpected error: %v", err)
+	}
+	if entered != v1.PodFailed {
+		t.Errorf("entered phase = %q, want override %q", entered, v1.PodFailed)
+	}
+	if delay != 5*time.Second {
+		t.Errorf("delay = %v, want the longest requested (5s)", delay)
+	}
+}
+
+func TestDecideDenialWins(t *testing.T) {
+	m := NewMachine()
+	m.AddDecider(deciderFunc(func(pod *v1.Pod, to v1.PodPhase) Decision {
+		return Decision{Allow: false, Reason: "denied by test"}
+	}))
+	m.AddDecider(deciderFunc(func(pod *v1.Pod, to v1.PodPhase) Decision {
+		return Decision{Allow: true}
+	}))
+	pod := makePod("uid-1", "pod-1", v1.PodPending)
+
+	if _, _, err := m.Transition(pod, v1.PodRunning); err == nil {
+		t.Fatal("denied Transition succeeded, want error")
+	}
+	if got := m.Phase(pod.UID); got != "" {
+		t.Errorf("Phase after denial = %q, want unrecorded", got)
+	}
+}
+
+func TestObserversNotified(t *testing.T) {
+	m := NewMachine()
+	var gotFrom, gotTo v1.PodPhase
+	m.AddObserver(ObserverFunc(func(pod *v1.Pod, from, to v1.PodPhase) {
+		gotFrom, gotTo = from, to
+	}))
+	pod := makePod("uid-1", "pod-1", v1.PodPending)
+
+	if _, _, err := m.Transition(pod, v1.PodRunning); err != nil {
+		t.Fatalf("Transition returned unexpected error: %v", err)
+	}
+	if gotFrom != v1.PodPending || gotTo != v1.PodRunning {
+		t.Errorf("observer saw (%q, %q), want (%q, %q)", gotFrom, gotTo, v1.PodPending, v1.PodRunning)
+	}
+}
+
+func TestForget(t *testing.T) {
+	m := NewMachine()
+	pod := makePod("uid-1", "pod-1", v1.PodPending)
+	if _, _, err := m.Transition(pod, v1.PodSucceeded); err != nil {
+		t.Fatalf("Transition returned unexpected error: %v", err)
+	}
+	m.Forget(pod.UID)
+	if got := m.Phase(pod.UID); got != "" {
+		t.Errorf("Phase after Forget = %q, want unrecorded", got)
+	}
+	// With the terminal phase forgotten and the pod recreated as Pending,
+	// Running is legal again.
+	pod = makePod("uid-1", "pod-1", v1.PodPending)
+	if _, _, err := m.Transition(pod, v1.PodRunning); err != nil {
+		t.Errorf("Transition after Forget returned unexpected error: %v", err)
+	}
+}
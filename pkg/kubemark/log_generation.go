@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/klog/v2"
+)
+
+var generatedLogBytesTotal = metrics.NewCounter(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "generated_log_bytes_total",
+		Help:           "Bytes of synthetic container log written on this hollow node.",
+		StabilityLevel: metrics.ALPHA,
+	},
+)
+
+func init() {
+	legacyregistry.MustRegister(generatedLogBytesTotal)
+}
+
+// maxGeneratedLogBytes caps a single generated log file; beyond it the file
+// is rotated aside the way containerd rotates, so long runs do not fill the
+// host disk.
+const maxGeneratedLogBytes = 10 * 1024 * 1024
+
+// LogGenerator writes synthetic CRI-format log lines for every running
+// container in the fake runtime and points the container's LogPath at the
+// generated file. The kubelet's /containerLogs endpoint reads that path
+// with its CRI log parser, so `kubectl logs` (including --since, --tail and
+// --follow semantics on growing files), log collectors tailing the log
+// directory and log-based e2e-style checks all work against hollow nodes.
+type LogGenerator struct {
+	Runtime *apitest.FakeRuntimeService
+	// LinesPerSecond is the log rate of each running container.
+	LinesPerSecond float64
+	// LogDir is the directory the generated files are written under.
+	LogDir string
+
+	// carry accumulates the fractional lines a sub-1/s rate leaves behind
+	// between ticks.
+	carry float64
+	// sequence numbers the lines of each container.
+	sequence map[string]int
+}
+
+// NewLogGenerator creates a generator writing linesPerSecond log lines for
+// each running container under logDir.
+func NewLogGenerator(runtime *apitest.FakeRuntimeService, linesPerSecond float64, logDir string) *LogGenerator {
+	return &LogGenerator{
+		Runtime:        runtime,
+		LinesPerSecond: linesPerSecond,
+		LogDir:         logDir,
+		sequence:       map[string]int{},
+	}
+}
+
+// Run writes log lines every second until stopCh is closed.
+func (g *LogGenerator) Run(stopCh <-chan struct{}) {
+	if g.LinesPerSecond <= 0 {
+		return
+	}
+	if err := os.MkdirAll(g.LogDir, 0755); err != nil {
+		klog.Errorf("Failed to create container log directory %s: %v", g.LogDir, err)
+		return
+	}
+	go func() {
+		ticker := simClock.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				g.tick()
+			}
+		}
+	}()
+}
+
+// generatedContainer is the snapshot of one running container taken under
+// the runtime lock, so file writes happen outside it.
+type generatedContainer struct {
+	id        string
+	logPath   string
+	pod       string
+	container string
+}
+
+// tick writes this second's lines for every running container and removes
+// the files of containers that are gone.
+func (g *LogGenerator) tick() {
+	g.carry += g.LinesPerSecond
+	lines := int(g.carry)
+	g.carry -= float64(lines)
+
+	running := g.snapshotRunning()
+	for _, c := range running {
+		if err := g.writeLines(c, lines); err != nil {
+			klog.V(3).Infof("Failed to write synthetic log for container %s: %v", c.id, err)
+		}
+	}
+	g.removeOrphanedLogs(running)
+}
+
+// snapshotRunning collects the running containers under the runtime lock,
+// assigning a LogPath to any container that does not have one yet. The fake
+// runtime drops the log path the kubelet passes at CreateContainer, so the
+// generator owns the whole path instead.
+func (g *LogGenerator) snapshotRunning() []generatedContainer {
+	g.Runtime.Lock()
+	defer g.Runtime.Unlock()
+	var running []generatedContainer
+	for id, c := range g.Runtime.Containers {
+		if c.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		if c.LogPath == "" {
+			c.LogPath = filepath.Join(g.LogDir, sanitizeLogName(id)+".log")
+		}
+		gc := generatedContainer{id: id, logPath: c.LogPath, container: c.Metadata.GetName()}
+		if sandbox, ok := g.Runtime.Sandboxes[c.SandboxID]; ok && sandbox.Metadata != nil {
+			gc.pod = sandbox.Metadata.Namespace + "/" + sandbox.Metadata.Name
+		}
+		running = append(running, gc)
+	}
+	return running
+}
+
+// writeLines appends n CRI-format lines, rotating the file aside once it
+// exceeds the size cap.
+func (g *LogGenerator) writeLines(c generatedContainer, n int) error {
+	if n == 0 {
+		return nil
+	}
+	if info, err := os.Stat(c.logPath); err == nil && info.Size() > maxGeneratedLogBytes {
+		if err := os.Rename(c.logPath, c.logPath+".1"); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(c.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	written := 0
+	for i := 0; i < n; i++ {
+		g.sequence[c.id]++
+		// The CRI log format the kubelet's parser expects:
+		// "<RFC3339Nano> <stream> <F|P> <content>". Timestamps are wall
+		// clock so --since and --timestamps behave.
+		line := fmt.Sprintf("%s stdout F synthetic log line %d from container %s of pod %s\n",
+			time.Now().Format(time.RFC3339Nano), g.sequence[c.id], c.container, c.pod)
+		size, err := file.WriteString(line)
+		written += size
+		if err != nil {
+			generatedLogBytesTotal.Add(float64(written))
+			return err
+		}
+	}
+	generatedLogBytesTotal.Add(float64(written))
+	return nil
+}
+
+// removeOrphanedLogs deletes generated files whose container no longer
+// runs, mirroring the kubelet's own container log garbage collection.
+func (g *LogGenerator) removeOrphanedLogs(running []generatedContainer) {
+	current := map[string]bool{}
+	for _, c := range running {
+		current[c.id] = true
+	}
+	for id := range g.sequence {
+		if current[id] {
+			continue
+		}
+		path := filepath.Join(g.LogDir, sanitizeLogName(id)+".log")
+		os.Remove(path)
+		os.Remove(path + ".1")
+		delete(g.sequence, id)
+	}
+}
+
+// sanitizeLogName turns a container ID into a safe file name.
+func sanitizeLogName(id string) string {
+	return strings.NewReplacer("/", "_", ":", "_").Replace(id)
+}
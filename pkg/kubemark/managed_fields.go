@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var managedFieldsEntries = metrics.NewGauge(
+	&metrics.GaugeOpts{
+		Subsystem:      "kubemark",
+		Name:           "node_managed_fields_entries",
+		Help:           "Number of managedFields entries on this hollow node's Node object, as grown by the managed-fields simulation.",
+		StabilityLevel: metrics.ALPHA,
+	},
+)
+
+func init() {
+	legacyregistry.MustRegister(managedFieldsEntries)
+}
+
+// ManagedFieldsGrower accumulates realistic managedFields history on this
+// hollow node's Node object. Long-lived Node objects in real clusters pick
+// up entries from many field managers (kubelet, cloud controllers, node
+// agents, operators), each owning a growing set of fields; the resulting
+// serialization cost on every node write and list is hard to see in small
+// clusters. The grower reproduces it by server-side-applying annotations
+// under rotating field manager identities, each manager's owned field set
+// growing up to a cap.
+type ManagedFieldsGrower struct {
+	Client   clientset.Interface
+	NodeName string
+	// Interval between apply operations.
+	Interval time.Duration
+	// Managers is how many distinct field manager identities rotate.
+	Managers int
+	// MaxKeysPerManager caps how many annotations each manager owns; once
+	// reached, applies rewrite existing keys so the object stops growing.
+	MaxKeysPerManager int
+
+	ticks int
+}
+
+// NewManagedFieldsGrower creates a grower for this node's Node object.
+func NewManagedFieldsGrower(client clientset.Interface, nodeName string, interval time.Duration, managers int) *ManagedFieldsGrower {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if managers <= 0 {
+		managers = 1
+	}
+	return &ManagedFieldsGrower{
+		Client:            client,
+		NodeName:          nodeName,
+		Interval:          interval,
+		Managers:          managers,
+		MaxKeysPerManager: 50,
+	}
+}
+
+// Run applies one managed-fields update per interval until stopCh is closed.
+func (g *ManagedFieldsGrower) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(g.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				g.apply()
+			}
+		}
+	}()
+}
+
+// apply server-side-applies one annotation under the next rotating field
+// manager identity.
+func (g *ManagedFieldsGrower) apply() {
+	manager := fmt.Sprintf("kubemark-node-agent-%d", g.ticks%g.Managers)
+	key := (g.ticks / g.Managers) % g.MaxKeysPerManager
+	g.ticks++
+
+	annotation := fmt.Sprintf("%s.kubemark.io/field-%d", manager, key)
+	patch := []byte(fmt.Sprintf(
+		`{"apiVersion":"v1","kind":"Node","metadata":{"name":%q,"annotations":{%q:%q}}}`,
+		g.NodeName, annotation, simClock.Now().Format(time.RFC3339)))
+	force := true
+	node, err := g.Client.CoreV1().Nodes().Patch(context.TODO(), g.NodeName, types.ApplyPatchType, patch, metav1.PatchOptions{
+		FieldManager: manager,
+		Force:        &force,
+	})
+	if err != nil {
+		klog.Warningf("Failed to grow managedFields on node %s: %v", g.NodeName, err)
+		return
+	}
+	managedFieldsEntries.Set(float64(len(node.ManagedFields)))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+)
+
+// CredentialsConfig describes a batch of per-node hollow component
+// credentials. At fleet sizes of thousands of nodes, fanning these out by
+// hand is the dominant setup cost of an external-cluster kubemark
+// deployment, so they are created in bulk instead.
+type CredentialsConfig struct {
+	// NamePrefix is the per-node name stem; node i uses <NamePrefix>-<i>.
+	NamePrefix string
+	// Count is the number of nodes to create credentials for.
+	Count int
+	// Namespace is where ServiceAccounts are created on the cluster under
+	// test.
+	Namespace string
+	// SecretNamespace is where the kubeconfig Secrets are created on the
+	// admin cluster running the fleet.
+	SecretNamespace string
+	// Server is the apiserver URL of the cluster under test embedded into
+	// the generated kubeconfigs.
+	Server string
+	// CAData is the cluster CA bundle embedded into the kubeconfigs.
+	CAData []byte
+	// TokenExpiration bounds the requested ServiceAccount token lifetime.
+	TokenExpiration time.Duration
+	// Concurrency is how many nodes are processed in parallel.
+	Concurrency int
+}
+
+// GenerateCredentials creates, for each of config.Count nodes, a
+// ServiceAccount on the target cluster, requests a bound token for it, and
+// stores a per-node kubeconfig Secret (with the kubelet.kubeconfig and
+// kubeproxy.kubeconfig keys the hollow-node Deployment mounts) on the admin
+// cluster. Existing ServiceAccounts and Secrets are reused or updated, so
+// the helper can be re-run to refresh expiring tokens.
+func GenerateCredentials(ctx context.Context, target, admin clientset.Interface, config CredentialsConfig) error {
+	if config.Count <= 0 {
+		return fmt.Errorf("credential count must be positive, got %d", config.Count)
+	}
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 20
+	}
+	if concurrency > config.Count {
+		concurrency = config.Count
+	}
+
+	indices := make(chan int)
+	errs := make([]error, config.Count)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = generateNodeCredentials(ctx, target, admin, config, i)
+			}
+		}()
+	}
+	for i := 0; i < config.Count; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			klog.Errorf("Failed to create credentials for %s-%d: %v", config.NamePrefix, i, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create credentials for %d of %d nodes", failed, config.Count)
+	}
+	return nil
+}
+
+// generateNodeCredentials provisions the ServiceAccount, token and
+// kubeconfig Secret of one node.
+func generateNodeCredentials(ctx context.Context, target, admin clientset.Interface, config CredentialsConfig, index int) error {
+	name := fmt.Sprintf("%s-%d", config.NamePrefix, index)
+
+	serviceAccount := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.Namespace},
+	}
+	if _, err := target.CoreV1().ServiceAccounts(config.Namespace).Create(ctx, serviceAccount, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error while creating service account %v: %w", name, err)
+	}
+
+	tokenRequest := &authenticationv1.TokenRequest{}
+	if config.TokenExpiration > 0 {
+		seconds := int64(config.TokenExpiration / time.Second)
+		tokenRequest.Spec.ExpirationSeconds = &seconds
+	}
+	token, err := target.CoreV1().ServiceAccounts(config.Namespace).CreateToken(ctx, name, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error while requesting token for service account %v: %w", name, err)
+	}
+
+	kubeconfig, err := buildKubeconfig(config.Server, config.CAData, token.Status.Token)
+	if err != nil {
+		return fmt.Errorf("error while building kubeconfig for %v: %w", name, err)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.SecretNamespace},
+		Type:       v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"kubelet.kubeconfig":   kubeconfig,
+			"kubeproxy.kubeconfig": kubeconfig,
+		},
+	}
+	_, err = admin.CoreV1().Secrets(config.SecretNamespace).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = admin.CoreV1().Secrets(config.SecretNamespace).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error while storing kubeconfig secret %v: %w", name, err)
+	}
+	return nil
+}
+
+// buildKubeconfig renders a token kubeconfig for the cluster under test.
+func buildKubeconfig(server string, caData []byte, token string) ([]byte, error) {
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["kubemark"] = &clientcmdapi.Cluster{
+		Server:                   server,
+		CertificateAuthorityData: caData,
+	}
+	if len(caData) == 0 {
+		kubeconfig.Clusters["kubemark"].InsecureSkipTLSVerify = true
+	}
+	kubeconfig.AuthInfos["kubemark"] = &clientcmdapi.AuthInfo{Token: token}
+	kubeconfig.Contexts["kubemark"] = &clientcmdapi.Context{Cluster: "kubemark", AuthInfo: "kubemark"}
+	kubeconfig.CurrentContext = "kubemark"
+	if err := clientcmd.Validate(*kubeconfig); err != nil {
+		return nil, err
+	}
+	return clientcmd.Write(*kubeconfig)
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifests programmatically produces the Kubernetes manifests needed
+// to run a fleet of hollow nodes on an admin cluster, replacing the
+// hand-maintained templates under test/kubemark/resources.
+package manifests
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// KubemarkConfiguration describes a hollow-node fleet to deploy on an admin
+// cluster. The zero value is not usable; call SetDefaults before generating
+// manifests.
+type KubemarkConfiguration struct {
+	// Name is the base name for all generated objects.
+	Name string
+	// Namespace the fleet runs in.
+	Namespace string
+	// NumNodes is the number of hollow nodes to run.
+	NumNodes int32
+	// Image is the kubemark image to use for the hollow node containers.
+	Image string
+	// KubeconfigSecret is the name of the Secret holding the kubelet and
+	// kube-proxy kubeconfigs.
+	KubeconfigSecret string
+	// Kubeconfig, if set, is embedded into the generated kubeconfig Secret
+	// for both the hollow kubelet and hollow proxy. If unset, no Secret
+	// manifest is produced and KubeconfigSecret must exist already.
+	Kubeconfig []byte
+	// NodeLabels are passed to each hollow kubelet via --node-labels.
+	NodeLabels map[string]string
+	// KubeletParams are extra arguments for the hollow kubelet container.
+	KubeletParams []string
+	// ProxyParams are extra arguments for the hollow proxy container.
+	ProxyParams []string
+	// KubeletCPU/KubeletMemory are resource requests per hollow kubelet.
+	KubeletCPU    resource.Quantity
+	KubeletMemory resource.Quantity
+	// ProxyCPU/ProxyMemory are resource requests per hollow proxy.
+	ProxyCPU    resource.Quantity
+	ProxyMemory resource.Quantity
+}
+
+// SetDefaults fills in unset fields with the values the kubemark scripts have
+// historically used.
+func (c *KubemarkConfiguration) SetDefaults() {
+	if c.Name == "" {
+		c.Name = "hollow-node"
+	}
+	if c.Namespace == "" {
+		c.Namespace = "kubemark"
+	}
+	if c.NumNodes == 0 {
+		c.NumNodes = 1
+	}
+	if c.Image == "" {
+		c.Image = "staging-k8s.gcr.io/kubemark:latest"
+	}
+	if c.KubeconfigSecret == "" {
+		c.KubeconfigSecret = "kubeconfig"
+	}
+	if c.KubeletCPU.IsZero() {
+		c.KubeletCPU = resource.MustParse("40m")
+	}
+	if c.KubeletMemory.IsZero() {
+		c.KubeletMemory = resource.MustParse("100Mi")
+	}
+	if c.ProxyCPU.IsZero() {
+		c.ProxyCPU = resource.MustParse("20m")
+	}
+	if c.ProxyMemory.IsZero() {
+		c.ProxyMemory = resource.MustParse("50Mi")
+	}
+}
+
+// MakeNamespace returns the namespace the fleet runs in.
+func (c *KubemarkConfiguration) MakeNamespace() *v1.Namespace {
+	return &v1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: c.Namespace},
+	}
+}
+
+// MakeKubeconfigSecret returns the Secret carrying kubeconfigs for the hollow
+// components, or nil if no kubeconfig content was provided.
+func (c *KubemarkConfiguration) MakeKubeconfigSecret() *v1.Secret {
+	if len(c.Kubeconfig) == 0 {
+		return nil
+	}
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.KubeconfigSecret,
+			Namespace: c.Namespace,
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"kubelet.kubeconfig":   c.Kubeconfig,
+			"kubeproxy.kubeconfig": c.Kubeconfig,
+		},
+	}
+}
+
+// MakeRBAC returns the ClusterRoleBindings granting the hollow components the
+// permissions their real counterparts have.
+func (c *KubemarkConfiguration) MakeRBAC() []runtime.Object {
+	binding := func(name, clusterRole, subject string) *rbacv1.ClusterRoleBinding {
+		return &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     clusterRole,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					APIGroup: rbacv1.GroupName,
+					Kind:     rbacv1.UserKind,
+					Name:     subject,
+				},
+			},
+		}
+	}
+	return []runtime.Object{
+		binding(c.Name+"-kubelet", "system:node", "system:node:"+c.Name),
+		binding(c.Name+"-proxy", "system:node-proxier", "system:kube-proxy"),
+	}
+}
+
+// MakeDeployment returns the Deployment running NumNodes hollow nodes, each
+// pod carrying a hollow kubelet and a hollow proxy container.
+func (c *KubemarkConfiguration) MakeDeployment() *appsv1.Deployment {
+	labels := map[string]string{"name": c.Name}
+
+	kubeletCommand := []string{
+		"/kubemark",
+		"--morph=kubelet",
+		"--name=$(NODE_NAME)",
+		"--kubeconfig=/kubeconfig/kubelet.kubeconfig",
+	}
+	if len(c.NodeLabels) > 0 {
+		kubeletCommand = append(kubeletCommand, "--node-labels="+labelString(c.NodeLabels))
+	}
+	kubeletCommand = append(kubeletCommand, c.KubeletParams...)
+
+	proxyCommand := []string{
+		"/kubemark",
+		"--morph=proxy",
+		"--name=$(NODE_NAME)",
+		"--kubeconfig=/kubeconfig/kubeproxy.kubeconfig",
+	}
+	proxyCommand = append(proxyCommand, c.ProxyParams...)
+
+	nodeNameEnv := v1.EnvVar{
+		Name: "NODE_NAME",
+		ValueFrom: &v1.EnvVarSource{
+			FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		},
+	}
+	kubeconfigMount := v1.VolumeMount{
+		Name:      "kubeconfig-volume",
+		MountPath: "/kubeconfig",
+		ReadOnly:  true,
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name,
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &c.NumNodes,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "kubeconfig-volume",
+							VolumeSource: v1.VolumeSource{
+								Secret: &v1.SecretVolumeSource{SecretName: c.KubeconfigSecret},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:    "hollow-kubelet",
+							Image:   c.Image,
+							Env:     []v1.EnvVar{nodeNameEnv},
+							Command: kubeletCommand,
+							Ports: []v1.ContainerPort{
+								{ContainerPort: 4194},
+								{ContainerPort: 10250},
+								{ContainerPort: 10255},
+							},
+							VolumeMounts: []v1.VolumeMount{kubeconfigMount},
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    c.KubeletCPU,
+									v1.ResourceMemory: c.KubeletMemory,
+								},
+							},
+						},
+						{
+							Name:         "hollow-proxy",
+							Image:        c.Image,
+							Env:          []v1.EnvVar{nodeNameEnv},
+							Command:      proxyCommand,
+							VolumeMounts: []v1.VolumeMount{kubeconfigMount},
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    c.ProxyCPU,
+									v1.ResourceMemory: c.ProxyMemory,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// GenerateAll returns all manifests needed to run the fleet, in apply order.
+func (c *KubemarkConfiguration) GenerateAll() []runtime.Object {
+	c.SetDefaults()
+	objs := []runtime.Object{c.MakeNamespace()}
+	if secret := c.MakeKubeconfigSecret(); secret != nil {
+		objs = append(objs, secret)
+	}
+	objs = append(objs, c.MakeRBAC()...)
+	objs = append(objs, c.MakeDeployment())
+	return objs
+}
+
+// WriteManifests serializes the given objects as a multi-document YAML
+// stream.
+func WriteManifests(w io.Writer, objs []runtime.Object) error {
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("error while marshalling manifest %d: %w", i, err)
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func labelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSetDefaults(t *testing.T) {
+	c := &KubemarkConfiguration{}
+	c.SetDefaults()
+	if c.Name != "hollow-node" || c.Namespace != "kubemark" || c.NumNodes != 1 {
+		t.Errorf("unexpected defaults: name=%q namespace=%q numNodes=%d", c.Name, c.Namespace, c.NumNodes)
+	}
+	if c.KubeconfigSecret != "kubeconfig" {
+		t.Errorf("KubeconfigSecret defaulted to %q", c.KubeconfigSecret)
+	}
+	if c.KubeletCPU.String() != "40m" || c.KubeletMemory.String() != "100Mi" {
+		t.Errorf("kubelet requests defaulted to cpu=%s memory=%s", c.KubeletCPU.String(), c.KubeletMemory.String())
+	}
+
+	// Explicit values survive defaulting.
+	c = &KubemarkConfiguration{Name: "perf", NumNodes: 500}
+	c.SetDefaults()
+	if c.Name != "perf" || c.NumNodes != 500 {
+		t.Errorf("explicit values overwritten: name=%q numNodes=%d", c.Name, c.NumNodes)
+	}
+}
+
+func TestGenerateAll(t *testing.T) {
+	c := &KubemarkConfiguration{}
+	objs := c.GenerateAll()
+	// Without kubeconfig content: Namespace, two ClusterRoleBindings and the
+	// Deployment, in apply order.
+	if len(objs) != 4 {
+		t.Fatalf("GenerateAll returned %d objects, want 4", len(objs))
+	}
+	if _, ok := objs[0].(*v1.Namespace); !ok {
+		t.Errorf("first object is %T, want *v1.Namespace", objs[0])
+	}
+	if _, ok := objs[len(objs)-1].(*appsv1.Deployment); !ok {
+		t.Errorf("last object is %T, want *appsv1.Deployment", objs[len(objs)-1])
+	}
+
+	c = &KubemarkConfiguration{Kubeconfig: []byte("apiVersion: v1")}
+	objs = c.GenerateAll()
+	if len(objs) != 5 {
+		t.Fatalf("GenerateAll with kubeconfig returned %d objects, want 5", len(objs))
+	}
+	secret, ok := objs[1].(*v1.Secret)
+	if !ok {
+		t.Fatalf("second object is %T, want *v1.Secret", objs[1])
+	}
+	for _, key := range []string{"kubelet.kubeconfig", "kubeproxy.kubeconfig"} {
+		if string(secret.Data[key]) != "apiVersion: v1" {
+			t.Errorf("secret key %q = %q, want the embedded kubeconfig", key, secret.Data[key])
+		}
+	}
+}
+
+func TestMakeDeployment(t *testing.T) {
+	c := &KubemarkConfiguration{
+		NumNodes:      50,
+		NodeLabels:    map[string]string{"b": "2", "a": "1"},
+		KubeletParams: []string{"--max-pods=200"},
+	}
+	c.SetDefaults()
+	deployment := c.MakeDeployment()
+
+	if *deployment.Spec.Replicas != 50 {
+		t.Errorf("replicas = %d, want 50", *deployment.Spec.Replicas)
+	}
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) != 2 {
+		t.Fatalf("deployment has %d containers, want hollow-kubelet and hollow-proxy", len(containers))
+	}
+	kubelet := containers[0]
+	if kubelet.Name != "hollow-kubelet" {
+		t.Errorf("first container is %q, want hollow-kubelet", kubelet.Name)
+	}
+	command := strings.Join(kubelet.Command, " ")
+	if !strings.Contains(command, "--morph=kubelet") {
+		t.Errorf("kubelet command %q lacks --morph=kubelet", command)
+	}
+	// Node labels are rendered sorted by key so output is deterministic.
+	if !strings.Contains(command, "--node-labels=a=1,b=2") {
+		t.Errorf("kubelet command %q lacks sorted --node-labels", command)
+	}
+	if !strings.Contains(command, "--max-pods=200") {
+		t.Errorf("kubelet command %q lacks the extra kubelet param", command)
+	}
+	if containers[1].Name != "hollow-proxy" {
+		t.Errorf("second container is %q, want hollow-proxy", containers[1].Name)
+	}
+}
+
+func TestWriteManifests(t *testing.T) {
+	c := &KubemarkConfiguration{}
+	objs := c.GenerateAll()
+	var buf bytes.Buffer
+	if err := WriteManifests(&buf, objs); err != nil {
+		t.Fatalf("WriteManifests returned unexpected error: %v", err)
+	}
+	out := buf.String()
+	if got := strings.Count(out, "---\n"); got != len(objs)-1 {
+		t.Errorf("output has %d document separators, want %d", got, len(objs)-1)
+	}
+	for _, kind := range []string{"kind: Namespace", "kind: ClusterRoleBinding", "kind: Deployment"} {
+		if !strings.Contains(out, kind) {
+			t.Errorf("output lacks %q", kind)
+		}
+	}
+}
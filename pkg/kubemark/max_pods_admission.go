@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// MaxPodsAdmissionHandler rejects pods at admission once the node holds
+// max-pods active pods, with the same OutOfpods reason a real kubelet uses.
+// Hollow kubelets otherwise admit everything, so scheduler/node-capacity
+// races (two binds landing on the last slot, stale Node allocatable) never
+// surface the OutOfpods failures they produce on real nodes. Unlike
+// PriorityAdmissionHandler this never evicts: capacity is enforced
+// unconditionally, regardless of pod priority.
+type MaxPodsAdmissionHandler struct {
+	MaxPods       int
+	GetActivePods func() []*v1.Pod
+}
+
+var _ lifecycle.PodAdmitHandler = &MaxPodsAdmissionHandler{}
+
+// NewMaxPodsAdmissionHandler creates the handler for the given capacity.
+func NewMaxPodsAdmissionHandler(maxPods int, getActivePods func() []*v1.Pod) *MaxPodsAdmissionHandler {
+	return &MaxPodsAdmissionHandler{
+		MaxPods:       maxPods,
+		GetActivePods: getActivePods,
+	}
+}
+
+// Admit implements lifecycle.PodAdmitHandler.
+func (h *MaxPodsAdmissionHandler) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	active := attrs.OtherPods
+	if active == nil && h.GetActivePods != nil {
+		active = h.GetActivePods()
+	}
+	if h.MaxPods <= 0 || len(active) < h.MaxPods {
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+	return lifecycle.PodAdmitResult{
+		Admit:   false,
+		Reason:  "OutOfpods",
+		Message: fmt.Sprintf("Node didn't have enough resource: pods, requested: 1, used: %d, capacity: %d", len(active), h.MaxPods),
+	}
+}
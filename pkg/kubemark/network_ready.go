@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	nodeutil "k8s.io/component-helpers/node/util"
+	"k8s.io/klog/v2"
+)
+
+// NetworkReadinessSimulator makes a hollow node start with
+// NetworkUnavailable=true and clears the condition after a configurable
+// delay, standing in for the cloud route controller so the full
+// node-becoming-schedulable sequence matches real cloud clusters.
+type NetworkReadinessSimulator struct {
+	Client   clientset.Interface
+	NodeName string
+	// ReadyAfter is how long after registration the simulated route is
+	// considered created.
+	ReadyAfter time.Duration
+}
+
+// NewNetworkReadinessSimulator creates a simulator that clears
+// NetworkUnavailable after the given delay.
+func NewNetworkReadinessSimulator(client clientset.Interface, nodeName string, readyAfter time.Duration) *NetworkReadinessSimulator {
+	return &NetworkReadinessSimulator{
+		Client:     client,
+		NodeName:   nodeName,
+		ReadyAfter: readyAfter,
+	}
+}
+
+// Run marks the node NetworkUnavailable once it registers and clears the
+// condition once ReadyAfter has elapsed. It returns immediately.
+func (s *NetworkReadinessSimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		start := simClock.Now()
+		// Wait for the node object to exist, then raise the condition the
+		// way a cloud node starts: no route created yet.
+		err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+			_, err := s.Client.CoreV1().Nodes().Get(context.TODO(), s.NodeName, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			return true, s.setCondition(v1.ConditionTrue, "NoRouteCreated", "Node created without a route")
+		}, stopCh)
+		if err != nil {
+			klog.Warningf("Failed to mark node %s NetworkUnavailable: %v", s.NodeName, err)
+			return
+		}
+
+		remaining := s.ReadyAfter - simClock.Since(start)
+		if remaining > 0 {
+			select {
+			case <-stopCh:
+				return
+			case <-simClock.After(remaining):
+			}
+		}
+		if err := s.setCondition(v1.ConditionFalse, "RouteCreated", "RouteController created a route"); err != nil {
+			klog.Warningf("Failed to clear NetworkUnavailable on node %s: %v", s.NodeName, err)
+			return
+		}
+		klog.V(2).Infof("Cleared NetworkUnavailable on node %s after %v", s.NodeName, simClock.Since(start))
+	}()
+}
+
+func (s *NetworkReadinessSimulator) setCondition(status v1.ConditionStatus, reason, message string) error {
+	return nodeutil.SetNodeCondition(s.Client, types.NodeName(s.NodeName), v1.NodeCondition{
+		Type:               v1.NodeNetworkUnavailable,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"time"
+
+	cadvisorapi "github.com/google/cadvisor/info/v1"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// defaultNetworkInterface is the interface name synthetic traffic is
+// reported on, matching the interface the summary API singles out.
+const defaultNetworkInterface = "eth0"
+
+// NetworkTraffic describes synthetic network traffic on a hollow node. The
+// cumulative rx/tx counters in node and pod stats grow at the configured
+// rates, so network-usage dashboards and network-based autoscaling inputs
+// see steady non-zero data from kubemark.
+type NetworkTraffic struct {
+	// RxBytesPerSecond and TxBytesPerSecond are the node-level traffic
+	// rates; each pod carries a stable per-pod share of them.
+	RxBytesPerSecond uint64
+	TxBytesPerSecond uint64
+	// ErrorsPerHour is the rate of rx and tx errors on the node interface.
+	ErrorsPerHour uint64
+	// Start is when the counters began accumulating.
+	Start time.Time
+}
+
+// NewNetworkTraffic creates a traffic description whose counters start
+// accumulating now.
+func NewNetworkTraffic(rxBytesPerSecond, txBytesPerSecond, errorsPerHour uint64) *NetworkTraffic {
+	return &NetworkTraffic{
+		RxBytesPerSecond: rxBytesPerSecond,
+		TxBytesPerSecond: txBytesPerSecond,
+		ErrorsPerHour:    errorsPerHour,
+		Start:            simClock.Now(),
+	}
+}
+
+// NodeNetworkStats returns the node's cumulative interface counters at the
+// given time, in the cadvisor shape the summary API reads for the root
+// cgroup.
+func (t *NetworkTraffic) NodeNetworkStats(now time.Time) *cadvisorapi.NetworkStats {
+	elapsed := now.Sub(t.Start).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	errors := uint64(elapsed * float64(t.ErrorsPerHour) / 3600)
+	iface := cadvisorapi.InterfaceStats{
+		Name:     defaultNetworkInterface,
+		RxBytes:  uint64(elapsed * float64(t.RxBytesPerSecond)),
+		RxErrors: errors,
+		TxBytes:  uint64(elapsed * float64(t.TxBytesPerSecond)),
+		TxErrors: errors,
+	}
+	return &cadvisorapi.NetworkStats{
+		InterfaceStats: iface,
+		Interfaces:     []cadvisorapi.InterfaceStats{iface},
+	}
+}
+
+// SandboxNetworkUsage returns cumulative interface counters for one pod
+// sandbox, accumulating since the sandbox was created at a stable per-pod
+// share of the node rates.
+func (t *NetworkTraffic) SandboxNetworkUsage(sandboxID string, createdAt int64, now time.Time) *runtimeapi.NetworkUsage {
+	elapsed := float64(now.UnixNano()-createdAt) / float64(time.Second)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	// Each pod carries 1-10% of the node's traffic, picked stably from its
+	// sandbox ID.
+	share := 0.01 + 0.09*containerFraction(sandboxID)
+	iface := &runtimeapi.NetworkInterfaceUsage{
+		Name:     defaultNetworkInterface,
+		RxBytes:  &runtimeapi.UInt64Value{Value: uint64(elapsed * share * float64(t.RxBytesPerSecond))},
+		RxErrors: &runtimeapi.UInt64Value{Value: 0},
+		TxBytes:  &runtimeapi.UInt64Value{Value: uint64(elapsed * share * float64(t.TxBytesPerSecond))},
+		TxErrors: &runtimeapi.UInt64Value{Value: 0},
+	}
+	return &runtimeapi.NetworkUsage{
+		Timestamp:        now.UnixNano(),
+		DefaultInterface: iface,
+	}
+}
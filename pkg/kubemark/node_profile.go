@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"os"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/kubelet/sysctl"
+	"sigs.k8s.io/yaml"
+)
+
+// NodeProfileNUMA describes the synthetic CPU/NUMA layout of a profile.
+type NodeProfileNUMA struct {
+	Sockets        int `json:"sockets"`
+	CoresPerSocket int `json:"coresPerSocket"`
+	ThreadsPerCore int `json:"threadsPerCore"`
+}
+
+// NodeProfileEvents configures the event broadcaster of hollow kubelets
+// using this profile, so experiments can compare apiserver event load under
+// different kubelet event throttling configurations. Zero values fall back
+// to the client-go defaults.
+type NodeProfileEvents struct {
+	// RecordQPS and RecordBurst are the client-side rate limit on event
+	// writes to the apiserver.
+	RecordQPS   float32 `json:"recordQPS,omitempty"`
+	RecordBurst int     `json:"recordBurst,omitempty"`
+	// MaxEvents is how many similar events may be emitted before the
+	// correlator starts aggregating them into a single event.
+	MaxEvents int `json:"maxEvents,omitempty"`
+	// AggregationInterval is the window within which similar events count
+	// toward aggregation.
+	AggregationInterval metav1.Duration `json:"aggregationInterval,omitempty"`
+	// SpamQPS and SpamBurst are the per-source token bucket of the event
+	// spam filter.
+	SpamQPS   float32 `json:"spamQPS,omitempty"`
+	SpamBurst int     `json:"spamBurst,omitempty"`
+}
+
+// NodeProfileClient configures the apiserver client encoding of hollow
+// components using this profile. Running profiles with different encodings
+// side by side lets serialization cost (JSON vs proto, gzip on vs off) be
+// A/B measured within a single simulated cluster. Unset fields keep the
+// command-line defaults.
+type NodeProfileClient struct {
+	// ContentType is the wire format of request bodies, either
+	// "application/vnd.kubernetes.protobuf" or "application/json".
+	ContentType string `json:"contentType,omitempty"`
+	// AcceptContentTypes is the Accept header sent for responses; empty
+	// falls back to ContentType.
+	AcceptContentTypes string `json:"acceptContentTypes,omitempty"`
+	// DisableCompression, when set, toggles gzip compression of responses.
+	DisableCompression *bool `json:"disableCompression,omitempty"`
+}
+
+// NodeProfile is a YAML description of the resource shape of a node, so a
+// hollow node can look like a specific real instance type. Resource values
+// use the usual Kubernetes quantity syntax.
+//
+// Example:
+//
+//	name: n1-standard-8
+//	architecture: arm64
+//	capacity:
+//	  cpu: "8"
+//	  memory: 30Gi
+//	  ephemeral-storage: 100Gi
+//	  pods: "110"
+//	  nvidia.com/gpu: "1"
+//	systemReserved:
+//	  cpu: 100m
+//	  memory: 1Gi
+//	kubeReserved:
+//	  cpu: 100m
+//	  memory: 1Gi
+//	numa:
+//	  sockets: 1
+//	  coresPerSocket: 4
+//	  threadsPerCore: 2
+//	events:
+//	  recordQPS: 5
+//	  recordBurst: 10
+//	  maxEvents: 10
+//	  aggregationInterval: 10m
+//	client:
+//	  contentType: application/json
+//	  disableCompression: true
+//	allowedUnsafeSysctls:
+//	- kernel.msg*
+//	- net.core.somaxconn
+type NodeProfile struct {
+	Name string `json:"name,omitempty"`
+	// Architecture, when set (e.g. "arm64", "amd64"), is reported in the
+	// node's nodeInfo and kubernetes.io/arch label instead of the build
+	// architecture, so a simulated fleet can mix architectures.
+	Architecture   string             `json:"architecture,omitempty"`
+	Capacity       map[string]string  `json:"capacity,omitempty"`
+	SystemReserved map[string]string  `json:"systemReserved,omitempty"`
+	KubeReserved   map[string]string  `json:"kubeReserved,omitempty"`
+	NUMA           *NodeProfileNUMA   `json:"numa,omitempty"`
+	Events         *NodeProfileEvents `json:"events,omitempty"`
+	Client         *NodeProfileClient `json:"client,omitempty"`
+	// AllowedUnsafeSysctls are the unsafe sysctls (or sysctl patterns ending
+	// in "*") this node profile supports. The hollow kubelet rejects pods
+	// requesting sysctls outside the safe set and this list with
+	// SysctlForbidden, as a real kubelet does, so sysctl-policy rollouts can
+	// be tested against a heterogeneous fleet.
+	AllowedUnsafeSysctls []string `json:"allowedUnsafeSysctls,omitempty"`
+}
+
+// LoadNodeProfile reads and validates a node profile from a YAML file.
+func LoadNodeProfile(path string) (*NodeProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading node profile from %v: %w", path, err)
+	}
+	profile := &NodeProfile{}
+	if err := yaml.UnmarshalStrict(data, profile); err != nil {
+		return nil, fmt.Errorf("error while parsing node profile %v: %w", path, err)
+	}
+	// Fail on malformed quantities at startup rather than on first use.
+	for _, resources := range []map[string]string{profile.Capacity, profile.SystemReserved, profile.KubeReserved} {
+		if _, err := ParseResourceList(resources); err != nil {
+			return nil, fmt.Errorf("invalid node profile %v: %w", path, err)
+		}
+	}
+	if len(profile.AllowedUnsafeSysctls) > 0 {
+		if _, err := sysctl.NewAllowlist(profile.AllowedUnsafeSysctls); err != nil {
+			return nil, fmt.Errorf("invalid node profile %v: %w", path, err)
+		}
+	}
+	if profile.Client != nil {
+		switch profile.Client.ContentType {
+		case "", runtime.ContentTypeJSON, runtime.ContentTypeProtobuf:
+		default:
+			return nil, fmt.Errorf("invalid node profile %v: unsupported client contentType %q", path, profile.Client.ContentType)
+		}
+	}
+	return profile, nil
+}
+
+// ApplyClientConfig overlays the profile's client encoding settings onto a
+// rest config, leaving unset fields alone.
+func (p *NodeProfile) ApplyClientConfig(config *restclient.Config) {
+	if p.Client == nil {
+		return
+	}
+	if p.Client.ContentType != "" {
+		config.ContentType = p.Client.ContentType
+	}
+	if p.Client.AcceptContentTypes != "" {
+		config.AcceptContentTypes = p.Client.AcceptContentTypes
+	}
+	if p.Client.DisableCompression != nil {
+		config.DisableCompression = *p.Client.DisableCompression
+	}
+}
+
+// CapacityList returns the profile's capacity as a ResourceList.
+func (p *NodeProfile) CapacityList() v1.ResourceList {
+	capacity, _ := ParseResourceList(p.Capacity)
+	return capacity
+}
+
+// SystemReservedList returns the profile's system-reserved resources.
+func (p *NodeProfile) SystemReservedList() v1.ResourceList {
+	reserved, _ := ParseResourceList(p.SystemReserved)
+	return reserved
+}
+
+// KubeReservedList returns the profile's kube-reserved resources.
+func (p *NodeProfile) KubeReservedList() v1.ResourceList {
+	reserved, _ := ParseResourceList(p.KubeReserved)
+	return reserved
+}
+
+// AllocatableReservation sums system- and kube-reserved per resource, the
+// value GetNodeAllocatableReservation reports.
+func (p *NodeProfile) AllocatableReservation() v1.ResourceList {
+	reservation := v1.ResourceList{}
+	for _, reserved := range []v1.ResourceList{p.SystemReservedList(), p.KubeReservedList()} {
+		for name, quantity := range reserved {
+			total := reservation[name]
+			total.Add(quantity)
+			reservation[name] = total
+		}
+	}
+	return reservation
+}
+
+// ParseResourceList converts a name->quantity string map into a
+// ResourceList, failing on the first malformed quantity.
+func ParseResourceList(resources map[string]string) (v1.ResourceList, error) {
+	list := v1.ResourceList{}
+	for name, value := range resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for resource %q: %w", value, name, err)
+		}
+		list[v1.ResourceName(name)] = quantity
+	}
+	return list, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"math/rand"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+)
+
+var oomKillsTotal = metrics.NewCounter(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "simulated_oom_kills_total",
+		Help:           "Number of simulated container OOM kills injected on this hollow node.",
+		StabilityLevel: metrics.ALPHA,
+	},
+)
+
+func init() {
+	legacyregistry.MustRegister(oomKillsTotal)
+}
+
+// OOMSimulator randomly OOM-kills running containers that carry a memory
+// limit, at a configured average rate. The kill only flips the container to
+// exited with code 137 and reason OOMKilled in the fake runtime; the real
+// kubelet then produces the Terminated(reason=OOMKilled) status, bumps
+// restartCount per the pod's restartPolicy, and Job backoff handling sees a
+// genuine failure. The node-level OOMKilling event a real kubelet's OOM
+// watcher records is emitted alongside, so alerting pipelines keyed on it
+// fire too.
+type OOMSimulator struct {
+	Runtime  *apitest.FakeRuntimeService
+	NodeName string
+	// RatePerHour is the average number of OOM kills per hour on this node.
+	RatePerHour float64
+	recorder    record.EventRecorder
+	nodeRef     *v1.ObjectReference
+}
+
+// NewOOMSimulator creates a simulator OOM-killing containers at the given
+// average rate per hour. A nil client disables the OOMKilling events.
+func NewOOMSimulator(client clientset.Interface, runtime *apitest.FakeRuntimeService, nodeName string, ratePerHour float64) *OOMSimulator {
+	s := &OOMSimulator{
+		Runtime:     runtime,
+		NodeName:    nodeName,
+		RatePerHour: ratePerHour,
+	}
+	if client != nil {
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: client.CoreV1().Events("")})
+		s.recorder = eventBroadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "hollow-kubelet", Host: nodeName})
+		s.nodeRef = &v1.ObjectReference{Kind: "Node", Name: nodeName, UID: types.UID(nodeName), Namespace: ""}
+	}
+	return s
+}
+
+// Run OOM-kills containers until stopCh is closed.
+func (s *OOMSimulator) Run(stopCh <-chan struct{}) {
+	if s.RatePerHour <= 0 {
+		return
+	}
+	interval := time.Duration(float64(time.Hour) / s.RatePerHour)
+	go func() {
+		ticker := simClock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.killOne()
+			}
+		}
+	}()
+}
+
+// killOne picks a random running container with a memory limit and marks it
+// OOM-killed.
+func (s *OOMSimulator) killOne() {
+	s.Runtime.Lock()
+	defer s.Runtime.Unlock()
+	var victims []*apitest.FakeContainer
+	for _, c := range s.Runtime.Containers {
+		if c.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		if c.LinuxResources == nil || c.LinuxResources.MemoryLimitInBytes <= 0 {
+			// Only memory-heavy pods, i.e. containers that declare a
+			// memory limit, are OOM candidates.
+			continue
+		}
+		victims = append(victims, c)
+	}
+	if len(victims) == 0 {
+		return
+	}
+	victim := victims[rand.Intn(len(victims))]
+	victim.State = runtimeapi.ContainerState_CONTAINER_EXITED
+	victim.FinishedAt = simClock.Now().UnixNano()
+	victim.ExitCode = 137
+	victim.Reason = "OOMKilled"
+	victim.Message = "simulated out-of-memory kill"
+	oomKillsTotal.Inc()
+
+	name := victim.Metadata.GetName()
+	if sandbox, ok := s.Runtime.Sandboxes[victim.SandboxID]; ok {
+		klog.V(2).Infof("Simulated OOM kill of container %s in pod %s/%s", name, sandbox.Metadata.Namespace, sandbox.Metadata.Name)
+	}
+	if s.recorder != nil {
+		// Same node-level event the kubelet's OOM watcher records.
+		s.recorder.Eventf(s.nodeRef, v1.EventTypeWarning, "OOMKilling",
+			"Memory cgroup out of memory: Killed process %d (%s)", rand.Intn(60000)+1000, name)
+	}
+}
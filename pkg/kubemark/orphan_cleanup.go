@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var orphanedPodsCleanedTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "orphaned_pods_cleaned_total",
+		Help:           "Number of orphaned pods cleaned up by hollow nodes after a restart, i.e. pods recorded before the restart that are no longer bound to the node in the API.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"node"},
+)
+
+func init() {
+	legacyregistry.MustRegister(orphanedPodsCleanedTotal)
+}
+
+// recordedPod is one entry in the running-pods state file.
+type recordedPod struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+}
+
+// OrphanedPodCleaner persists the set of pods bound to a hollow node and,
+// after a restart, runs the orphaned-pod cleanup pass a real kubelet
+// performs: pods recorded before the restart but no longer in the API are
+// removed from local state, with logs and metrics, so restart-related
+// cleanup storms are represented. State is kept in the node's root
+// directory, which survives restarts when a persistent root is configured.
+type OrphanedPodCleaner struct {
+	Client   clientset.Interface
+	NodeName string
+	// StateDir is the node root directory holding the running-pods file.
+	StateDir string
+}
+
+// NewOrphanedPodCleaner creates a cleaner persisting state under stateDir.
+func NewOrphanedPodCleaner(client clientset.Interface, nodeName, stateDir string) *OrphanedPodCleaner {
+	return &OrphanedPodCleaner{
+		Client:   client,
+		NodeName: nodeName,
+		StateDir: stateDir,
+	}
+}
+
+func (c *OrphanedPodCleaner) stateFile() string {
+	return filepath.Join(c.StateDir, "running-pods.json")
+}
+
+// CleanupOnStart removes pods recorded by a previous run that are no longer
+// bound to this node. It should be called once during startup.
+func (c *OrphanedPodCleaner) CleanupOnStart() {
+	data, err := os.ReadFile(c.stateFile())
+	if err != nil {
+		// First start, or no persistent root directory: nothing to clean.
+		return
+	}
+	var recorded []recordedPod
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		klog.Warningf("Ignoring unparseable running-pods state %s: %v", c.stateFile(), err)
+		return
+	}
+
+	current, err := c.listBoundPods()
+	if err != nil {
+		klog.Warningf("Skipping orphaned pod cleanup on node %s: %v", c.NodeName, err)
+		return
+	}
+	currentUIDs := make(map[types.UID]bool, len(current))
+	for _, pod := range current {
+		currentUIDs[pod.UID] = true
+	}
+
+	cleaned := 0
+	for _, pod := range recorded {
+		if currentUIDs[pod.UID] {
+			continue
+		}
+		klog.V(2).Infof("Orphaned pod %q found on node %s, removing", string(pod.UID), c.NodeName)
+		// Remove whatever per-pod state a previous run left behind.
+		if err := os.RemoveAll(filepath.Join(c.StateDir, "pods", string(pod.UID))); err != nil {
+			klog.Warningf("Failed to remove state of orphaned pod %q: %v", string(pod.UID), err)
+		}
+		orphanedPodsCleanedTotal.WithLabelValues(c.NodeName).Inc()
+		cleaned++
+	}
+	if cleaned > 0 {
+		klog.Infof("Cleaned up %d orphaned pods on node %s after restart", cleaned, c.NodeName)
+	}
+}
+
+// Run keeps the running-pods state file up to date until stopCh is closed.
+func (c *OrphanedPodCleaner) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				if err := c.record(); err != nil {
+					klog.V(3).Infof("Failed to record running pods for node %s: %v", c.NodeName, err)
+				}
+			}
+		}
+	}()
+}
+
+func (c *OrphanedPodCleaner) record() error {
+	pods, err := c.listBoundPods()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(pods)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.stateFile(), data, 0644)
+}
+
+func (c *OrphanedPodCleaner) listBoundPods() ([]recordedPod, error) {
+	pods, err := c.Client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", c.NodeName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	recorded := make([]recordedPod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		recorded = append(recorded, recordedPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+		})
+	}
+	return recorded, nil
+}
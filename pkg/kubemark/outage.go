@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/transport"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// OutageConfigMapNamespace is where the zone outage ConfigMap lives.
+	OutageConfigMapNamespace = "kube-system"
+	// OutageConfigMapName is the ConfigMap admins write to trigger a
+	// coordinated zone outage across the hollow fleet.
+	OutageConfigMapName = "kubemark-outage"
+
+	// OutageModeNotReady suppresses node status and lease heartbeats so
+	// affected nodes turn NotReady.
+	OutageModeNotReady = "notready"
+	// OutageModePartition fails every apiserver request from affected
+	// nodes, simulating a full network partition.
+	OutageModePartition = "partition"
+)
+
+// currentOutage is the outage published by the admin ConfigMap, shared by
+// the watcher and the transport wrapper. The `until` deadline is evaluated
+// locally so a partitioned node recovers even though it cannot see the
+// ConfigMap being cleared.
+var currentOutage = struct {
+	sync.RWMutex
+	zone  string
+	mode  string
+	until time.Time
+}{}
+
+// setOutage records the active outage spec for this process.
+func setOutage(zone, mode string, until time.Time) {
+	currentOutage.Lock()
+	defer currentOutage.Unlock()
+	currentOutage.zone = zone
+	currentOutage.mode = mode
+	currentOutage.until = until
+}
+
+// outageModeFor returns the active outage mode for the given zone, or "".
+func outageModeFor(zone string) string {
+	currentOutage.RLock()
+	defer currentOutage.RUnlock()
+	if currentOutage.zone != zone || time.Now().After(currentOutage.until) {
+		return ""
+	}
+	return currentOutage.mode
+}
+
+// OutageWatcher follows the admin outage ConfigMap so the transport wrapper
+// in this process knows when its zone is down.
+type OutageWatcher struct {
+	Client clientset.Interface
+}
+
+// NewOutageWatcher creates a watcher over the kubemark outage ConfigMap.
+func NewOutageWatcher(client clientset.Interface) *OutageWatcher {
+	return &OutageWatcher{Client: client}
+}
+
+// Run watches the outage ConfigMap until stopCh is closed.
+func (w *OutageWatcher) Run(stopCh <-chan struct{}) {
+	lw := cache.NewListWatchFromClient(
+		w.Client.CoreV1().RESTClient(), "configmaps", OutageConfigMapNamespace,
+		fields.OneTermEqualSelector("metadata.name", OutageConfigMapName))
+	_, informer := cache.NewInformer(lw, &v1.ConfigMap{}, 30*time.Second, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.apply(obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			w.apply(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			setOutage("", "", time.Time{})
+		},
+	})
+	go informer.Run(stopCh)
+}
+
+func (w *OutageWatcher) apply(obj interface{}) {
+	cm, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		return
+	}
+	until, err := time.Parse(time.RFC3339, cm.Data["until"])
+	if err != nil {
+		klog.Warningf("Ignoring outage ConfigMap with unparseable until %q: %v", cm.Data["until"], err)
+		return
+	}
+	zone, mode := cm.Data["zone"], cm.Data["mode"]
+	klog.V(2).Infof("Zone outage published: zone=%s mode=%s until=%v", zone, mode, until)
+	setOutage(zone, mode, until)
+}
+
+// outageTransport fails requests while this node's zone has an active
+// outage: everything in partition mode, only heartbeat writes in notready
+// mode.
+type outageTransport struct {
+	delegate http.RoundTripper
+	nodeName string
+	zone     string
+}
+
+// NewOutageWrapper returns a transport wrapper enforcing zone outages for a
+// hollow node in the given zone.
+func NewOutageWrapper(nodeName, zone string) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &outageTransport{delegate: rt, nodeName: nodeName, zone: zone}
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *outageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch outageModeFor(t.zone) {
+	case OutageModePartition:
+		return nil, fmt.Errorf("simulated zone outage: node %s in zone %s is partitioned from the apiserver", t.nodeName, t.zone)
+	case OutageModeNotReady:
+		if t.isHeartbeat(req) {
+			return nil, fmt.Errorf("simulated zone outage: heartbeat from node %s in zone %s dropped", t.nodeName, t.zone)
+		}
+	}
+	return t.delegate.RoundTrip(req)
+}
+
+// isHeartbeat reports whether the request is a node status or lease write
+// for this node.
+func (t *outageTransport) isHeartbeat(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return false
+	}
+	path := req.URL.Path
+	return strings.Contains(path, "/nodes/"+t.nodeName+"/status") ||
+		strings.Contains(path, "/leases/"+t.nodeName)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	statsapi "k8s.io/kubelet/pkg/apis/stats/v1alpha1"
+)
+
+// SimulatedPIDUsageAnnotation, when set on a pod, fixes the number of
+// simulated PIDs the pod consumes, overriding the flat per-pod default.
+const SimulatedPIDUsageAnnotation = "kubemark.io/simulated-pids"
+
+var simulatedRunningPIDs = metrics.NewGauge(
+	&metrics.GaugeOpts{
+		Subsystem:      "kubemark",
+		Name:           "simulated_running_pids",
+		Help:           "Current simulated node-wide process count reported via the summary API's Rlimit stats.",
+		StabilityLevel: metrics.ALPHA,
+	},
+)
+
+func init() {
+	legacyregistry.MustRegister(simulatedRunningPIDs)
+}
+
+// PIDUsageSimulator reports simulated PID capacity and usage through the
+// summary API's Rlimit stats, feeding the pid.available eviction signal.
+// Usage is a system baseline plus a per-pod share for every ready sandbox,
+// so packing pods onto the node drives pid.available down and, past the
+// eviction threshold, raises PIDPressure on the node exactly as it would on
+// a real machine running out of PIDs.
+type PIDUsageSimulator struct {
+	Runtime *apitest.FakeRuntimeService
+	// Capacity is the simulated kernel task limit, reported as MaxPID.
+	Capacity int64
+	// SystemPIDs is the baseline consumed by simulated system daemons.
+	SystemPIDs int64
+	// PIDsPerPod is the share each ready sandbox consumes, unless the pod
+	// overrides it with the SimulatedPIDUsageAnnotation.
+	PIDsPerPod int64
+}
+
+// NewPIDUsageSimulator creates a simulator deriving PID usage from the
+// sandboxes in the given fake runtime service.
+func NewPIDUsageSimulator(runtime *apitest.FakeRuntimeService, capacity, systemPIDs, pidsPerPod int64) *PIDUsageSimulator {
+	return &PIDUsageSimulator{
+		Runtime:    runtime,
+		Capacity:   capacity,
+		SystemPIDs: systemPIDs,
+		PIDsPerPod: pidsPerPod,
+	}
+}
+
+// Stats returns the simulated Rlimit stats. It has the signature of
+// pidlimit.Stats so it can be installed as the kubelet stats provider's
+// RlimitStatsFn.
+func (s *PIDUsageSimulator) Stats() (*statsapi.RlimitStats, error) {
+	s.Runtime.Lock()
+	running := s.SystemPIDs
+	for _, sandbox := range s.Runtime.Sandboxes {
+		if sandbox.State != runtimeapi.PodSandboxState_SANDBOX_READY {
+			continue
+		}
+		running += s.podPIDs(sandbox.Annotations)
+	}
+	s.Runtime.Unlock()
+
+	if running > s.Capacity {
+		running = s.Capacity
+	}
+	simulatedRunningPIDs.Set(float64(running))
+
+	capacity := s.Capacity
+	return &statsapi.RlimitStats{
+		Time:                  metav1.NewTime(simClock.Now()),
+		MaxPID:                &capacity,
+		NumOfRunningProcesses: &running,
+	}, nil
+}
+
+// podPIDs returns one pod's simulated PID consumption.
+func (s *PIDUsageSimulator) podPIDs(annotations map[string]string) int64 {
+	if value, ok := annotations[SimulatedPIDUsageAnnotation]; ok {
+		if pids, err := strconv.ParseInt(value, 10, 64); err == nil && pids >= 0 {
+			return pids
+		}
+	}
+	return s.PIDsPerPod
+}
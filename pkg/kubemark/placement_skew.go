@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// PlacementSkewConfig selects the pods to analyze and how nodes are grouped
+// for the skew report.
+type PlacementSkewConfig struct {
+	// GroupBy is the node label key that partitions the fleet into groups
+	// (e.g. the zone label, or a node-profile label).
+	GroupBy string
+	// Weights are the expected relative pod shares per group label value.
+	// Empty means every node carries equal weight, so a group's expected
+	// share is its fraction of the fleet's nodes.
+	Weights map[string]float64
+	// Namespace restricts the analyzed pods; empty analyzes all namespaces.
+	Namespace string
+	// Selector is an optional pod label selector.
+	Selector string
+}
+
+// PlacementSkewGroup is one row of the skew report.
+type PlacementSkewGroup struct {
+	Group    string
+	Nodes    int
+	Pods     int
+	Actual   float64
+	Expected float64
+	// Skew is the ratio of actual to expected share; 1.0 is a perfect
+	// match, above 1 the group is over-packed.
+	Skew float64
+}
+
+// PlacementSkewReport compares the actual pod distribution across node
+// groups against the expected weights.
+type PlacementSkewReport struct {
+	GroupBy string
+	Pods    int
+	Groups  []PlacementSkewGroup
+	// MaxSkew is the largest absolute deviation of any group's skew from
+	// 1.0, the single-number summary of how far placement drifted.
+	MaxSkew float64
+}
+
+// AnalyzePlacementSkew groups the fleet's nodes by the configured label,
+// counts the scheduled pods per group and compares each group's share
+// against its expected weight. Run it after a scheduler-policy experiment to
+// tell genuine policy effects apart from placement noise.
+func AnalyzePlacementSkew(ctx context.Context, client clientset.Interface, config PlacementSkewConfig) (*PlacementSkewReport, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list nodes, error: %w", err)
+	}
+	groupOfNode := make(map[string]string, len(nodes.Items))
+	nodesPerGroup := map[string]int{}
+	for _, node := range nodes.Items {
+		group := node.Labels[config.GroupBy]
+		if group == "" {
+			group = "<none>"
+		}
+		groupOfNode[node.Name] = group
+		nodesPerGroup[group]++
+	}
+
+	pods, err := client.CoreV1().Pods(config.Namespace).List(ctx, metav1.ListOptions{LabelSelector: config.Selector})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list pods, error: %w", err)
+	}
+	podsPerGroup := map[string]int{}
+	scheduled := 0
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		group, known := groupOfNode[pod.Spec.NodeName]
+		if !known {
+			continue
+		}
+		podsPerGroup[group]++
+		scheduled++
+	}
+
+	// Expected share per group: explicit weights, or node count.
+	weights := make(map[string]float64, len(nodesPerGroup))
+	totalWeight := 0.0
+	for group, count := range nodesPerGroup {
+		weight := float64(count)
+		if len(config.Weights) > 0 {
+			weight = config.Weights[group]
+		}
+		weights[group] = weight
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("no group carries any expected weight under label %q", config.GroupBy)
+	}
+
+	report := &PlacementSkewReport{GroupBy: config.GroupBy, Pods: scheduled}
+	for group, weight := range weights {
+		row := PlacementSkewGroup{
+			Group:    group,
+			Nodes:    nodesPerGroup[group],
+			Pods:     podsPerGroup[group],
+			Expected: weight / totalWeight,
+		}
+		if scheduled > 0 {
+			row.Actual = float64(row.Pods) / float64(scheduled)
+		}
+		if row.Expected > 0 {
+			row.Skew = row.Actual / row.Expected
+		}
+		if deviation := row.Skew - 1; deviation < 0 {
+			if -deviation > report.MaxSkew {
+				report.MaxSkew = -deviation
+			}
+		} else if deviation > report.MaxSkew {
+			report.MaxSkew = deviation
+		}
+		report.Groups = append(report.Groups, row)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool { return report.Groups[i].Group < report.Groups[j].Group })
+	return report, nil
+}
+
+// WriteReport renders the skew report as a table.
+func (r *PlacementSkewReport) WriteReport(w io.Writer) {
+	fmt.Fprintf(w, "%-24s %-8s %-8s %-10s %-10s %-8s\n", r.GroupBy, "NODES", "PODS", "ACTUAL", "EXPECTED", "SKEW")
+	for _, row := range r.Groups {
+		fmt.Fprintf(w, "%-24s %-8d %-8d %-10s %-10s %-8.2f\n",
+			row.Group, row.Nodes, row.Pods,
+			fmt.Sprintf("%.1f%%", 100*row.Actual), fmt.Sprintf("%.1f%%", 100*row.Expected), row.Skew)
+	}
+	fmt.Fprintf(w, "\n%d scheduled pods analyzed, max skew %.1f%% from expected shares.\n", r.Pods, 100*r.MaxSkew)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	nodePodsGauge = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "kubemark",
+			Name:           "node_pods",
+			Help:           "Number of pods on each hollow node in this process, partitioned by phase.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"node", "phase"},
+	)
+	nodePodUtilizationGauge = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "kubemark",
+			Name:           "node_pod_utilization",
+			Help:           "Fraction of max-pods in use on each hollow node in this process.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"node"},
+	)
+	fleetPodsGauge = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "kubemark",
+			Name:           "fleet_pods",
+			Help:           "Total number of pods across all hollow nodes in this process, partitioned by phase.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"phase"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(nodePodsGauge)
+	legacyregistry.MustRegister(nodePodUtilizationGauge)
+	legacyregistry.MustRegister(fleetPodsGauge)
+}
+
+// fleetPodCounts aggregates per-node phase counts across all trackers in the
+// process so experiment dashboards get fleet totals without scraping the
+// apiserver.
+var fleetPodCounts = struct {
+	sync.Mutex
+	perNode map[string]map[v1.PodPhase]int
+}{perNode: make(map[string]map[v1.PodPhase]int)}
+
+// PodCountTracker watches the pods bound to one hollow node and keeps the
+// per-node and fleet pod gauges up to date.
+type PodCountTracker struct {
+	Client   clientset.Interface
+	NodeName string
+	MaxPods  int
+}
+
+// NewPodCountTracker creates a tracker for the given hollow node.
+func NewPodCountTracker(client clientset.Interface, nodeName string, maxPods int) *PodCountTracker {
+	return &PodCountTracker{
+		Client:   client,
+		NodeName: nodeName,
+		MaxPods:  maxPods,
+	}
+}
+
+// Run starts watching this node's pods until stopCh is closed.
+func (t *PodCountTracker) Run(stopCh <-chan struct{}) {
+	lw := cache.NewListWatchFromClient(
+		t.Client.CoreV1().RESTClient(), "pods", "",
+		fields.OneTermEqualSelector("spec.nodeName", t.NodeName))
+	store, informer := cache.NewInformer(lw, &v1.Pod{}, 30*time.Second, cache.ResourceEventHandlerFuncs{})
+	recompute := func() {
+		counts := make(map[v1.PodPhase]int)
+		for _, obj := range store.List() {
+			pod, ok := obj.(*v1.Pod)
+			if !ok {
+				continue
+			}
+			counts[pod.Status.Phase]++
+		}
+		t.publish(counts)
+	}
+	go informer.Run(stopCh)
+	go func() {
+		ticker := simClock.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				recompute()
+			}
+		}
+	}()
+}
+
+// publish updates the per-node gauges and recomputes the fleet aggregates.
+func (t *PodCountTracker) publish(counts map[v1.PodPhase]int) {
+	total := 0
+	for _, phase := range []v1.PodPhase{v1.PodPending, v1.PodRunning, v1.PodSucceeded, v1.PodFailed} {
+		nodePodsGauge.WithLabelValues(t.NodeName, string(phase)).Set(float64(counts[phase]))
+		total += counts[phase]
+	}
+	if t.MaxPods > 0 {
+		nodePodUtilizationGauge.WithLabelValues(t.NodeName).Set(float64(total-counts[v1.PodSucceeded]-counts[v1.PodFailed]) / float64(t.MaxPods))
+	}
+
+	fleetPodCounts.Lock()
+	defer fleetPodCounts.Unlock()
+	fleetPodCounts.perNode[t.NodeName] = counts
+	fleetTotals := make(map[v1.PodPhase]int)
+	for _, nodeCounts := range fleetPodCounts.perNode {
+		for phase, count := range nodeCounts {
+			fleetTotals[phase] += count
+		}
+	}
+	for _, phase := range []v1.PodPhase{v1.PodPending, v1.PodRunning, v1.PodSucceeded, v1.PodFailed} {
+		fleetPodsGauge.WithLabelValues(string(phase)).Set(float64(fleetTotals[phase]))
+	}
+}
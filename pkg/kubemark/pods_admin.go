@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"encoding/json"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// RegisterPodsHandler serves GET /pods on the admin API with the same
+// payload as the kubelet's /pods debug endpoint: a v1.PodList of the pods
+// bound to this node. Node agents and debugging workflows that read /pods
+// break against dense hollow-node fleets, which usually run with the kubelet
+// server ports disabled to avoid per-node port fan-out; the admin API serves
+// the endpoint instead.
+func RegisterPodsHandler(client clientset.Interface, nodeName string) {
+	RegisterAdminHandler("/pods", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(req.Context(), metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		pods.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PodList"}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(pods)
+	}))
+}
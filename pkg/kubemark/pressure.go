@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// PressureProfile describes background "system usage" on a hollow node that
+// changes over time, modeling noisy neighbors and gradual memory leaks.
+type PressureProfile struct {
+	// BaseFraction is the steady-state fraction of node memory used by the
+	// simulated system processes.
+	BaseFraction float64
+	// AmplitudeFraction is the amplitude of a periodic usage oscillation on
+	// top of the base.
+	AmplitudeFraction float64
+	// Period is the oscillation period. Ignored if AmplitudeFraction is 0.
+	Period time.Duration
+	// GrowthFractionPerHour makes usage creep upward over time, simulating
+	// a leak. Usage is capped at the node's capacity.
+	GrowthFractionPerHour float64
+}
+
+// SystemPressure produces time-varying simulated system memory usage for a
+// hollow node, reflected in the node's stats.
+type SystemPressure struct {
+	Profile        PressureProfile
+	MemoryCapacity uint64
+	start          time.Time
+	// scenarioFraction is a lower bound on the usage fraction, set by
+	// pressure scenarios, stored as math.Float64bits.
+	scenarioFraction uint64
+}
+
+// NewSystemPressure creates a pressure simulation starting now.
+func NewSystemPressure(profile PressureProfile, memoryCapacity uint64) *SystemPressure {
+	return &SystemPressure{
+		Profile:        profile,
+		MemoryCapacity: memoryCapacity,
+		start:          simClock.Now(),
+	}
+}
+
+// SetScenarioFraction floors the usage fraction at the given value; 0
+// removes the floor.
+func (s *SystemPressure) SetScenarioFraction(fraction float64) {
+	atomic.StoreUint64(&s.scenarioFraction, math.Float64bits(fraction))
+}
+
+// MemoryUsage returns the simulated system memory usage in bytes at the
+// given time.
+func (s *SystemPressure) MemoryUsage(now time.Time) uint64 {
+	elapsed := now.Sub(s.start)
+	fraction := s.Profile.BaseFraction
+	fraction += s.Profile.GrowthFractionPerHour * elapsed.Hours()
+	if s.Profile.AmplitudeFraction > 0 && s.Profile.Period > 0 {
+		fraction += s.Profile.AmplitudeFraction * math.Sin(2*math.Pi*float64(elapsed)/float64(s.Profile.Period))
+	}
+	if floor := math.Float64frombits(atomic.LoadUint64(&s.scenarioFraction)); fraction < floor {
+		fraction = floor
+	}
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return uint64(fraction * float64(s.MemoryCapacity))
+}
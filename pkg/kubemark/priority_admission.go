@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+// PriorityAdmissionHandler implements priority-aware admission when a hollow
+// node is saturated. At capacity, pods with higher priority than the
+// lowest-priority pod on the node are admitted after that victim is evicted;
+// other pods are rejected with OutOfpods, matching kubelet behavior, so
+// priority/preemption experiments do not stop at the scheduler layer.
+type PriorityAdmissionHandler struct {
+	Client        clientset.Interface
+	NodeName      string
+	MaxPods       int
+	GetActivePods func() []*v1.Pod
+}
+
+var _ lifecycle.PodAdmitHandler = &PriorityAdmissionHandler{}
+
+// NewPriorityAdmissionHandler creates the handler for the given node.
+func NewPriorityAdmissionHandler(client clientset.Interface, nodeName string, maxPods int, getActivePods func() []*v1.Pod) *PriorityAdmissionHandler {
+	return &PriorityAdmissionHandler{
+		Client:        client,
+		NodeName:      nodeName,
+		MaxPods:       maxPods,
+		GetActivePods: getActivePods,
+	}
+}
+
+func podPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	return 0
+}
+
+// Admit implements lifecycle.PodAdmitHandler.
+func (h *PriorityAdmissionHandler) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	active := attrs.OtherPods
+	if active == nil && h.GetActivePods != nil {
+		active = h.GetActivePods()
+	}
+	if h.MaxPods <= 0 || len(active) < h.MaxPods {
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+
+	// The node is saturated. Find the lowest-priority victim.
+	var victim *v1.Pod
+	for _, pod := range active {
+		if victim == nil || podPriority(pod) < podPriority(victim) {
+			victim = pod
+		}
+	}
+	if victim == nil || podPriority(attrs.Pod) <= podPriority(victim) {
+		return lifecycle.PodAdmitResult{
+			Admit:   false,
+			Reason:  "OutOfpods",
+			Message: fmt.Sprintf("node is at its pod capacity (%d) and pod priority %d does not preempt any running pod", h.MaxPods, podPriority(attrs.Pod)),
+		}
+	}
+
+	// Evict the victim to make room for the higher-priority pod.
+	klog.V(2).Infof("Evicting pod %s/%s (priority %d) from node %s to admit %s/%s (priority %d)",
+		victim.Namespace, victim.Name, podPriority(victim), h.NodeName,
+		attrs.Pod.Namespace, attrs.Pod.Name, podPriority(attrs.Pod))
+	if h.Client != nil {
+		err := h.Client.CoreV1().Pods(victim.Namespace).Delete(context.TODO(), victim.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: utilpointer.Int64(0),
+		})
+		if err != nil {
+			klog.Warningf("Failed to evict victim pod %s/%s: %v", victim.Namespace, victim.Name, err)
+		}
+	}
+	return lifecycle.PodAdmitResult{Admit: true}
+}
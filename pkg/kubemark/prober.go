@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	apitest "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/klog/v2"
+	probetest "k8s.io/kubernetes/pkg/kubelet/prober/testing"
+)
+
+var probeFailuresTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "simulated_probe_failures_total",
+		Help:           "Number of simulated probe failures on this hollow node, partitioned by probe type (readiness, liveness).",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"probe"},
+)
+
+func init() {
+	legacyregistry.MustRegister(probeFailuresTotal)
+}
+
+// ProbeSimulator stands in for the kubelet's prober manager with
+// configurable probe behavior instead of unconditional success. Containers
+// stay unready for a startup delay after starting (modeling probe initial
+// delays and latency), a configurable fraction of per-container probe
+// windows reports not-ready (readiness flapping and the resulting endpoint
+// churn), and simulated liveness failures kill containers so the kubelet
+// produces genuine restart storms — enough signal for endpoint controller
+// and proxy scale tests.
+type ProbeSimulator struct {
+	probetest.FakeManager
+	// ReadyDelay is how long after starting a container stays unready.
+	ReadyDelay time.Duration
+	// FlakeFraction is the fraction of probe windows a container spends
+	// not-ready once started.
+	FlakeFraction float64
+	// FlakePeriod is the length of one probe window; readiness can change
+	// at window boundaries.
+	FlakePeriod time.Duration
+	// LivenessFailuresPerHour is the average number of simulated liveness
+	// failures per running container per hour. Each failure kills the
+	// container in Runtime, so the kubelet restarts it per restartPolicy.
+	LivenessFailuresPerHour float64
+	// Runtime is the fake runtime liveness failures act on.
+	Runtime *apitest.FakeRuntimeService
+}
+
+// NewProbeSimulator creates a probe simulator with the given readiness
+// behavior.
+func NewProbeSimulator(readyDelay time.Duration, flakeFraction float64, flakePeriod time.Duration) *ProbeSimulator {
+	if flakePeriod <= 0 {
+		flakePeriod = time.Minute
+	}
+	return &ProbeSimulator{
+		ReadyDelay:    readyDelay,
+		FlakeFraction: flakeFraction,
+		FlakePeriod:   flakePeriod,
+	}
+}
+
+// UpdatePodStatus sets the Ready state of each container from the simulated
+// probe outcomes, overriding FakeManager's unconditional success.
+func (p *ProbeSimulator) UpdatePodStatus(_ types.UID, podStatus *v1.PodStatus) {
+	now := simClock.Now()
+	for i := range podStatus.ContainerStatuses {
+		status := &podStatus.ContainerStatuses[i]
+		if status.State.Running == nil {
+			status.Ready = false
+			continue
+		}
+		status.Ready = p.ready(status.ContainerID, status.State.Running.StartedAt.Time, now)
+		if !status.Ready {
+			probeFailuresTotal.WithLabelValues("readiness").Inc()
+		}
+	}
+}
+
+// ready evaluates the simulated readiness probe of one container: unready
+// during the startup delay, then flaky per probe window. The per-window
+// outcome is a stable hash, so all readers agree on it for the whole window
+// and readiness flaps rather than flickers.
+func (p *ProbeSimulator) ready(containerID string, startedAt, now time.Time) bool {
+	if p.ReadyDelay > 0 && now.Sub(startedAt) < p.ReadyDelay {
+		return false
+	}
+	if p.FlakeFraction <= 0 {
+		return true
+	}
+	window := now.UnixNano() / int64(p.FlakePeriod)
+	return containerFraction(fmt.Sprintf("%s/%d", containerID, window)) >= p.FlakeFraction
+}
+
+// Run starts injecting liveness failures until stopCh is closed. It is a
+// no-op unless LivenessFailuresPerHour and Runtime are set.
+func (p *ProbeSimulator) Run(stopCh <-chan struct{}) {
+	if p.LivenessFailuresPerHour <= 0 || p.Runtime == nil {
+		return
+	}
+	const interval = 10 * time.Second
+	go func() {
+		ticker := simClock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				p.injectLivenessFailures(interval)
+			}
+		}
+	}()
+}
+
+// injectLivenessFailures kills each running container with the probability
+// that makes failures average LivenessFailuresPerHour.
+func (p *ProbeSimulator) injectLivenessFailures(interval time.Duration) {
+	probability := p.LivenessFailuresPerHour * interval.Hours()
+	now := simClock.Now()
+	p.Runtime.Lock()
+	defer p.Runtime.Unlock()
+	for _, c := range p.Runtime.Containers {
+		if c.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		if rand.Float64() >= probability {
+			continue
+		}
+		c.State = runtimeapi.ContainerState_CONTAINER_EXITED
+		c.FinishedAt = now.UnixNano()
+		c.ExitCode = 137
+		c.Reason = "Error"
+		c.Message = "simulated liveness probe failure"
+		probeFailuresTotal.WithLabelValues("liveness").Inc()
+		klog.V(3).Infof("Simulated liveness failure killed container %s", c.Id)
+	}
+}
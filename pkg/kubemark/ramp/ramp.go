@@ -0,0 +1,309 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ramp implements scripted multi-phase ramp tests against a
+// hollow-node fleet: the fleet (and optionally a synthetic workload) grows
+// through configured plateaus while control-plane SLIs are recorded at each
+// one, producing a single comparative report for capacity qualification.
+package ramp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubemark/benchmark"
+)
+
+// Config describes a ramp test.
+type Config struct {
+	// FleetNamespace and FleetName locate the hollow-node Deployment on the
+	// admin cluster (as produced by `kubemark generate`).
+	FleetNamespace string
+	FleetName      string
+	// Phases are the fleet sizes to plateau at, in order.
+	Phases []int
+	// Hold is how long each plateau is held while SLIs are sampled.
+	Hold time.Duration
+	// ReadyTimeout bounds how long to wait for a phase's nodes to turn Ready.
+	ReadyTimeout time.Duration
+	// SampleInterval is the spacing of API latency samples during a plateau.
+	SampleInterval time.Duration
+	// PodsPerNode, when positive, scales a pause-pod workload Deployment to
+	// PodsPerNode * nodes at each phase so plateaus carry pod churn too.
+	PodsPerNode int
+	// WorkloadNamespace is where the workload Deployment runs.
+	WorkloadNamespace string
+}
+
+// LatencySummary aggregates API request latencies sampled during a plateau.
+type LatencySummary struct {
+	Count  int
+	Errors int
+	P50    time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// PhaseReport is the outcome of one plateau.
+type PhaseReport struct {
+	// Nodes is the fleet size the phase targeted.
+	Nodes int
+	// ReadyNodes is how many nodes were Ready when the plateau started.
+	ReadyNodes int
+	// TimeToReady is how long the fleet took to converge on the target size,
+	// the node-registration SLI of the phase.
+	TimeToReady time.Duration
+	// APILatency summarizes node list latencies sampled during the plateau.
+	APILatency LatencySummary
+	// PodStart summarizes bind-to-running latencies of workload pods that
+	// started during the phase. Count is zero when no workload is configured.
+	PodStart benchmark.BindingLatencySummary
+}
+
+// Runner executes a ramp test against an admin cluster.
+type Runner struct {
+	client clientset.Interface
+	config Config
+}
+
+// NewRunner creates a ramp runner. The client must reach the cluster under
+// test, which for kubemark fleets is also where the hollow-node Deployment
+// lives unless the fleet runs on a separate admin cluster.
+func NewRunner(client clientset.Interface, config Config) *Runner {
+	if config.SampleInterval <= 0 {
+		config.SampleInterval = 5 * time.Second
+	}
+	return &Runner{client: client, config: config}
+}
+
+// Run executes the configured phases in order and returns one report per
+// phase. A phase that fails to converge within ReadyTimeout still produces a
+// report (with the partial ready count) and the ramp continues, so a single
+// degraded plateau does not discard the earlier measurements.
+func (r *Runner) Run(ctx context.Context) ([]PhaseReport, error) {
+	baseline, err := r.readyNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while counting ready nodes: %w", err)
+	}
+	klog.Infof("Starting ramp with %d ready nodes, phases %v", baseline, r.config.Phases)
+
+	reports := make([]PhaseReport, 0, len(r.config.Phases))
+	for _, nodes := range r.config.Phases {
+		report, err := r.runPhase(ctx, nodes)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// runPhase grows the fleet (and workload) to the target size, waits for
+// convergence, then holds the plateau while sampling SLIs.
+func (r *Runner) runPhase(ctx context.Context, nodes int) (PhaseReport, error) {
+	report := PhaseReport{Nodes: nodes}
+
+	measurerStop := make(chan struct{})
+	defer close(measurerStop)
+	var measurer *benchmark.BindingLatencyMeasurer
+	if r.config.PodsPerNode > 0 {
+		measurer = benchmark.NewBindingLatencyMeasurer(r.client, r.config.WorkloadNamespace)
+		if err := measurer.Run(measurerStop); err != nil {
+			return report, fmt.Errorf("error while starting binding latency measurer: %w", err)
+		}
+	}
+
+	if err := r.scaleFleet(ctx, int32(nodes)); err != nil {
+		return report, err
+	}
+	if r.config.PodsPerNode > 0 {
+		if err := r.scaleWorkload(ctx, int32(nodes*r.config.PodsPerNode)); err != nil {
+			return report, err
+		}
+	}
+
+	start := time.Now()
+	ready, converged := r.waitForReady(ctx, nodes)
+	report.ReadyNodes = ready
+	report.TimeToReady = time.Since(start)
+	if !converged {
+		klog.Warningf("Phase %d: only %d/%d nodes Ready after %v, holding plateau anyway", nodes, ready, nodes, r.config.ReadyTimeout)
+	} else {
+		klog.Infof("Phase %d: fleet Ready after %v, holding plateau for %v", nodes, report.TimeToReady.Round(time.Second), r.config.Hold)
+	}
+
+	report.APILatency = r.samplePlateau(ctx)
+	if measurer != nil {
+		report.PodStart = measurer.Summarize()
+	}
+	return report, nil
+}
+
+// scaleFleet sets the replica count of the hollow-node Deployment.
+func (r *Runner) scaleFleet(ctx context.Context, replicas int32) error {
+	deployments := r.client.AppsV1().Deployments(r.config.FleetNamespace)
+	scale, err := deployments.GetScale(ctx, r.config.FleetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error while reading scale of deployment %v/%v: %w", r.config.FleetNamespace, r.config.FleetName, err)
+	}
+	scale.Spec.Replicas = replicas
+	if _, err := deployments.UpdateScale(ctx, r.config.FleetName, scale, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error while scaling deployment %v/%v to %d: %w", r.config.FleetNamespace, r.config.FleetName, replicas, err)
+	}
+	return nil
+}
+
+// scaleWorkload scales the pause-pod workload Deployment, creating it on
+// first use.
+func (r *Runner) scaleWorkload(ctx context.Context, replicas int32) error {
+	deployments := r.client.AppsV1().Deployments(r.config.WorkloadNamespace)
+	name := r.config.FleetName + "-workload"
+	scale, err := deployments.GetScale(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err := deployments.Create(ctx, workloadDeployment(name, replicas), metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("error while creating workload deployment %v/%v: %w", r.config.WorkloadNamespace, name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading scale of deployment %v/%v: %w", r.config.WorkloadNamespace, name, err)
+	}
+	scale.Spec.Replicas = replicas
+	if _, err := deployments.UpdateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error while scaling deployment %v/%v to %d: %w", r.config.WorkloadNamespace, name, replicas, err)
+	}
+	return nil
+}
+
+// waitForReady polls until at least want nodes are Ready or ReadyTimeout
+// elapses, returning the final ready count and whether the fleet converged.
+func (r *Runner) waitForReady(ctx context.Context, want int) (int, bool) {
+	deadline := time.Now().Add(r.config.ReadyTimeout)
+	for {
+		ready, err := r.readyNodes(ctx)
+		if err != nil {
+			klog.Warningf("Failed to count ready nodes: %v", err)
+		} else if ready >= want {
+			return ready, true
+		}
+		if time.Now().After(deadline) {
+			return ready, false
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// readyNodes counts nodes whose Ready condition is True.
+func (r *Runner) readyNodes(ctx context.Context) (int, error) {
+	nodes, err := r.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	ready := 0
+	for i := range nodes.Items {
+		for _, condition := range nodes.Items[i].Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready, nil
+}
+
+// samplePlateau holds the plateau for Hold, timing a full node list every
+// SampleInterval. Node lists scale with the fleet, so their latency is the
+// control-plane SLI most sensitive to the ramp.
+func (r *Runner) samplePlateau(ctx context.Context) LatencySummary {
+	var latencies []time.Duration
+	summary := LatencySummary{}
+	deadline := time.Now().Add(r.config.Hold)
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		_, err := r.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			summary.Errors++
+		} else {
+			latencies = append(latencies, time.Since(start))
+		}
+		time.Sleep(r.config.SampleInterval)
+	}
+
+	summary.Count = len(latencies)
+	if len(latencies) == 0 {
+		return summary
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	summary.P50 = percentile(0.50)
+	summary.P90 = percentile(0.90)
+	summary.P99 = percentile(0.99)
+	summary.Max = latencies[len(latencies)-1]
+	return summary
+}
+
+// workloadDeployment builds the pause-pod Deployment that carries the pod
+// churn of a ramp. The image is never pulled on hollow nodes.
+func workloadDeployment(name string, replicas int32) *appsv1.Deployment {
+	labels := map[string]string{"name": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: "pause", Image: "registry.k8s.io/pause:3.8"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// WriteReport renders the per-phase reports as one comparative table.
+func WriteReport(w io.Writer, reports []PhaseReport) {
+	fmt.Fprintf(w, "%-8s %-8s %-14s %-40s %-40s\n", "NODES", "READY", "TIME-TO-READY", "NODE-LIST LATENCY (P50/P90/P99/MAX)", "POD-START LATENCY (P50/P90/P99/MAX)")
+	for _, report := range reports {
+		apiLatency := fmt.Sprintf("%v/%v/%v/%v (%d samples, %d errors)",
+			report.APILatency.P50.Round(time.Millisecond), report.APILatency.P90.Round(time.Millisecond),
+			report.APILatency.P99.Round(time.Millisecond), report.APILatency.Max.Round(time.Millisecond),
+			report.APILatency.Count, report.APILatency.Errors)
+		podStart := "-"
+		if report.PodStart.Count > 0 {
+			podStart = fmt.Sprintf("%v/%v/%v/%v (%d pods)",
+				report.PodStart.P50.Round(time.Millisecond), report.PodStart.P90.Round(time.Millisecond),
+				report.PodStart.P99.Round(time.Millisecond), report.PodStart.Max.Round(time.Millisecond),
+				report.PodStart.Count)
+		}
+		fmt.Fprintf(w, "%-8d %-8d %-14v %-40s %-40s\n", report.Nodes, report.ReadyNodes, report.TimeToReady.Round(time.Second), apiLatency, podStart)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var requestBudgetViolationsTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "request_budget_violations_total",
+		Help:           "Number of API requests from this hollow node exceeding the configured per-verb-group budget, partitioned by verb group and whether the request was rejected or only reported.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"verb_group", "action"},
+)
+
+func init() {
+	legacyregistry.MustRegister(requestBudgetViolationsTotal)
+}
+
+// RequestBudget enforces an optional per-node API request budget, expressed
+// as requests per minute per verb group. It lets the simulator both model
+// well-behaved kubelets and detect when a configuration accidentally creates
+// unrealistic traffic.
+type RequestBudget struct {
+	// Limits maps verb group ("read", "write", "watch") to the allowed
+	// number of requests per minute. Absent groups are unlimited.
+	Limits map[string]int
+	// Enforce rejects over-budget requests with a synthetic 429 response.
+	// When false, violations are only counted and logged.
+	Enforce bool
+
+	lock        sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// NewRequestBudget creates a request budget from per-verb-group limits.
+func NewRequestBudget(limits map[string]int, enforce bool) *RequestBudget {
+	return &RequestBudget{
+		Limits:  limits,
+		Enforce: enforce,
+		counts:  make(map[string]int),
+	}
+}
+
+// verbGroup buckets a request the way the budget is configured: watches are
+// separated from other reads because their steady-state cost is so different.
+func verbGroup(req *http.Request) string {
+	if req.Method == http.MethodGet {
+		if req.URL.Query().Get("watch") == "true" {
+			return "watch"
+		}
+		return "read"
+	}
+	return "write"
+}
+
+// admit accounts the request and reports whether it is within budget.
+func (b *RequestBudget) admit(group string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	if now.Sub(b.windowStart) >= time.Minute {
+		b.windowStart = now
+		b.counts = make(map[string]int)
+	}
+	b.counts[group]++
+	limit, limited := b.Limits[group]
+	return !limited || b.counts[group] <= limit
+}
+
+type requestBudgetTransport struct {
+	budget   *RequestBudget
+	delegate http.RoundTripper
+}
+
+func (t *requestBudgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	group := verbGroup(req)
+	if !t.budget.admit(group) {
+		if t.budget.Enforce {
+			requestBudgetViolationsTotal.WithLabelValues(group, "rejected").Inc()
+			klog.V(3).InfoS("Rejecting over-budget API request", "verbGroup", group, "url", req.URL.Path)
+			// Mimic server-side throttling so client retry machinery engages.
+			return &http.Response{
+				Status:     "429 Too Many Requests",
+				StatusCode: http.StatusTooManyRequests,
+				Proto:      req.Proto,
+				ProtoMajor: req.ProtoMajor,
+				ProtoMinor: req.ProtoMinor,
+				Header:     http.Header{"Retry-After": []string{strconv.Itoa(int(time.Minute.Seconds()))}},
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+				Request:    req,
+			}, nil
+		}
+		requestBudgetViolationsTotal.WithLabelValues(group, "reported").Inc()
+		klog.V(3).InfoS("API request exceeds configured budget", "verbGroup", group, "url", req.URL.Path)
+	}
+	return t.delegate.RoundTrip(req)
+}
+
+// NewRequestBudgetWrapper returns a RoundTripper wrapper enforcing the given
+// budget on all requests passing through it.
+func NewRequestBudgetWrapper(budget *RequestBudget) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &requestBudgetTransport{budget: budget, delegate: rt}
+	}
+}
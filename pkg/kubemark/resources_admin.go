@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"encoding/json"
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// ResourceIntrospector is the view of the stub container manager dumped by
+// the admin resources endpoint; the stub container manager implements it.
+type ResourceIntrospector interface {
+	GetCapacity() v1.ResourceList
+	GetNodeAllocatableReservation() v1.ResourceList
+	GetDevicePluginResourceCapacity() (v1.ResourceList, v1.ResourceList, []string)
+	GetAllocatableDevices() []*podresourcesapi.ContainerDevices
+	GetDevices(podUID, containerName string) []*podresourcesapi.ContainerDevices
+}
+
+// deviceGroup is the admin API rendering of a set of devices of one resource.
+type deviceGroup struct {
+	Resource  string   `json:"resource"`
+	DeviceIDs []string `json:"deviceIds"`
+	NUMANodes []int64  `json:"numaNodes,omitempty"`
+}
+
+// resourceIntrospection is the response of GET /resources.
+type resourceIntrospection struct {
+	NodeName                string          `json:"nodeName"`
+	Capacity                v1.ResourceList `json:"capacity"`
+	AllocatableReservation  v1.ResourceList `json:"allocatableReservation,omitempty"`
+	DevicePluginCapacity    v1.ResourceList `json:"devicePluginCapacity,omitempty"`
+	DevicePluginAllocatable v1.ResourceList `json:"devicePluginAllocatable,omitempty"`
+	InactivePluginResources []string        `json:"inactivePluginResources,omitempty"`
+	AllocatableDevices      []deviceGroup   `json:"allocatableDevices,omitempty"`
+	// ContainerDevices is only present when the request names a pod UID and
+	// container, and holds that container's device allocation.
+	ContainerDevices []deviceGroup `json:"containerDevices,omitempty"`
+}
+
+// RegisterResourceIntrospectionHandler exposes GET /resources on the admin
+// API, dumping the stub container manager's current capacity, device plugin
+// resources and allocations, so operators can verify the simulated resource
+// model matches what the apiserver shows for the node. Passing ?podUID= and
+// ?container= also dumps that container's device allocation.
+func RegisterResourceIntrospectionHandler(nodeName string, introspector ResourceIntrospector) {
+	RegisterAdminHandler("/resources", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		pluginCapacity, pluginAllocatable, inactive := introspector.GetDevicePluginResourceCapacity()
+		dump := resourceIntrospection{
+			NodeName:                nodeName,
+			Capacity:                introspector.GetCapacity(),
+			AllocatableReservation:  introspector.GetNodeAllocatableReservation(),
+			DevicePluginCapacity:    pluginCapacity,
+			DevicePluginAllocatable: pluginAllocatable,
+			InactivePluginResources: inactive,
+			AllocatableDevices:      deviceGroups(introspector.GetAllocatableDevices()),
+		}
+		podUID := req.URL.Query().Get("podUID")
+		container := req.URL.Query().Get("container")
+		if podUID != "" && container != "" {
+			dump.ContainerDevices = deviceGroups(introspector.GetDevices(podUID, container))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(dump)
+	}))
+}
+
+// deviceGroups flattens podresources ContainerDevices for the admin API.
+func deviceGroups(devices []*podresourcesapi.ContainerDevices) []deviceGroup {
+	groups := make([]deviceGroup, 0, len(devices))
+	for _, d := range devices {
+		group := deviceGroup{
+			Resource:  d.GetResourceName(),
+			DeviceIDs: d.GetDeviceIds(),
+		}
+		for _, numaNode := range d.GetTopology().GetNodes() {
+			group.NUMANodes = append(group.NUMANodes, numaNode.GetID())
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	internalapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+)
+
+// PreStopSecondsAnnotation, when set on a pod, simulates a preStop hook
+// taking that many seconds before each of its containers stops.
+const PreStopSecondsAnnotation = "kubemark.io/prestop-seconds"
+
+var runtimeOperationsDuration = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Subsystem:      "kubemark",
+		Name:           "runtime_operations_duration_seconds",
+		Help:           "Duration of simulated container runtime operations, mirroring kubelet_runtime_operations_duration_seconds so runtime-operation dashboards can be validated against kubemark.",
+		Buckets:        metrics.ExponentialBuckets(0.005, 2.5, 12),
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"operation_type"},
+)
+
+func init() {
+	legacyregistry.MustRegister(runtimeOperationsDuration)
+}
+
+// simulatedRuntimeService decorates the fake CRI runtime with configurable
+// per-operation latencies and exports kubelet-compatible operation duration
+// metrics for the lifecycle-relevant operations.
+type simulatedRuntimeService struct {
+	internalapi.RuntimeService
+	operationLatency time.Duration
+	// terminationLatency, when non-zero, overrides operationLatency for
+	// stop/remove operations, delaying how quickly the hollow kubelet
+	// acknowledges pod termination so teardown-latency SLOs can be studied
+	// with realistic kubelet-side delays.
+	terminationLatency time.Duration
+	// terminationGraceCap, when positive, makes container stops take the
+	// grace period the kubelet passes down (the pod's
+	// terminationGracePeriodSeconds or an override), capped at this value —
+	// the behavior of containers that ignore SIGTERM. Pods then spend that
+	// long Terminating, so node drain and rolling-update timing is
+	// realistic. Zero stops containers immediately, the legacy behavior.
+	terminationGraceCap time.Duration
+
+	lock sync.Mutex
+	// preStopDelay remembers the per-container preStop delay resolved at
+	// creation from the pod's annotations.
+	preStopDelay map[string]time.Duration
+}
+
+// NewSimulatedRuntimeService wraps a runtime service so that container
+// start/stop style operations take the given simulated latencies and are
+// reported in the kubemark_runtime_operations_duration_seconds histogram.
+func NewSimulatedRuntimeService(delegate internalapi.RuntimeService, operationLatency, terminationLatency, terminationGraceCap time.Duration) internalapi.RuntimeService {
+	return &simulatedRuntimeService{
+		RuntimeService:      delegate,
+		operationLatency:    operationLatency,
+		terminationLatency:  terminationLatency,
+		terminationGraceCap: terminationGraceCap,
+		preStopDelay:        make(map[string]time.Duration),
+	}
+}
+
+// observe injects the simulated latency and records the total duration of
+// the operation as seen by the kubelet.
+func (s *simulatedRuntimeService) observe(operation string, start time.Time) {
+	s.observeWithLatency(operation, start, s.operationLatency)
+}
+
+// observeTermination is observe for the termination path, which has its own
+// latency knob.
+func (s *simulatedRuntimeService) observeTermination(operation string, start time.Time) {
+	latency := s.terminationLatency
+	if latency == 0 {
+		latency = s.operationLatency
+	}
+	s.observeWithLatency(operation, start, latency)
+}
+
+func (s *simulatedRuntimeService) observeWithLatency(operation string, start time.Time, latency time.Duration) {
+	if latency > 0 {
+		simClock.Sleep(latency)
+	}
+	runtimeOperationsDuration.WithLabelValues(operation).Observe(simClock.Since(start).Seconds())
+	if operation == "start_container" {
+		// The container start completes the simulated startup path, so it
+		// doubles as the in-process startup latency series.
+		RecordLatency("pod_startup", simClock.Since(start))
+	}
+}
+
+func (s *simulatedRuntimeService) RunPodSandbox(config *runtimeapi.PodSandboxConfig, runtimeHandler string) (string, error) {
+	defer s.observe("run_podsandbox", simClock.Now())
+	return s.RuntimeService.RunPodSandbox(config, runtimeHandler)
+}
+
+func (s *simulatedRuntimeService) StopPodSandbox(podSandboxID string) error {
+	defer s.observeTermination("stop_podsandbox", simClock.Now())
+	return s.RuntimeService.StopPodSandbox(podSandboxID)
+}
+
+func (s *simulatedRuntimeService) RemovePodSandbox(podSandboxID string) error {
+	defer s.observeTermination("remove_podsandbox", simClock.Now())
+	return s.RuntimeService.RemovePodSandbox(podSandboxID)
+}
+
+func (s *simulatedRuntimeService) CreateContainer(podSandboxID string, config *runtimeapi.ContainerConfig, sandboxConfig *runtimeapi.PodSandboxConfig) (string, error) {
+	defer s.observe("create_container", simClock.Now())
+	containerID, err := s.RuntimeService.CreateContainer(podSandboxID, config, sandboxConfig)
+	if value, ok := sandboxConfig.GetAnnotations()[PreStopSecondsAnnotation]; ok && err == nil {
+		if seconds, parseErr := strconv.Atoi(value); parseErr == nil && seconds >= 0 {
+			s.lock.Lock()
+			s.preStopDelay[containerID] = time.Duration(seconds) * time.Second
+			s.lock.Unlock()
+		} else {
+			klog.Warningf("Ignoring invalid %s annotation %q", PreStopSecondsAnnotation, value)
+		}
+	}
+	return containerID, err
+}
+
+func (s *simulatedRuntimeService) StartContainer(containerID string) error {
+	defer s.observe("start_container", simClock.Now())
+	return s.RuntimeService.StartContainer(containerID)
+}
+
+func (s *simulatedRuntimeService) StopContainer(containerID string, timeout int64) error {
+	defer s.observeTermination("stop_container", simClock.Now())
+	s.lock.Lock()
+	preStop := s.preStopDelay[containerID]
+	s.lock.Unlock()
+	if preStop > 0 {
+		simClock.Sleep(preStop)
+	}
+	if s.terminationGraceCap > 0 {
+		grace := time.Duration(timeout) * time.Second
+		if grace > s.terminationGraceCap {
+			grace = s.terminationGraceCap
+		}
+		if grace > 0 {
+			simClock.Sleep(grace)
+		}
+	}
+	return s.RuntimeService.StopContainer(containerID, timeout)
+}
+
+func (s *simulatedRuntimeService) RemoveContainer(containerID string) error {
+	defer s.observeTermination("remove_container", simClock.Now())
+	s.lock.Lock()
+	delete(s.preStopDelay, containerID)
+	s.lock.Unlock()
+	return s.RuntimeService.RemoveContainer(containerID)
+}
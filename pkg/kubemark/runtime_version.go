@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	internalapi "k8s.io/cri-api/pkg/apis"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// runtimeVersionService makes a hollow node report a configurable container
+// runtime name and version. The kubelet surfaces the CRI Version response as
+// the node's containerRuntimeVersion (e.g. "containerd://1.6.8"), so a fleet
+// of hollow nodes can impersonate mixed containerd/cri-o versions and
+// fleet-inventory or version-skew tooling can be validated against it.
+type runtimeVersionService struct {
+	internalapi.RuntimeService
+	name    string
+	version string
+}
+
+// NewRuntimeVersionService wraps a runtime service so Version reports the
+// given runtime name and version. Empty values keep the delegate's.
+func NewRuntimeVersionService(delegate internalapi.RuntimeService, name, version string) internalapi.RuntimeService {
+	return &runtimeVersionService{RuntimeService: delegate, name: name, version: version}
+}
+
+// Version overrides the runtime identity in the delegate's response.
+func (s *runtimeVersionService) Version(apiVersion string) (*runtimeapi.VersionResponse, error) {
+	resp, err := s.RuntimeService.Version(apiVersion)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if s.name != "" {
+		resp.RuntimeName = s.name
+	}
+	if s.version != "" {
+		resp.RuntimeVersion = s.version
+	}
+	return resp, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+)
+
+var sandboxRestartsTotal = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "sandbox_restarts_total",
+		Help:           "Number of simulated pod sandbox restarts, accounted separately from container restarts.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"node"},
+)
+
+func init() {
+	legacyregistry.MustRegister(sandboxRestartsTotal)
+}
+
+// SandboxChurner periodically restarts the sandbox of a random running pod on
+// its hollow node, emitting the same SandboxChanged event a real kubelet
+// produces. Sandbox restarts are accounted separately from container restarts
+// so tooling sensitive to sandbox churn (CNI IP leaks, istio init containers)
+// can be evaluated against kubemark.
+type SandboxChurner struct {
+	Client   clientset.Interface
+	NodeName string
+	// RatePerHour is the average number of sandbox restarts per hour on
+	// this node.
+	RatePerHour float64
+	recorder    record.EventRecorder
+}
+
+// NewSandboxChurner creates a churner restarting sandboxes at the given
+// average rate per hour.
+func NewSandboxChurner(client clientset.Interface, nodeName string, ratePerHour float64) *SandboxChurner {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "hollow-kubelet", Host: nodeName})
+	return &SandboxChurner{
+		Client:      client,
+		NodeName:    nodeName,
+		RatePerHour: ratePerHour,
+		recorder:    recorder,
+	}
+}
+
+// Run restarts sandboxes until stopCh is closed.
+func (c *SandboxChurner) Run(stopCh <-chan struct{}) {
+	if c.RatePerHour <= 0 {
+		return
+	}
+	interval := time.Duration(float64(time.Hour) / c.RatePerHour)
+	go func() {
+		ticker := simClock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				c.restartOne()
+			}
+		}
+	}()
+}
+
+// restartOne picks a random running pod on this node and simulates a sandbox
+// restart for it.
+func (c *SandboxChurner) restartOne() {
+	pods, err := c.Client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", c.NodeName).String(),
+	})
+	if err != nil {
+		klog.Warningf("Failed to list pods for sandbox churn on node %s: %v", c.NodeName, err)
+		return
+	}
+	running := make([]*v1.Pod, 0, len(pods.Items))
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == v1.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			running = append(running, &pods.Items[i])
+		}
+	}
+	if len(running) == 0 {
+		return
+	}
+	pod := running[rand.Intn(len(running))]
+	klog.V(2).Infof("Simulating sandbox restart for pod %s/%s on node %s", pod.Namespace, pod.Name, c.NodeName)
+	// Same event a real kubelet records when the sandbox dies.
+	c.recorder.Eventf(pod, v1.EventTypeNormal, "SandboxChanged", "Pod sandbox changed, it will be killed and re-created.")
+	sandboxRestartsTotal.WithLabelValues(c.NodeName).Inc()
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+)
+
+// The status the real node shutdown manager leaves on pods it terminates.
+const (
+	shutdownReason  = "Terminated"
+	shutdownMessage = "Pod was terminated in response to imminent node shutdown."
+)
+
+// ParseShutdownGracePeriods parses comma-separated priority:duration pairs
+// (e.g. "0:30s,2000000000:10s") into the kubelet's
+// shutdownGracePeriodByPodPriority representation, sorted by priority.
+func ParseShutdownGracePeriods(value string) ([]kubeletconfig.ShutdownGracePeriodByPodPriority, error) {
+	var tiers []kubeletconfig.ShutdownGracePeriodByPodPriority
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid shutdown grace period %q, want priority:duration", entry)
+		}
+		priority, err := strconv.ParseInt(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority in %q: %w", entry, err)
+		}
+		period, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in %q: %w", entry, err)
+		}
+		tiers = append(tiers, kubeletconfig.ShutdownGracePeriodByPodPriority{
+			Priority:                   int32(priority),
+			ShutdownGracePeriodSeconds: int64(period / time.Second),
+		})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Priority < tiers[j].Priority })
+	return tiers, nil
+}
+
+// GracefulShutdownSimulator reproduces the kubelet node shutdown manager's
+// priority-tiered pod teardown for hollow nodes, which cannot use the real
+// manager because it needs a systemd inhibitor lock. On shutdown, pods are
+// grouped by priority class value against the configured tiers and failed
+// tier by tier — lowest priorities first, each tier given its grace period —
+// with the same terminal status the real manager writes, so priority-tiered
+// shutdown policies can be validated before production rollout.
+type GracefulShutdownSimulator struct {
+	Client   clientset.Interface
+	NodeName string
+	// Tiers follow shutdownGracePeriodByPodPriority: a pod belongs to the
+	// tier with the highest priority not exceeding its own.
+	Tiers []kubeletconfig.ShutdownGracePeriodByPodPriority
+}
+
+// NewGracefulShutdownSimulator creates a simulator for the given node.
+func NewGracefulShutdownSimulator(client clientset.Interface, nodeName string, tiers []kubeletconfig.ShutdownGracePeriodByPodPriority) *GracefulShutdownSimulator {
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Priority < tiers[j].Priority })
+	return &GracefulShutdownSimulator{Client: client, NodeName: nodeName, Tiers: tiers}
+}
+
+// HandleSignals runs the tiered teardown on SIGTERM/SIGINT and then exits,
+// so deleting a hollow-node pod (e.g. on fleet scale-down) looks like a
+// graceful node shutdown to the cluster under test.
+func (s *GracefulShutdownSimulator) HandleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		sig := <-signals
+		klog.Infof("Received %v, starting graceful node shutdown", sig)
+		s.Shutdown()
+		os.Exit(0)
+	}()
+}
+
+// Shutdown fails this node's pods tier by tier. Heartbeats stop when the
+// process exits right after, so the node turns NotReady the same way a
+// really shut-down node does.
+func (s *GracefulShutdownSimulator) Shutdown() {
+	if len(s.Tiers) == 0 {
+		return
+	}
+	pods, err := s.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", s.NodeName).String(),
+	})
+	if err != nil {
+		klog.Errorf("Failed to list pods for shutdown: %v", err)
+		return
+	}
+
+	groups := make([][]*v1.Pod, len(s.Tiers))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		groups[s.tierFor(pod)] = append(groups[s.tierFor(pod)], pod)
+	}
+
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		tier := s.Tiers[i]
+		klog.Infof("Shutting down %d pods at priority >= %d with %ds grace", len(group), tier.Priority, tier.ShutdownGracePeriodSeconds)
+		for _, pod := range group {
+			s.failPod(pod)
+		}
+		simClock.Sleep(time.Duration(tier.ShutdownGracePeriodSeconds) * time.Second)
+	}
+}
+
+// tierFor picks the pod's shutdown tier: the highest-priority tier whose
+// priority does not exceed the pod's, clamped to the configured range, the
+// boundary behavior of the real manager's groupByPriority.
+func (s *GracefulShutdownSimulator) tierFor(pod *v1.Pod) int {
+	var priority int32
+	if pod.Spec.Priority != nil {
+		priority = *pod.Spec.Priority
+	}
+	index := sort.Search(len(s.Tiers), func(i int) bool { return s.Tiers[i].Priority >= priority })
+	if index == len(s.Tiers) || (index > 0 && s.Tiers[index].Priority > priority) {
+		index--
+	}
+	return index
+}
+
+// failPod writes the terminal status the real shutdown manager leaves.
+func (s *GracefulShutdownSimulator) failPod(pod *v1.Pod) {
+	if pod.Status.Phase != v1.PodSucceeded {
+		pod.Status.Phase = v1.PodFailed
+	}
+	pod.Status.Reason = shutdownReason
+	pod.Status.Message = shutdownMessage
+	if _, err := s.Client.CoreV1().Pods(pod.Namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{}); err != nil {
+		klog.Warningf("Failed to fail pod %s/%s during shutdown: %v", pod.Namespace, pod.Name, err)
+	}
+}
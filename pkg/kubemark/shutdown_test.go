@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"reflect"
+	"testing"
+
+	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+)
+
+func TestParseShutdownGracePeriods(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []kubeletconfig.ShutdownGracePeriodByPodPriority
+	}{
+		{
+			value: "0:30s",
+			want: []kubeletconfig.ShutdownGracePeriodByPodPriority{
+				{Priority: 0, ShutdownGracePeriodSeconds: 30},
+			},
+		},
+		{
+			// Entries are sorted by priority regardless of input order, and
+			// durations convert to whole seconds.
+			value: "2000000000:10s, 0:2m, 1000:90s",
+			want: []kubeletconfig.ShutdownGracePeriodByPodPriority{
+				{Priority: 0, ShutdownGracePeriodSeconds: 120},
+				{Priority: 1000, ShutdownGracePeriodSeconds: 90},
+				{Priority: 2000000000, ShutdownGracePeriodSeconds: 10},
+			},
+		},
+	}
+	for _, test := range tests {
+		got, err := ParseShutdownGracePeriods(test.value)
+		if err != nil {
+			t.Errorf("ParseShutdownGracePeriods(%q) returned unexpected error: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseShutdownGracePeriods(%q) = %+v, want %+v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseShutdownGracePeriodsInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"30s",
+		"abc:30s",
+		"0:soon",
+		"0:30s,1000",
+	} {
+		if _, err := ParseShutdownGracePeriods(value); err == nil {
+			t.Errorf("ParseShutdownGracePeriods(%q) succeeded, want error", value)
+		}
+	}
+}
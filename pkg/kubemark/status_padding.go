@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// PaddingImageNames fabricates image names to pad the node's status.images
+// list. Each entry is roughly the size of a real registry path plus digest.
+func PaddingImageNames(count int) []string {
+	images := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		images = append(images, fmt.Sprintf("registry.k8s.io/kubemark/padding-image-%04d@sha256:%064d", i, i))
+	}
+	return images
+}
+
+// NodeStatusPadder inflates a hollow node's status with synthetic
+// volumesInUse entries and extra conditions, reproducing the large Node
+// objects that are a known etcd/apiserver scalability pain point. The
+// kubelet overwrites volumesInUse on every status sync, so the padder
+// re-patches periodically; the resulting extra status-write churn is part of
+// the stress being modelled.
+type NodeStatusPadder struct {
+	Client     clientset.Interface
+	NodeName   string
+	Volumes    int
+	Conditions int
+}
+
+// NewNodeStatusPadder creates a padder adding the given numbers of synthetic
+// volumesInUse entries and conditions to the node status.
+func NewNodeStatusPadder(client clientset.Interface, nodeName string, volumes, conditions int) *NodeStatusPadder {
+	return &NodeStatusPadder{
+		Client:     client,
+		NodeName:   nodeName,
+		Volumes:    volumes,
+		Conditions: conditions,
+	}
+}
+
+// Run re-applies the padding every 30 seconds until stopCh is closed.
+func (p *NodeStatusPadder) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				if err := p.pad(); err != nil {
+					klog.V(3).Infof("Failed to pad status of node %s: %v", p.NodeName, err)
+				}
+			}
+		}
+	}()
+}
+
+func (p *NodeStatusPadder) pad() error {
+	status := map[string]interface{}{}
+	if p.Volumes > 0 {
+		volumes := make([]v1.UniqueVolumeName, 0, p.Volumes)
+		for i := 0; i < p.Volumes; i++ {
+			volumes = append(volumes, v1.UniqueVolumeName(fmt.Sprintf(
+				"kubernetes.io/csi/pd.csi.storage.gke.io^projects/kubemark/zones/padding/disks/%s-padding-volume-%04d", p.NodeName, i)))
+		}
+		status["volumesInUse"] = volumes
+	}
+	if p.Conditions > 0 {
+		now := metav1.Now()
+		conditions := make([]v1.NodeCondition, 0, p.Conditions)
+		for i := 0; i < p.Conditions; i++ {
+			conditions = append(conditions, v1.NodeCondition{
+				Type:               v1.NodeConditionType(fmt.Sprintf("KubemarkPadding%04d", i)),
+				Status:             v1.ConditionFalse,
+				Reason:             "StatusPadding",
+				Message:            strings.Repeat("x", 128),
+				LastHeartbeatTime:  now,
+				LastTransitionTime: now,
+			})
+		}
+		status["conditions"] = conditions
+	}
+	if len(status) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"status": status})
+	if err != nil {
+		return err
+	}
+	_, err = p.Client.CoreV1().Nodes().PatchStatus(context.TODO(), p.NodeName, patch)
+	return err
+}
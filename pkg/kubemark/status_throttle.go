@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var podStatusUpdates = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "pod_status_updates_total",
+		Help:           "Number of pod status update requests from this hollow node, partitioned by whether they were sent or throttled. Throttled updates are retried by the status manager with the latest status, effectively coalescing them.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"action"},
+)
+
+func init() {
+	legacyregistry.MustRegister(podStatusUpdates)
+}
+
+// PodStatusThrottle is a node-wide token bucket capping the rate of pod
+// status updates. It is shared by all clients the hollow component creates,
+// so the cap holds per node regardless of how many clients write status.
+type PodStatusThrottle struct {
+	// QPS is the sustained pod status update rate allowed; Burst is how
+	// many updates may go out back-to-back.
+	QPS   float64
+	Burst int
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewPodStatusThrottle creates a throttle allowing the given sustained rate
+// of pod status updates with the given burst.
+func NewPodStatusThrottle(qps float64, burst int) *PodStatusThrottle {
+	if burst < 1 {
+		burst = 1
+	}
+	return &PodStatusThrottle{
+		QPS:    qps,
+		Burst:  burst,
+		tokens: float64(burst),
+		last:   simClock.Now(),
+	}
+}
+
+// admit consumes a token if one is available.
+func (t *PodStatusThrottle) admit() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := simClock.Now()
+	t.tokens += now.Sub(t.last).Seconds() * t.QPS
+	if t.tokens > float64(t.Burst) {
+		t.tokens = float64(t.Burst)
+	}
+	t.last = now
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
+
+// isPodStatusUpdate reports whether the request writes a pod's status
+// subresource.
+func isPodStatusUpdate(req *http.Request) bool {
+	if req.Method != http.MethodPut && req.Method != http.MethodPatch {
+		return false
+	}
+	return strings.HasSuffix(req.URL.Path, "/status") && strings.Contains(req.URL.Path, "/pods/")
+}
+
+// podStatusThrottleTransport answers over-cap pod status updates with a
+// synthetic 429, which the status manager treats as a failed sync and
+// retries later with the pod's newest status — so excess updates are
+// coalesced rather than queued. This lets status-update throttling
+// strategies be explored for controller correctness before implementing
+// them in the real kubelet.
+type podStatusThrottleTransport struct {
+	throttle *PodStatusThrottle
+	delegate http.RoundTripper
+}
+
+func (t *podStatusThrottleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isPodStatusUpdate(req) {
+		return t.delegate.RoundTrip(req)
+	}
+	if !t.throttle.admit() {
+		podStatusUpdates.WithLabelValues("throttled").Inc()
+		klog.V(3).InfoS("Throttling pod status update", "url", req.URL.Path)
+		// Mimic server-side throttling so the status manager retries with
+		// the latest status instead of losing the update.
+		return &http.Response{
+			Status:     "429 Too Many Requests",
+			StatusCode: http.StatusTooManyRequests,
+			Proto:      req.Proto,
+			ProtoMajor: req.ProtoMajor,
+			ProtoMinor: req.ProtoMinor,
+			Header:     http.Header{"Retry-After": []string{"1"}},
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+			Request:    req,
+		}, nil
+	}
+	podStatusUpdates.WithLabelValues("sent").Inc()
+	return t.delegate.RoundTrip(req)
+}
+
+// NewPodStatusThrottleWrapper returns a RoundTripper wrapper applying the
+// given throttle to pod status updates.
+func NewPodStatusThrottleWrapper(throttle *PodStatusThrottle) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &podStatusThrottleTransport{throttle: throttle, delegate: rt}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+	"k8s.io/klog/v2"
+)
+
+// NotifySystemdReady signals READY=1 to a supervising systemd instance and,
+// if the unit has WatchdogSec configured, starts a goroutine petting the
+// watchdog at half the configured interval. This lets hollow nodes run
+// densely as Type=notify services on bare-metal lab machines, with systemd
+// restarting any instance that hangs. Outside systemd (NOTIFY_SOCKET unset,
+// e.g. when running as a pod on an admin cluster) it is a no-op.
+func NotifySystemdReady() {
+	sent, err := daemon.SdNotify(false, daemon.SdNotifyReady)
+	if err != nil {
+		klog.Warningf("Failed to notify systemd of readiness: %v", err)
+		return
+	}
+	if !sent {
+		// Not running under systemd supervision.
+		return
+	}
+	klog.V(2).Infof("Notified systemd of readiness")
+
+	interval, err := daemon.SdWatchdogEnabled(false)
+	if err != nil {
+		klog.Warningf("Failed to determine systemd watchdog interval: %v", err)
+		return
+	}
+	if interval == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := daemon.SdNotify(false, daemon.SdNotifyWatchdog); err != nil {
+				klog.Warningf("Failed to pet systemd watchdog: %v", err)
+			}
+		}
+	}()
+}
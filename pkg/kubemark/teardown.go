@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+// TeardownConfig bounds how fast a fleet is torn down.
+type TeardownConfig struct {
+	// FleetNamespace and FleetName identify the hollow-node Deployment.
+	FleetNamespace string
+	FleetName      string
+	// QPS caps the aggregate rate of delete calls across all workers.
+	QPS float64
+	// Burst is the token bucket depth behind QPS.
+	Burst int
+	// Workers is how many nodes are torn down concurrently.
+	Workers int
+}
+
+// TeardownSummary reports what a teardown removed.
+type TeardownSummary struct {
+	Nodes int
+	Pods  int
+}
+
+// Teardown scales the hollow-node Deployment to zero and then removes the
+// fleet's Node objects and the pods scheduled on them, pacing every delete
+// call through a shared token bucket. Deleting a few thousand nodes and
+// their pods in one unthrottled burst is a denial of service on a shared
+// control plane; the bounded rate keeps scale-down within the same request
+// budget as any other client.
+func Teardown(ctx context.Context, client clientset.Interface, config TeardownConfig) (*TeardownSummary, error) {
+	if config.Workers <= 0 {
+		config.Workers = 10
+	}
+	limiter := flowcontrol.NewTokenBucketRateLimiter(float32(config.QPS), config.Burst)
+
+	// Capture the fleet's node names before the pods terminate: each fleet
+	// pod registers the hollow node under its own name.
+	fleetPods, err := client.CoreV1().Pods(config.FleetNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "name=" + config.FleetName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list fleet pods, error: %w", err)
+	}
+	nodeNames := map[string]bool{}
+	for _, pod := range fleetPods.Items {
+		nodeNames[pod.Name] = true
+	}
+	// Also sweep up registered nodes whose fleet pod is already gone.
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list nodes, error: %w", err)
+	}
+	for _, node := range nodes.Items {
+		if strings.HasPrefix(node.Name, config.FleetName+"-") {
+			nodeNames[node.Name] = true
+		}
+	}
+
+	// Stop the Deployment from recreating fleet pods while nodes go away.
+	limiter.Accept()
+	_, err = client.AppsV1().Deployments(config.FleetNamespace).UpdateScale(ctx, config.FleetName, &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Name: config.FleetName, Namespace: config.FleetNamespace},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: 0},
+	}, metav1.UpdateOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("Failed to scale fleet %s/%s to zero, error: %w", config.FleetNamespace, config.FleetName, err)
+	}
+
+	names := make(chan string, len(nodeNames))
+	for name := range nodeNames {
+		names <- name
+	}
+	close(names)
+
+	summary := &TeardownSummary{}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for worker := 0; worker < config.Workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				pods, nodeDeleted := teardownNode(ctx, client, limiter, name)
+				lock.Lock()
+				summary.Pods += pods
+				if nodeDeleted {
+					summary.Nodes++
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return summary, nil
+}
+
+// teardownNode deletes the pods bound to the named node and then the Node
+// object, pacing each call through the limiter.
+func teardownNode(ctx context.Context, client clientset.Interface, limiter flowcontrol.RateLimiter, nodeName string) (podsDeleted int, nodeDeleted bool) {
+	limiter.Accept()
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		klog.Warningf("Failed to list pods on node %s: %v", nodeName, err)
+	} else {
+		for _, pod := range pods.Items {
+			limiter.Accept()
+			err := client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+				GracePeriodSeconds: utilpointer.Int64(0),
+			})
+			if err != nil && !apierrors.IsNotFound(err) {
+				klog.Warningf("Failed to delete pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				continue
+			}
+			podsDeleted++
+		}
+	}
+
+	limiter.Accept()
+	err = client.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Warningf("Failed to delete node %s: %v", nodeName, err)
+		return podsDeleted, false
+	}
+	return podsDeleted, err == nil
+}
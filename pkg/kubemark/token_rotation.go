@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+var tokenRequests = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "kubemark",
+		Name:           "token_requests_total",
+		Help:           "TokenRequest calls issued by the service account token rotation simulation, by result.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"result"},
+)
+
+func init() {
+	legacyregistry.MustRegister(tokenRequests)
+}
+
+// TokenRotationSimulator reproduces the TokenRequest traffic a real kubelet
+// generates for projected service account token volumes. The hollow
+// kubelet's own token manager covers pods whose volumes actually mount, but
+// simulated pods injected outside the volume manager never request tokens;
+// this simulator issues a pod-bound TokenRequest for every pod on the node
+// and refreshes each one at 80% of its TTL, the kubelet's cadence, so the
+// apiserver-side cost of BoundServiceAccountTokenVolume can be benchmarked
+// at fleet scale.
+type TokenRotationSimulator struct {
+	Client   clientset.Interface
+	NodeName string
+	// Expiration is the TTL requested for each token.
+	Expiration time.Duration
+
+	// nextRefresh tracks when each pod's token is due, keyed by pod UID.
+	nextRefresh map[types.UID]time.Time
+}
+
+// NewTokenRotationSimulator creates the simulator for this node.
+func NewTokenRotationSimulator(client clientset.Interface, nodeName string, expiration time.Duration) *TokenRotationSimulator {
+	return &TokenRotationSimulator{
+		Client:      client,
+		NodeName:    nodeName,
+		Expiration:  expiration,
+		nextRefresh: map[types.UID]time.Time{},
+	}
+}
+
+// Run refreshes due tokens once a minute until stopCh is closed.
+func (s *TokenRotationSimulator) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				s.refreshDueTokens()
+			}
+		}
+	}()
+}
+
+// refreshDueTokens issues a TokenRequest for every pod on the node whose
+// token is missing or past its refresh point.
+func (s *TokenRotationSimulator) refreshDueTokens() {
+	pods, err := s.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", s.NodeName).String(),
+	})
+	if err != nil {
+		klog.Warningf("Failed to list pods for token rotation: %v", err)
+		return
+	}
+	now := simClock.Now()
+	seen := map[types.UID]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		seen[pod.UID] = true
+		if due, known := s.nextRefresh[pod.UID]; known && now.Before(due) {
+			continue
+		}
+		serviceAccount := pod.Spec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+		_, err := s.Client.CoreV1().ServiceAccounts(pod.Namespace).CreateToken(context.TODO(), serviceAccount, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				Audiences:         []string{"https://kubernetes.default.svc"},
+				ExpirationSeconds: utilpointer.Int64(int64(s.Expiration / time.Second)),
+				BoundObjectRef: &authenticationv1.BoundObjectReference{
+					APIVersion: "v1",
+					Kind:       "Pod",
+					Name:       pod.Name,
+					UID:        pod.UID,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			tokenRequests.WithLabelValues("error").Inc()
+			klog.V(3).Infof("Failed to refresh token for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		tokenRequests.WithLabelValues("success").Inc()
+		// Refresh at 80% of the TTL, as the kubelet's token manager does.
+		s.nextRefresh[pod.UID] = now.Add(s.Expiration * 8 / 10)
+	}
+	for uid := range s.nextRefresh {
+		if !seen[uid] {
+			delete(s.nextRefresh, uid)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"sync"
+
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+var (
+	transportWrapperLock sync.Mutex
+	transportWrappers    []transport.WrapperFunc
+)
+
+// RegisterTransportWrapper registers a RoundTripper wrapper that is applied
+// to every client the hollow components create. Downstream users can use this
+// to inject auth headers or capture traffic without forking the package.
+// Wrappers must be registered before the hollow component is started.
+func RegisterTransportWrapper(wrapper transport.WrapperFunc) {
+	transportWrapperLock.Lock()
+	defer transportWrapperLock.Unlock()
+	transportWrappers = append(transportWrappers, wrapper)
+}
+
+// ApplyTransportWrappers applies all registered RoundTripper wrappers to the
+// given client config, in registration order.
+func ApplyTransportWrappers(config *restclient.Config) {
+	transportWrapperLock.Lock()
+	defer transportWrapperLock.Unlock()
+	for _, wrapper := range transportWrappers {
+		config.Wrap(wrapper)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"time"
+)
+
+// UsageModelAnnotation, when set on a pod, selects the shape of the
+// simulated CPU/memory usage of its containers. The models scale the
+// QoS-derived baseline usage, so the Summary API and resource metrics
+// endpoints report load that metrics-server and HPA can meaningfully react
+// to.
+const UsageModelAnnotation = "kubemark.io/usage-model"
+
+// UsageModel names a synthetic per-pod usage shape.
+type UsageModel string
+
+const (
+	// UsageModelSteady holds the baseline usage constant; the default.
+	UsageModelSteady UsageModel = "steady"
+	// UsageModelIdle runs at a few percent of the baseline.
+	UsageModelIdle UsageModel = "idle"
+	// UsageModelRamp climbs linearly from 10% to the full baseline over the
+	// container's first hour, the shape of a service warming its caches.
+	UsageModelRamp UsageModel = "ramp"
+	// UsageModelSpike idles at 30% with 5-minute bursts to 3x the baseline
+	// every 30 minutes, phase-shifted per container so a fleet's spikes
+	// spread out.
+	UsageModelSpike UsageModel = "spike"
+	// UsageModelNoise jitters around the baseline by up to ±40%, changing
+	// once per minute.
+	UsageModelNoise UsageModel = "noise"
+)
+
+// usageModelFor reads a pod's usage model annotation, defaulting to steady
+// for missing or unknown values.
+func usageModelFor(annotations map[string]string) UsageModel {
+	switch model := UsageModel(annotations[UsageModelAnnotation]); model {
+	case UsageModelIdle, UsageModelRamp, UsageModelSpike, UsageModelNoise:
+		return model
+	default:
+		return UsageModelSteady
+	}
+}
+
+// multiplier returns the factor the model applies to a container's baseline
+// usage at the given instant. Outcomes that vary over time are stable hashes
+// of the container ID and time window, so repeated scrapes within a window
+// agree.
+func (m UsageModel) multiplier(id string, createdAt, now time.Time) float64 {
+	age := now.Sub(createdAt)
+	if age < 0 {
+		age = 0
+	}
+	switch m {
+	case UsageModelIdle:
+		return 0.05
+	case UsageModelRamp:
+		if age >= time.Hour {
+			return 1
+		}
+		return 0.10 + 0.90*float64(age)/float64(time.Hour)
+	case UsageModelSpike:
+		const period = 30 * time.Minute
+		phase := time.Duration(containerFraction(id) * float64(period))
+		if (age+phase)%period < 5*time.Minute {
+			return 3
+		}
+		return 0.30
+	case UsageModelNoise:
+		window := now.UnixNano() / int64(time.Minute)
+		return 0.60 + 0.80*containerFraction(fmt.Sprintf("%s/%d", id, window))
+	default:
+		return 1
+	}
+}
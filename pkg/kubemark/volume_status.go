@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// VolumeStatusReporter reports volumesInUse and volumesAttached in node
+// status for the CSI volumes referenced by pods on a hollow node. The hollow
+// kubelet's volume manager mounts nothing, so without this the attach-detach
+// controller never sees realistic node status at scale. The kubelet
+// overwrites these fields on its own status syncs, so the reporter
+// re-patches periodically.
+type VolumeStatusReporter struct {
+	Client   clientset.Interface
+	NodeName string
+}
+
+// NewVolumeStatusReporter creates a reporter for the given hollow node.
+func NewVolumeStatusReporter(client clientset.Interface, nodeName string) *VolumeStatusReporter {
+	return &VolumeStatusReporter{
+		Client:   client,
+		NodeName: nodeName,
+	}
+}
+
+// Run reports volume status every 30 seconds until stopCh is closed.
+func (r *VolumeStatusReporter) Run(stopCh <-chan struct{}) {
+	go func() {
+		ticker := simClock.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C():
+				if err := r.report(); err != nil {
+					klog.V(3).Infof("Failed to report volume status for node %s: %v", r.NodeName, err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *VolumeStatusReporter) report() error {
+	pods, err := r.Client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", r.NodeName).String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	seen := map[v1.UniqueVolumeName]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			name, err := r.uniqueVolumeName(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+			if err != nil {
+				klog.V(4).Infof("Skipping volume %s of pod %s/%s: %v", volume.Name, pod.Namespace, pod.Name, err)
+				continue
+			}
+			if name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	inUse := make([]v1.UniqueVolumeName, 0, len(seen))
+	for name := range seen {
+		inUse = append(inUse, name)
+	}
+	sort.Slice(inUse, func(i, j int) bool { return inUse[i] < inUse[j] })
+
+	attached := make([]v1.AttachedVolume, 0, len(inUse))
+	for i, name := range inUse {
+		attached = append(attached, v1.AttachedVolume{
+			Name:       name,
+			DevicePath: fmt.Sprintf("/dev/disk/by-id/kubemark-%d", i),
+		})
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"volumesInUse":    inUse,
+			"volumesAttached": attached,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.Client.CoreV1().Nodes().PatchStatus(context.TODO(), r.NodeName, patch)
+	return err
+}
+
+// uniqueVolumeName resolves a PVC to the CSI unique volume name the real
+// kubelet would report, or "" for non-CSI volumes.
+func (r *VolumeStatusReporter) uniqueVolumeName(namespace, claimName string) (v1.UniqueVolumeName, error) {
+	pvc, err := r.Client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", fmt.Errorf("claim %s/%s is not bound", namespace, claimName)
+	}
+	pv, err := r.Client.CoreV1().PersistentVolumes().Get(context.TODO(), pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if pv.Spec.CSI == nil {
+		return "", nil
+	}
+	return v1.UniqueVolumeName(fmt.Sprintf("kubernetes.io/csi/%s^%s", pv.Spec.CSI.Driver, pv.Spec.CSI.VolumeHandle)), nil
+}
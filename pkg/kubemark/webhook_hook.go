@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/kubernetes/pkg/kubemark/lifecycle"
+)
+
+// TransitionReview is sent to the lifecycle webhook at every simulated pod
+// transition point.
+type TransitionReview struct {
+	NodeName  string      `json:"nodeName"`
+	Namespace string      `json:"namespace"`
+	PodName   string      `json:"podName"`
+	Phase     v1.PodPhase `json:"phase"`
+}
+
+// TransitionDecision is the webhook's verdict on a pod transition. The
+// webhook can delay the transition, fail it, or redirect it to a different
+// phase, enabling custom behavior without rebuilding kubemark.
+type TransitionDecision struct {
+	// Allow indicates whether the transition should proceed. A disallowed
+	// transition is treated as a simulated failure.
+	Allow bool `json:"allow"`
+	// DelaySeconds postpones the transition by the given amount.
+	DelaySeconds int `json:"delaySeconds,omitempty"`
+	// PhaseOverride, if set, replaces the phase the pod transitions to.
+	PhaseOverride v1.PodPhase `json:"phaseOverride,omitempty"`
+	// Reason is surfaced in logs and events for disallowed transitions.
+	Reason string `json:"reason,omitempty"`
+}
+
+// WebhookHook calls a user-provided HTTP webhook at pod transition points,
+// as an alternative to compiled-in LifecycleHook implementations. Webhook
+// errors fail open so an unavailable webhook degrades to default behavior
+// rather than wedging the simulation.
+type WebhookHook struct {
+	URL      string
+	NodeName string
+	client   *http.Client
+}
+
+var _ LifecycleHook = &WebhookHook{}
+var _ lifecycle.Decider = &WebhookHook{}
+
+// NewWebhookHook creates a webhook-backed lifecycle hook.
+func NewWebhookHook(url, nodeName string, timeout time.Duration) *WebhookHook {
+	return &WebhookHook{
+		URL:      url,
+		NodeName: nodeName,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements LifecycleHook.
+func (w *WebhookHook) Name() string {
+	return fmt.Sprintf("webhook[%s]", w.URL)
+}
+
+// OnNodeStart implements LifecycleHook.
+func (w *WebhookHook) OnNodeStart(nodeName string) {}
+
+// OnNodeShutdown implements LifecycleHook.
+func (w *WebhookHook) OnNodeShutdown(nodeName string) {}
+
+// OnPodTransition implements LifecycleHook as a pure observer. The webhook
+// is consulted through DecideTransition before a transition lands, so by
+// the time hooks fire there is nothing left to decide, and per the
+// LifecycleHook contract nothing here may block.
+func (w *WebhookHook) OnPodTransition(pod *v1.Pod, phase v1.PodPhase) {
+	klog.V(4).Infof("Pod %s/%s entered phase %s", pod.Namespace, pod.Name, phase)
+}
+
+// DecideTransition implements lifecycle.Decider, so the webhook can gate
+// transitions routed through the pod lifecycle state machine instead of
+// merely observing them.
+func (w *WebhookHook) DecideTransition(pod *v1.Pod, to v1.PodPhase) lifecycle.Decision {
+	decision := w.Decide(pod, to)
+	return lifecycle.Decision{
+		Allow:         decision.Allow,
+		Delay:         time.Duration(decision.DelaySeconds) * time.Second,
+		PhaseOverride: decision.PhaseOverride,
+		Reason:        decision.Reason,
+	}
+}
+
+// Decide sends the transition to the webhook and returns its decision.
+// Errors and malformed responses fail open with an allowing decision.
+func (w *WebhookHook) Decide(pod *v1.Pod, phase v1.PodPhase) TransitionDecision {
+	allow := TransitionDecision{Allow: true}
+	review := TransitionReview{
+		NodeName:  w.NodeName,
+		Namespace: pod.Namespace,
+		PodName:   pod.Name,
+		Phase:     phase,
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		klog.Warningf("Failed to encode transition review: %v", err)
+		return allow
+	}
+	resp, err := w.client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		klog.Warningf("Lifecycle webhook %s unreachable, failing open: %v", w.URL, err)
+		return allow
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		klog.Warningf("Lifecycle webhook %s returned status %d, failing open", w.URL, resp.StatusCode)
+		return allow
+	}
+	var decision TransitionDecision
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		klog.Warningf("Failed to decode lifecycle webhook response, failing open: %v", err)
+		return allow
+	}
+	return decision
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workload contains helpers that generate the workload shapes real
+// clusters exhibit, so kubemark simulations include them without hand-written
+// manifests.
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// DaemonSetSpec describes one synthetic per-node agent. Per-node agents
+// dominate real clusters' pods-per-node baselines, so DaemonSet-heavy
+// simulations need a cheap way to generate them.
+type DaemonSetSpec struct {
+	// Name of the DaemonSet.
+	Name string
+	// Image run by the agent container. The image is never pulled on hollow
+	// nodes, so any well-formed reference works.
+	Image string
+	// CPU/Memory request of the agent container.
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// DefaultNodeAgents returns agent footprints resembling the logging,
+// monitoring and networking daemons found on typical production nodes.
+func DefaultNodeAgents() []DaemonSetSpec {
+	return []DaemonSetSpec{
+		{Name: "sim-logging-agent", Image: "registry.k8s.io/pause:3.8", CPU: resource.MustParse("100m"), Memory: resource.MustParse("200Mi")},
+		{Name: "sim-monitoring-agent", Image: "registry.k8s.io/pause:3.8", CPU: resource.MustParse("50m"), Memory: resource.MustParse("100Mi")},
+		{Name: "sim-network-agent", Image: "registry.k8s.io/pause:3.8", CPU: resource.MustParse("50m"), Memory: resource.MustParse("50Mi")},
+	}
+}
+
+// MakeDaemonSet builds the DaemonSet for one synthetic agent. The pods
+// tolerate everything so they land on every hollow node, as kube-system
+// agents do.
+func MakeDaemonSet(namespace string, spec DaemonSetSpec) *appsv1.DaemonSet {
+	labels := map[string]string{"name": spec.Name}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      spec.Name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Tolerations: []v1.Toleration{
+						{Operator: v1.TolerationOpExists},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  spec.Name,
+							Image: spec.Image,
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    spec.CPU,
+									v1.ResourceMemory: spec.Memory,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// CreateDaemonSets creates the given synthetic agents in the target
+// namespace.
+func CreateDaemonSets(ctx context.Context, client clientset.Interface, namespace string, specs []DaemonSetSpec) error {
+	for _, spec := range specs {
+		ds := MakeDaemonSet(namespace, spec)
+		if _, err := client.AppsV1().DaemonSets(namespace).Create(ctx, ds, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error while creating DaemonSet %s/%s: %w", namespace, spec.Name, err)
+		}
+	}
+	return nil
+}